@@ -0,0 +1,38 @@
+// Package apikey lets admins issue and revoke API keys for machine clients.
+package apikey
+
+import (
+	"context"
+	"time"
+)
+
+type APIKey struct {
+	ID         int        `json:"id" example:"1"`
+	Name       string     `json:"name" example:"nightly-reconciliation"`
+	Prefix     string     `json:"prefix" example:"3f9a1c0e5b7d2a64"`
+	Scopes     []string   `json:"scopes" example:"wallet:read"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2025-03-25T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-03-25T14:19:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2024-03-26T09:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// CreatedAPIKey is the only response that contains the key itself.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"wk_3f9a1c0e5b7d2a64_q8Xh..."`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name" example:"nightly-reconciliation"`
+	Scopes    []string   `json:"scopes" example:"wallet:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-03-25T00:00:00Z"`
+}
+
+type Service interface {
+	CreateAPIKey(ctx context.Context, request *APIKeyRequest) (*CreatedAPIKey, error)
+
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+
+	RevokeAPIKey(ctx context.Context, id int) (*APIKey, error)
+}
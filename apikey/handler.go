@@ -0,0 +1,102 @@
+package apikey
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateAPIKey
+// @Summary Create api key
+// @Description Issue an API key for a machine client; the key is only shown in this response
+// @Tags apikey
+// @Accept json
+// @Produce json
+// @Router /api/v1/api-keys [post]
+// @Security BearerAuth
+// @Param APIKeyRequest body APIKeyRequest true "APIKeyRequest"
+// @Success 201 {object} CreatedAPIKey
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) CreateAPIKeyHandler(c echo.Context) error {
+
+	req := new(APIKeyRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	key, err := h.service.CreateAPIKey(c.Request().Context(), req)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys
+// @Summary List api keys
+// @Description List API keys without their secrets
+// @Tags apikey
+// @Accept json
+// @Produce json
+// @Router /api/v1/api-keys [get]
+// @Security BearerAuth
+// @Success 200 {array} APIKey
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+func (h *Handler) ListAPIKeysHandler(c echo.Context) error {
+
+	keys, err := h.service.ListAPIKeys(c.Request().Context())
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey
+// @Summary Revoke api key
+// @Description Revoke an API key; requests using it are rejected from then on
+// @Tags apikey
+// @Accept json
+// @Produce json
+// @Router /api/v1/api-keys/{id} [delete]
+// @Security BearerAuth
+// @Param	id	path	string	true	"api key id"
+// @Success 200 {object} APIKey
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) RevokeAPIKeyHandler(c echo.Context) error {
+
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid api key ID")
+	}
+
+	key, err := h.service.RevokeAPIKey(c.Request().Context(), id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
@@ -0,0 +1,93 @@
+package apikey
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	reqBody := APIKeyRequest{Name: "batch", Scopes: []string{"wallet:read"}}
+	created := CreatedAPIKey{APIKey: APIKey{ID: 1, Name: "batch", Prefix: "0123456789abcdef", Scopes: []string{"wallet:read"}}, Key: "wk_0123456789abcdef_secret"}
+
+	mockService.On("CreateAPIKey", mock.Anything, &reqBody).Return(&created, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.CreateAPIKeyHandler(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"key":"wk_0123456789abcdef_secret"`)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListAPIKeysHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("ListAPIKeys", mock.Anything).Return([]APIKey{{ID: 1, Name: "batch"}}, nil)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.ListAPIKeysHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"key"`)
+	}
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	t.Run("given key id should revoke it", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		mockService.On("RevokeAPIKey", mock.Anything, 3).Return(&APIKey{ID: 3}, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+
+		assert.NoError(t, handler.RevokeAPIKeyHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given invalid id should return 400", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		err := handler.RevokeAPIKeyHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockService.AssertNotCalled(t, "RevokeAPIKey")
+	})
+}
@@ -0,0 +1,102 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+type KeyService struct {
+	KeyStore postgres.APIKeyStorer
+}
+
+func NewService(db postgres.APIKeyStorer) KeyService {
+	return KeyService{KeyStore: db}
+}
+
+// CreateAPIKey issues a key. The key is returned here and nowhere else;
+// only its hash is kept.
+func (s KeyService) CreateAPIKey(ctx context.Context, request *APIKeyRequest) (*CreatedAPIKey, error) {
+
+	err := ValidateAPIKeyRequest(request, time.Now())
+
+	if err != nil {
+		slog.InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Create api key failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create api key failed")
+	}
+
+	k, err := s.KeyStore.CreateAPIKey(ctx, &postgres.APIKey{
+		Name:       strings.TrimSpace(request.Name),
+		Prefix:     prefix,
+		SecretHash: hash,
+		Scopes:     request.Scopes,
+		ExpiresAt:  request.ExpiresAt,
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Create api key failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create api key failed")
+	}
+
+	return &CreatedAPIKey{APIKey: toAPIKeyResponse(k), Key: key}, nil
+}
+
+func (s KeyService) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+
+	keys, err := s.KeyStore.ListAPIKeys(ctx)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "List api keys failed", "err", err)
+		return nil, apperrs.NewInternalServerError("List api keys failed")
+	}
+
+	responses := []APIKey{}
+	for i := range keys {
+		responses = append(responses, toAPIKeyResponse(&keys[i]))
+	}
+
+	return responses, nil
+}
+
+func (s KeyService) RevokeAPIKey(ctx context.Context, id int) (*APIKey, error) {
+
+	k, err := s.KeyStore.RevokeAPIKey(ctx, id)
+
+	if errors.Is(err, postgres.ErrNotFound) {
+		return nil, apperrs.NewNotFoundError(err.Error())
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Revoke api key failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Revoke api key failed")
+	}
+
+	response := toAPIKeyResponse(k)
+	return &response, nil
+}
+
+func toAPIKeyResponse(k *postgres.APIKey) APIKey {
+	return APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -0,0 +1,26 @@
+package apikey
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockService is a mock implementation of the Service interface
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateAPIKey(ctx context.Context, request *APIKeyRequest) (*CreatedAPIKey, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*CreatedAPIKey), args.Error(1)
+}
+
+func (m *MockService) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]APIKey), args.Error(1)
+}
+
+func (m *MockService) RevokeAPIKey(ctx context.Context, id int) (*APIKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*APIKey), args.Error(1)
+}
@@ -0,0 +1,111 @@
+package apikey_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apikey"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubKeyStore struct {
+	Created *postgres.APIKey
+	Keys    []postgres.APIKey
+	Err     error
+}
+
+func (s *StubKeyStore) CreateAPIKey(ctx context.Context, key *postgres.APIKey) (*postgres.APIKey, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	s.Created = key
+	k := *key
+	k.ID = 1
+	k.CreatedAt = time.Now()
+	return &k, nil
+}
+
+func (s *StubKeyStore) ListAPIKeys(ctx context.Context) ([]postgres.APIKey, error) {
+	return s.Keys, s.Err
+}
+
+func (s *StubKeyStore) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*postgres.APIKey, error) {
+	return nil, postgres.ErrNotFound
+}
+
+func (s *StubKeyStore) RevokeAPIKey(ctx context.Context, id int) (*postgres.APIKey, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	now := time.Now()
+	return &postgres.APIKey{ID: id, RevokedAt: &now}, nil
+}
+
+func (s *StubKeyStore) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	return nil
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Run("given valid request should store only the hash", func(t *testing.T) {
+		store := &StubKeyStore{}
+		service := apikey.NewService(store)
+
+		created, err := service.CreateAPIKey(context.Background(), &apikey.APIKeyRequest{Name: " batch ", Scopes: []string{auth.PermWalletRead}})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, created.Key)
+		assert.Equal(t, "batch", created.Name)
+		assert.Equal(t, auth.HashAPIKey(created.Key), store.Created.SecretHash)
+		assert.NotContains(t, store.Created.SecretHash, created.Key)
+		assert.Equal(t, store.Created.Prefix, created.Prefix)
+	})
+
+	t.Run("given unknown scope should return 400", func(t *testing.T) {
+		store := &StubKeyStore{}
+		service := apikey.NewService(store)
+
+		_, err := service.CreateAPIKey(context.Background(), &apikey.APIKeyRequest{Name: "batch", Scopes: []string{auth.PermAPIKeyManage}})
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Nil(t, store.Created)
+	})
+}
+
+func TestListAPIKeys(t *testing.T) {
+	service := apikey.NewService(&StubKeyStore{})
+
+	keys, err := service.ListAPIKeys(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []apikey.APIKey{}, keys)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	t.Run("given existing key should return it revoked", func(t *testing.T) {
+		service := apikey.NewService(&StubKeyStore{})
+
+		key, err := service.RevokeAPIKey(context.Background(), 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, key.ID)
+		assert.NotNil(t, key.RevokedAt)
+	})
+
+	t.Run("given unknown key should return 404", func(t *testing.T) {
+		service := apikey.NewService(&StubKeyStore{Err: fmt.Errorf("api key 3: %w", postgres.ErrNotFound)})
+
+		_, err := service.RevokeAPIKey(context.Background(), 3)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+)
+
+const (
+	minNameLength = 3
+	maxNameLength = 255
+)
+
+// ValidateAPIKeyRequest validates the name, scopes and expiry of a new key
+func ValidateAPIKeyRequest(request *APIKeyRequest, now time.Time) error {
+	var errMsgs []string
+
+	name := strings.TrimSpace(request.Name)
+	if len(name) < minNameLength || len(name) > maxNameLength {
+		errMsgs = append(errMsgs, fmt.Sprintf("Name must be between %d and %d characters", minNameLength, maxNameLength))
+	}
+
+	if len(request.Scopes) == 0 {
+		errMsgs = append(errMsgs, "Scopes must not be empty")
+	}
+	seen := map[string]bool{}
+	for _, scope := range request.Scopes {
+		if !isKeyScope(scope) {
+			errMsgs = append(errMsgs, fmt.Sprintf("Scope %q is not one of %s", scope, strings.Join(auth.KeyScopes, ", ")))
+		} else if seen[scope] {
+			errMsgs = append(errMsgs, fmt.Sprintf("Scope %q is repeated", scope))
+		}
+		seen[scope] = true
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		errMsgs = append(errMsgs, "ExpiresAt must be in the future")
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+func isKeyScope(scope string) bool {
+	for _, s := range auth.KeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
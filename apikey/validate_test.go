@@ -0,0 +1,34 @@
+package apikey
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAPIKeyRequest(t *testing.T) {
+	now := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+	future := now.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name      string
+		request   *APIKeyRequest
+		wantError bool
+	}{
+		{name: "Valid request", request: &APIKeyRequest{Name: "batch", Scopes: []string{"wallet:read", "wallet:transact"}, ExpiresAt: &future}, wantError: false},
+		{name: "Valid request without expiry", request: &APIKeyRequest{Name: "batch", Scopes: []string{"wallet:read"}}, wantError: false},
+		{name: "Invalid Name (too short)", request: &APIKeyRequest{Name: "b", Scopes: []string{"wallet:read"}}, wantError: true},
+		{name: "Invalid Scopes (empty)", request: &APIKeyRequest{Name: "batch"}, wantError: true},
+		{name: "Invalid Scopes (unknown)", request: &APIKeyRequest{Name: "batch", Scopes: []string{"wallet:everything"}}, wantError: true},
+		{name: "Invalid Scopes (repeated)", request: &APIKeyRequest{Name: "batch", Scopes: []string{"wallet:read", "wallet:read"}}, wantError: true},
+		{name: "Invalid ExpiresAt (past)", request: &APIKeyRequest{Name: "batch", Scopes: []string{"wallet:read"}, ExpiresAt: &now}, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAPIKeyRequest(tc.request, now)
+			if (err != nil) != tc.wantError {
+				t.Errorf("ValidateAPIKeyRequest(%+v) returned error: %v, wantError: %t", tc.request, err, tc.wantError)
+			}
+		})
+	}
+}
@@ -29,4 +29,28 @@ func NewUnprocessableEntity(message string) error {
 
 func NewBadRequestError(message string) error {
 	return echo.NewHTTPError(http.StatusBadRequest, message)
-}
\ No newline at end of file
+}
+
+func NewConflictError(message string) error {
+	return echo.NewHTTPError(http.StatusConflict, message)
+}
+
+func NewPreconditionFailedError(message string) error {
+	return echo.NewHTTPError(http.StatusPreconditionFailed, message)
+}
+
+func NewPreconditionRequiredError(message string) error {
+	return echo.NewHTTPError(http.StatusPreconditionRequired, message)
+}
+
+func NewUnauthorizedError(message string) error {
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
+
+func NewForbiddenError(message string) error {
+	return echo.NewHTTPError(http.StatusForbidden, message)
+}
+
+func NewGatewayTimeoutError(message string) error {
+	return echo.NewHTTPError(http.StatusGatewayTimeout, message)
+}
@@ -1,15 +1,21 @@
 package apperrs
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
 	"github.com/labstack/echo/v4"
 )
 
+// CustomError is the body of every error response. TraceID names the trace
+// of the failed request, when there is one, to look it up by.
 type CustomError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 
@@ -28,12 +34,24 @@ func CustomErrorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 				message = e.Message.(string)
 			default:
 				// Log the error for debugging purposes
-				log.Println(err)
+				slog.ErrorContext(c.Request().Context(), "unhandled error", "err", err)
+			}
+
+			// A server error after the request's deadline passed is the
+			// database running out of time, whatever the handler made of it
+			if code >= http.StatusInternalServerError && timedOut(c, err) {
+				code = http.StatusGatewayTimeout
+				message = "Request timed out"
 			}
 
 			// Return standardized JSON response with error details
-			return c.JSON(code, CustomError{Code: code, Message: message})
+			return c.JSON(code, CustomError{Code: code, Message: message, TraceID: tracing.TraceID(c.Request().Context())})
 		}
 		return nil
 	}
-}
\ No newline at end of file
+}
+
+func timedOut(c echo.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(c.Request().Context().Err(), context.DeadlineExceeded)
+}
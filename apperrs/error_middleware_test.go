@@ -1,9 +1,12 @@
 package apperrs_test
 
 import (
+    "context"
+    "fmt"
     "net/http"
     "net/http/httptest"
     "testing"
+    "time"
 
     "github.com/labstack/echo/v4"
     "github.com/stretchr/testify/assert"
@@ -37,3 +40,54 @@ func TestCustomErrorMiddleware(t *testing.T) {
     assert.Equal(t, http.StatusBadRequest, rec.Code)
     assert.JSONEq(t, `{"code":400,"message":"Bad Request"}`, rec.Body.String())
 }
+
+func TestCustomErrorMiddlewareTimeout(t *testing.T) {
+    t.Run("given server error after the deadline should return 504", func(t *testing.T) {
+        e := echo.New()
+        e.Use(apperrs.CustomErrorMiddleware)
+        e.Use(apperrs.Timeout(time.Millisecond))
+
+        // The handler waits on the database until the deadline cancels it
+        e.GET("/", func(c echo.Context) error {
+            <-c.Request().Context().Done()
+            return apperrs.NewInternalServerError("Get wallet failed")
+        })
+
+        req := httptest.NewRequest(http.MethodGet, "/", nil)
+        rec := httptest.NewRecorder()
+        e.ServeHTTP(rec, req)
+
+        assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+        assert.JSONEq(t, `{"code":504,"message":"Request timed out"}`, rec.Body.String())
+    })
+
+    t.Run("given deadline exceeded error should return 504", func(t *testing.T) {
+        e := echo.New()
+        e.Use(apperrs.CustomErrorMiddleware)
+        e.GET("/", func(c echo.Context) error {
+            return fmt.Errorf("find wallet: %w", context.DeadlineExceeded)
+        })
+
+        req := httptest.NewRequest(http.MethodGet, "/", nil)
+        rec := httptest.NewRecorder()
+        e.ServeHTTP(rec, req)
+
+        assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+    })
+
+    t.Run("given client error after the deadline should keep its status", func(t *testing.T) {
+        e := echo.New()
+        e.Use(apperrs.CustomErrorMiddleware)
+        e.Use(apperrs.Timeout(time.Millisecond))
+        e.GET("/", func(c echo.Context) error {
+            <-c.Request().Context().Done()
+            return apperrs.NewNotFoundError("wallet 1: not found")
+        })
+
+        req := httptest.NewRequest(http.MethodGet, "/", nil)
+        rec := httptest.NewRecorder()
+        e.ServeHTTP(rec, req)
+
+        assert.Equal(t, http.StatusNotFound, rec.Code)
+    })
+}
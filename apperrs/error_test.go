@@ -55,3 +55,75 @@ func TestNewBadRequestError(t *testing.T) {
 	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
 	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
 }
+
+func TestNewConflictError(t *testing.T) {
+	expectedMessage := "Conflict"
+	expectedCode := http.StatusConflict
+
+	err := NewConflictError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
+
+func TestNewPreconditionFailedError(t *testing.T) {
+	expectedMessage := "Precondition failed"
+	expectedCode := http.StatusPreconditionFailed
+
+	err := NewPreconditionFailedError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
+
+func TestNewPreconditionRequiredError(t *testing.T) {
+	expectedMessage := "Precondition required"
+	expectedCode := http.StatusPreconditionRequired
+
+	err := NewPreconditionRequiredError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
+
+func TestNewUnauthorizedError(t *testing.T) {
+	expectedMessage := "Unauthorized"
+	expectedCode := http.StatusUnauthorized
+
+	err := NewUnauthorizedError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
+
+func TestNewForbiddenError(t *testing.T) {
+	expectedMessage := "Forbidden"
+	expectedCode := http.StatusForbidden
+
+	err := NewForbiddenError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
+
+func TestNewGatewayTimeoutError(t *testing.T) {
+	expectedMessage := "Request timed out"
+	expectedCode := http.StatusGatewayTimeout
+
+	err := NewGatewayTimeoutError(expectedMessage)
+	echoErr, ok := err.(*echo.HTTPError)
+
+	assert.True(t, ok, "error should be an echo.HTTPError")
+	assert.Equal(t, expectedCode, echoErr.Code, "HTTP status code should match")
+	assert.Equal(t, expectedMessage, echoErr.Message, "Message should match")
+}
@@ -0,0 +1,27 @@
+package apperrs
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Timeout gives every request a deadline of d. Store calls take the request
+// context, so a query still running at the deadline is cancelled and the
+// request fails with 504 through CustomErrorMiddleware. A d of zero or less
+// means no deadline.
+func Timeout(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if d <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
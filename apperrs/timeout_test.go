@@ -0,0 +1,45 @@
+package apperrs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Run("given a duration should set the request deadline", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var deadline time.Time
+		var ok bool
+		handler := Timeout(time.Minute)(func(c echo.Context) error {
+			deadline, ok = c.Request().Context().Deadline()
+			return nil
+		})
+
+		assert.NoError(t, handler(c))
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	})
+
+	t.Run("given zero should not set a deadline", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var ok bool
+		handler := Timeout(0)(func(c echo.Context) error {
+			_, ok = c.Request().Context().Deadline()
+			return nil
+		})
+
+		assert.NoError(t, handler(c))
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,41 @@
+// Package audit lets admins read the log of changes made to wallets.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Event struct {
+	ID        int64           `json:"id" example:"42"`
+	Actor     string          `json:"actor" example:"7"`
+	Action    string          `json:"action" example:"wallet.deposit"`
+	WalletID  int             `json:"wallet_id" example:"1"`
+	RequestID string          `json:"request_id,omitempty" example:"3kX9ZfQ2pL"`
+	ClientIP  string          `json:"client_ip,omitempty" example:"192.0.2.1"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// Query selects a page of events, newest first. Cursor is the next_cursor
+// of the previous page.
+type Query struct {
+	WalletID int
+	Actor    string
+	Action   string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Cursor   string
+}
+
+type Page struct {
+	Data       []Event `json:"data"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"42"`
+}
+
+type Service interface {
+	ListEvents(ctx context.Context, query *Query) (*Page, error)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListEvents
+// @Summary List audit events
+// @Description Get a page of wallet changes, newest first; pass next_cursor back as cursor to get the following page
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Router /api/v1/audit [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	wallet_id	query	int	false	"wallet id"
+// @Param	actor	query	string	false	"subject of the caller that made the change"
+// @Param	action	query	string	false	"action, e.g. wallet.deposit"
+// @Param	from	query	string	false	"RFC 3339 time, inclusive"
+// @Param	to	query	string	false	"RFC 3339 time, exclusive"
+// @Param	limit	query	int	false	"page size (default 20, max 100)"
+// @Param	cursor	query	string	false	"next_cursor of the previous page"
+// @Success 200 {object} Page
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) ListEventsHandler(c echo.Context) error {
+
+	query := &Query{
+		Actor:  c.QueryParam("actor"),
+		Action: c.QueryParam("action"),
+		Cursor: c.QueryParam("cursor"),
+		Limit:  defaultPageLimit,
+	}
+
+	var err error
+
+	if value := c.QueryParam("wallet_id"); value != "" {
+		if query.WalletID, err = strconv.Atoi(value); err != nil {
+			return apperrs.NewBadRequestError("invalid wallet_id")
+		}
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			return apperrs.NewBadRequestError("invalid limit")
+		}
+	}
+	if query.From, err = queryParamTime(c, "from"); err != nil {
+		return apperrs.NewBadRequestError("invalid from")
+	}
+	if query.To, err = queryParamTime(c, "to"); err != nil {
+		return apperrs.NewBadRequestError("invalid to")
+	}
+
+	page, err := h.service.ListEvents(c.Request().Context(), query)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func queryParamTime(c echo.Context, name string) (*time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
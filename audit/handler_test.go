@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListEventsHandler(t *testing.T) {
+	t.Run("given filters should pass them to the service", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		from := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+		query := &Query{WalletID: 1, Actor: "7", Action: "wallet.deposit", From: &from, Limit: 10, Cursor: "42"}
+		mockService.On("ListEvents", mock.Anything, query).Return(&Page{Data: []Event{{ID: 41, Action: "wallet.deposit"}}}, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?wallet_id=1&actor=7&action=wallet.deposit&from=2024-03-25T00:00:00Z&limit=10&cursor=42", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.ListEventsHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"action":"wallet.deposit"`)
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given invalid from should return 400", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.ListEventsHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockService.AssertNotCalled(t, "ListEvents")
+	})
+}
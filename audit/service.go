@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+type AuditService struct {
+	AuditStore postgres.AuditStorer
+}
+
+func NewService(db postgres.AuditStorer) AuditService {
+	return AuditService{AuditStore: db}
+}
+
+// ListEvents returns one page of audit events and, when more follow, the
+// cursor to pass back for the next page.
+func (s AuditService) ListEvents(ctx context.Context, query *Query) (*Page, error) {
+
+	beforeID, err := ValidateQuery(query)
+
+	if err != nil {
+		slog.InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	events, err := s.AuditStore.ListAuditEvents(ctx, postgres.AuditFilter{
+		WalletID: query.WalletID,
+		Actor:    query.Actor,
+		Action:   query.Action,
+		From:     query.From,
+		To:       query.To,
+		BeforeID: beforeID,
+		Limit:    query.Limit + 1,
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "List audit events failed", "err", err)
+		return nil, apperrs.NewInternalServerError("List audit events failed")
+	}
+
+	page := &Page{Data: []Event{}}
+
+	// One row more than the limit was asked for to learn whether a next page exists
+	if len(events) > query.Limit {
+		events = events[:query.Limit]
+		page.NextCursor = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+
+	for _, e := range events {
+		page.Data = append(page.Data, Event{
+			ID:        e.ID,
+			Actor:     e.Actor,
+			Action:    e.Action,
+			WalletID:  e.WalletID,
+			RequestID: e.RequestID,
+			ClientIP:  e.ClientIP,
+			Before:    e.Before,
+			After:     e.After,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return page, nil
+}
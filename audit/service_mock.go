@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockService is a mock implementation of the Service interface
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) ListEvents(ctx context.Context, query *Query) (*Page, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(*Page), args.Error(1)
+}
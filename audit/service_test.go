@@ -0,0 +1,73 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubAuditStore struct {
+	Filter postgres.AuditFilter
+	Events []postgres.AuditEvent
+	Err    error
+}
+
+func (s *StubAuditStore) ListAuditEvents(ctx context.Context, filter postgres.AuditFilter) ([]postgres.AuditEvent, error) {
+	s.Filter = filter
+	return s.Events, s.Err
+}
+
+func TestListEvents(t *testing.T) {
+	t.Run("given more events than the limit should return next cursor", func(t *testing.T) {
+		store := &StubAuditStore{Events: []postgres.AuditEvent{
+			{ID: 9, Actor: "7", Action: postgres.AuditWalletDeposit, WalletID: 1},
+			{ID: 8, Actor: "7", Action: postgres.AuditWalletDeposit, WalletID: 1},
+			{ID: 5, Actor: "7", Action: postgres.AuditWalletDeposit, WalletID: 1},
+		}}
+		service := audit.NewService(store)
+
+		page, err := service.ListEvents(context.Background(), &audit.Query{WalletID: 1, Action: postgres.AuditWalletDeposit, Limit: 2, Cursor: "10"})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Data, 2)
+		assert.Equal(t, "8", page.NextCursor)
+		assert.Equal(t, postgres.AuditFilter{WalletID: 1, Action: postgres.AuditWalletDeposit, BeforeID: 10, Limit: 3}, store.Filter)
+	})
+
+	t.Run("given last page should not return next cursor", func(t *testing.T) {
+		store := &StubAuditStore{}
+		service := audit.NewService(store)
+
+		page, err := service.ListEvents(context.Background(), &audit.Query{Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []audit.Event{}, page.Data)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("given invalid cursor should return 400", func(t *testing.T) {
+		service := audit.NewService(&StubAuditStore{})
+
+		_, err := service.ListEvents(context.Background(), &audit.Query{Limit: 20, Cursor: "abc"})
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("given store error should return 500", func(t *testing.T) {
+		service := audit.NewService(&StubAuditStore{Err: errors.New("db down")})
+
+		_, err := service.ListEvents(context.Background(), &audit.Query{Limit: 20})
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	})
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// ValidateQuery checks query and returns the id its cursor continues below.
+func ValidateQuery(query *Query) (int64, error) {
+	var errMsgs []string
+	var beforeID int64
+
+	if query.Limit <= 0 || query.Limit > maxPageLimit {
+		errMsgs = append(errMsgs, fmt.Sprintf("Limit must be between 1 and %d", maxPageLimit))
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		errMsgs = append(errMsgs, "From must be before To")
+	}
+
+	if query.Cursor != "" {
+		id, err := strconv.ParseInt(query.Cursor, 10, 64)
+		if err != nil || id <= 0 {
+			errMsgs = append(errMsgs, "Cursor is invalid")
+		}
+		beforeID = id
+	}
+
+	if len(errMsgs) > 0 {
+		return 0, errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return beforeID, nil
+}
@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateQuery(t *testing.T) {
+	from := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name      string
+		query     *Query
+		wantError bool
+	}{
+		{name: "Valid query", query: &Query{Limit: 20, From: &from, To: &to, Cursor: "42"}, wantError: false},
+		{name: "Invalid Limit (zero)", query: &Query{Limit: 0}, wantError: true},
+		{name: "Invalid Limit (too large)", query: &Query{Limit: 101}, wantError: true},
+		{name: "Invalid range (from after to)", query: &Query{Limit: 20, From: &to, To: &from}, wantError: true},
+		{name: "Invalid Cursor (not a number)", query: &Query{Limit: 20, Cursor: "abc"}, wantError: true},
+		{name: "Invalid Cursor (negative)", query: &Query{Limit: 20, Cursor: "-1"}, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ValidateQuery(tc.query)
+			if (err != nil) != tc.wantError {
+				t.Errorf("ValidateQuery(%+v) returned error: %v, wantError: %t", tc.query, err, tc.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+const (
+	apiKeyMarker = "wk"
+
+	// touchInterval limits how often last_used_at is written for a busy key.
+	touchInterval = time.Minute
+)
+
+// GenerateAPIKey returns a new key along with the prefix and hash to store.
+// Keys look like wk_<prefix>_<secret>.
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	b := make([]byte, 8+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(b[:8])
+	key = apiKeyMarker + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(b[8:])
+	return key, prefix, HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyPrefix(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyMarker || len(parts[1]) != 16 || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// APIKeys authenticates requests that carry an X-API-Key header and leaves
+// the others to Middleware. A key acts as no user: its scopes are the
+// permissions it holds, on every wallet.
+func APIKeys(store postgres.APIKeyStorer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw := c.Request().Header.Get(HeaderAPIKey)
+			if raw == "" {
+				return next(c)
+			}
+
+			prefix, ok := apiKeyPrefix(raw)
+			if !ok {
+				return apperrs.NewUnauthorizedError("invalid api key")
+			}
+
+			k, err := store.FindAPIKeyByPrefix(c.Request().Context(), prefix)
+			if errors.Is(err, postgres.ErrNotFound) {
+				return apperrs.NewUnauthorizedError("invalid api key")
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "API key lookup failed", "err", err)
+				return apperrs.NewInternalServerError("API key lookup failed")
+			}
+
+			if subtle.ConstantTimeCompare([]byte(HashAPIKey(raw)), []byte(k.SecretHash)) != 1 {
+				return apperrs.NewUnauthorizedError("invalid api key")
+			}
+
+			now := time.Now()
+			if k.RevokedAt != nil {
+				return apperrs.NewUnauthorizedError("api key revoked")
+			}
+			if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+				return apperrs.NewUnauthorizedError("api key expired")
+			}
+
+			if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+				if err := store.TouchAPIKey(c.Request().Context(), k.ID, now); err != nil {
+					slog.WarnContext(c.Request().Context(), "Touch api key failed", "key_id", k.ID, "err", err)
+				}
+			}
+
+			SetPrincipal(c, keyPrincipal(k))
+			return next(c)
+		}
+	}
+}
+
+func keyPrincipal(k *postgres.APIKey) *Principal {
+	p := &Principal{Subject: "apikey:" + k.Prefix, KeyID: k.ID, Scopes: k.Scopes, granted: map[string]bool{}}
+	for _, scope := range k.Scopes {
+		p.granted[scope] = true
+	}
+	return p
+}
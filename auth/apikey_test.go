@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubKeyStore struct {
+	Keys    map[string]*postgres.APIKey
+	Touched []int
+}
+
+func (s *StubKeyStore) CreateAPIKey(ctx context.Context, key *postgres.APIKey) (*postgres.APIKey, error) {
+	return key, nil
+}
+
+func (s *StubKeyStore) ListAPIKeys(ctx context.Context) ([]postgres.APIKey, error) {
+	return nil, nil
+}
+
+func (s *StubKeyStore) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*postgres.APIKey, error) {
+	k, ok := s.Keys[prefix]
+	if !ok {
+		return nil, postgres.ErrNotFound
+	}
+	return k, nil
+}
+
+func (s *StubKeyStore) RevokeAPIKey(ctx context.Context, id int) (*postgres.APIKey, error) {
+	return nil, nil
+}
+
+func (s *StubKeyStore) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	s.Touched = append(s.Touched, id)
+	return nil
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+
+	assert.NoError(t, err)
+	assert.Len(t, prefix, 16)
+	assert.Equal(t, HashAPIKey(key), hash)
+
+	parsed, ok := apiKeyPrefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	other, _, _, _ := GenerateAPIKey()
+	assert.NotEqual(t, key, other)
+}
+
+func TestAPIKeys(t *testing.T) {
+	key, prefix, hash, _ := GenerateAPIKey()
+	past := time.Now().Add(-time.Hour)
+	recent := time.Now()
+
+	newStore := func(k postgres.APIKey) *StubKeyStore {
+		k.ID = 5
+		k.Prefix = prefix
+		k.SecretHash = hash
+		k.Scopes = []string{PermWalletRead}
+		return &StubKeyStore{Keys: map[string]*postgres.APIKey{prefix: &k}}
+	}
+
+	run := func(store *StubKeyStore, header string) (*httptest.ResponseRecorder, *Principal) {
+		var got *Principal
+		e := echo.New()
+		e.Use(apperrs.CustomErrorMiddleware)
+		e.Use(APIKeys(store))
+		e.GET("/api/v1/wallets", func(c echo.Context) error {
+			got, _ = FromContext(c)
+			return c.NoContent(http.StatusNoContent)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+		if header != "" {
+			req.Header.Set(HeaderAPIKey, header)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec, got
+	}
+
+	t.Run("given valid key should grant its scopes on every wallet", func(t *testing.T) {
+		store := newStore(postgres.APIKey{})
+
+		rec, p := run(store, key)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		if assert.NotNil(t, p) {
+			assert.Equal(t, 5, p.KeyID)
+			assert.True(t, p.Granted(PermWalletRead))
+			assert.False(t, p.MayHave(PermWalletTransact))
+		}
+		assert.Equal(t, []int{5}, store.Touched)
+	})
+
+	t.Run("given key used within the last minute should not touch it again", func(t *testing.T) {
+		store := newStore(postgres.APIKey{LastUsedAt: &recent})
+
+		rec, _ := run(store, key)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, store.Touched)
+	})
+
+	t.Run("given no header should leave the request to other authentication", func(t *testing.T) {
+		rec, p := run(newStore(postgres.APIKey{}), "")
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Nil(t, p)
+	})
+
+	testCases := []struct {
+		name    string
+		key     postgres.APIKey
+		header  string
+		message string
+	}{
+		{name: "given wrong secret should return 401", header: "wk_" + prefix + "_wrong", message: "invalid api key"},
+		{name: "given unknown prefix should return 401", header: "wk_0000000000000000_x", message: "invalid api key"},
+		{name: "given malformed key should return 401", header: "not-a-key", message: "invalid api key"},
+		{name: "given revoked key should return 401", key: postgres.APIKey{RevokedAt: &past}, header: key, message: "api key revoked"},
+		{name: "given expired key should return 401", key: postgres.APIKey{ExpiresAt: &past}, header: key, message: "api key expired"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, p := run(newStore(tc.key), tc.header)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.message)
+			assert.Nil(t, p)
+		})
+	}
+}
+
+func TestMiddlewareAfterAPIKey(t *testing.T) {
+	key, prefix, hash, _ := GenerateAPIKey()
+	store := &StubKeyStore{Keys: map[string]*postgres.APIKey{prefix: {ID: 5, Prefix: prefix, SecretHash: hash, Scopes: []string{PermWalletRead}}}}
+
+	e := echo.New()
+	e.Use(apperrs.CustomErrorMiddleware)
+	e.Use(APIKeys(store), Middleware(&Keys{HMACSecret: testSecret}))
+	e.GET("/api/v1/wallets", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+	req.Header.Set(HeaderAPIKey, key)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,72 @@
+// Package auth verifies bearer JWTs and carries the caller's identity
+// through the Echo context.
+package auth
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScopeAdmin in a token grants the admin role without a user_role row, for
+// service accounts that have no user of their own.
+const ScopeAdmin = "admin"
+
+const principalKey = "auth.principal"
+
+// Principal is the authenticated caller. For a token the subject is the user
+// id that wallets are owned by, and its permissions are filled in by
+// Policy.Resolve. An API key has no user; KeyID identifies it instead.
+type Principal struct {
+	Subject string
+	UserID  int
+	KeyID   int
+	Scopes  []string
+	Roles   []string
+
+	granted map[string]bool
+	owned   map[string]bool
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Granted reports whether p holds permission on every user's wallets.
+func (p *Principal) Granted(permission string) bool {
+	return p.granted[permission]
+}
+
+// Allowed reports whether p holds permission on the wallets of userId,
+// either through a role or as their owner.
+func (p *Principal) Allowed(permission string, userId int) bool {
+	return p.granted[permission] || (p.UserID == userId && p.owned[permission])
+}
+
+// MayHave reports whether p holds permission on at least its own wallets.
+func (p *Principal) MayHave(permission string) bool {
+	return p.granted[permission] || p.owned[permission]
+}
+
+func SetPrincipal(c echo.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+func FromContext(c echo.Context) (*Principal, bool) {
+	p, ok := c.Get(principalKey).(*Principal)
+	return p, ok && p != nil
+}
+
+func newPrincipal(subject string, scope string) (*Principal, bool) {
+	userId, err := strconv.Atoi(subject)
+	if err != nil || userId <= 0 {
+		return nil, false
+	}
+	return &Principal{Subject: subject, UserID: userId, Scopes: strings.Fields(scope)}, true
+}
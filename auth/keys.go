@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keys verify token signatures. A token is accepted with HS256 when
+// HMACSecret is set and with RS256 when RSAPublicKey is set.
+type Keys struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+}
+
+var ErrNoKeys = errors.New("auth: no JWT verification key configured")
+
+// LoadKeys reads the verification keys in cfg. Each key is used inline
+// when set, and read from its file otherwise.
+func LoadKeys(cfg config.Auth) (*Keys, error) {
+	keys := &Keys{}
+
+	secret, err := readSetting(cfg.HS256Secret, cfg.HS256SecretFile, "JWT_HS256_SECRET_FILE")
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) > 0 {
+		keys.HMACSecret = secret
+	}
+
+	pem, err := readSetting(cfg.RS256PublicKey, cfg.RS256PublicKeyFile, "JWT_RS256_PUBLIC_KEY_FILE")
+	if err != nil {
+		return nil, err
+	}
+	if len(pem) > 0 {
+		keys.RSAPublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth: JWT_RS256_PUBLIC_KEY: %w", err)
+		}
+	}
+
+	if keys.HMACSecret == nil && keys.RSAPublicKey == nil {
+		return nil, ErrNoKeys
+	}
+	return keys, nil
+}
+
+// readSetting returns value, or the contents of the file at path. name is
+// the variable that sets path, for the error.
+func readSetting(value string, path string, name string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %s: %w", name, err)
+	}
+	return b, nil
+}
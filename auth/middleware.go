@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+type claims struct {
+	Scope string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// Middleware rejects requests without a valid "Authorization: Bearer" JWT
+// and stores the caller's Principal in the context. Only HS256 and RS256
+// are accepted, each only when its key is configured, so a token cannot
+// pick a weaker algorithm than the server expects. Requests already
+// authenticated by APIKeys pass through.
+func Middleware(keys *Keys) echo.MiddlewareFunc {
+	var methods []string
+	if keys.HMACSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if keys.RSAPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithExpirationRequired())
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() == jwt.SigningMethodRS256.Alg() {
+			return keys.RSAPublicKey, nil
+		}
+		return keys.HMACSecret, nil
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := FromContext(c); ok {
+				return next(c)
+			}
+
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			raw, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || raw == "" {
+				return apperrs.NewUnauthorizedError("missing bearer token")
+			}
+
+			var cl claims
+			if _, err := parser.ParseWithClaims(raw, &cl, keyFunc); err != nil {
+				return apperrs.NewUnauthorizedError("invalid token")
+			}
+
+			p, ok := newPrincipal(cl.Subject, cl.Scope)
+			if !ok {
+				return apperrs.NewUnauthorizedError("token subject is not a user id")
+			}
+
+			SetPrincipal(c, p)
+			return next(c)
+		}
+	}
+}
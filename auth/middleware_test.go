@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("test-secret")
+
+func newServer(keys *Keys) *echo.Echo {
+	e := echo.New()
+	e.Use(apperrs.CustomErrorMiddleware)
+	e.Use(Middleware(keys))
+	e.GET("/api/v1/wallets", func(c echo.Context) error {
+		p, _ := FromContext(c)
+		return c.JSON(http.StatusOK, p)
+	})
+	return e
+}
+
+func doRequest(e *echo.Echo, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, c jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, c).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func validClaims(sub string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "scope": "admin", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestMiddleware(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newServer(&Keys{HMACSecret: testSecret, RSAPublicKey: &rsaKey.PublicKey})
+
+	t.Run("given HS256 token should set principal", func(t *testing.T) {
+		rec := doRequest(e, sign(t, jwt.SigningMethodHS256, testSecret, validClaims("7")))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Subject":"7","UserID":7`)
+		assert.Contains(t, rec.Body.String(), `"Scopes":["admin"]`)
+	})
+
+	t.Run("given RS256 token should set principal", func(t *testing.T) {
+		rec := doRequest(e, sign(t, jwt.SigningMethodRS256, rsaKey, validClaims("8")))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"UserID":8`)
+	})
+
+	t.Run("given no token should return 401", func(t *testing.T) {
+		rec := doRequest(e, "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given expired token should return 401", func(t *testing.T) {
+		c := validClaims("7")
+		c["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		rec := doRequest(e, sign(t, jwt.SigningMethodHS256, testSecret, c))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given token without expiry should return 401", func(t *testing.T) {
+		c := validClaims("7")
+		delete(c, "exp")
+
+		rec := doRequest(e, sign(t, jwt.SigningMethodHS256, testSecret, c))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given wrong secret should return 401", func(t *testing.T) {
+		rec := doRequest(e, sign(t, jwt.SigningMethodHS256, []byte("other"), validClaims("7")))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given unconfigured algorithm should return 401", func(t *testing.T) {
+		hsOnly := newServer(&Keys{HMACSecret: testSecret})
+
+		rec := doRequest(hsOnly, sign(t, jwt.SigningMethodRS256, rsaKey, validClaims("7")))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("given non-numeric subject should return 401", func(t *testing.T) {
+		rec := doRequest(e, sign(t, jwt.SigningMethodHS256, testSecret, validClaims("alice")))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestLoadKeys(t *testing.T) {
+	t.Run("given no keys should return ErrNoKeys", func(t *testing.T) {
+		_, err := LoadKeys(config.Auth{})
+
+		assert.ErrorIs(t, err, ErrNoKeys)
+	})
+
+	t.Run("given secret and public key file should load both", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "jwt.pub")
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		keys, err := LoadKeys(config.Auth{HS256Secret: "s3cret", RS256PublicKeyFile: path})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), keys.HMACSecret)
+		assert.True(t, rsaKey.PublicKey.Equal(keys.RSAPublicKey))
+	})
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+)
+
+// Permissions checked by the wallet routes.
+const (
+	PermWalletRead     = "wallet:read"
+	PermWalletWrite    = "wallet:write"
+	PermWalletTransact = "wallet:transact"
+	PermWalletAdjust   = "wallet:adjust"
+	PermWalletDelete   = "wallet:delete"
+	PermWalletFreeze   = "wallet:freeze"
+
+	PermAPIKeyManage  = "apikey:manage"
+	PermAuditRead     = "audit:read"
+	PermWebhookManage = "webhook:manage"
+)
+
+// KeyScopes are the permissions an API key can be given.
+var KeyScopes = []string{PermWalletRead, PermWalletWrite, PermWalletTransact, PermWalletAdjust, PermWalletDelete, PermWalletFreeze}
+
+// Roles with a meaning of their own. Other roles only matter through the
+// permissions role_permission gives them.
+const (
+	RoleAdmin = "admin"
+	RoleOwner = "owner"
+)
+
+// Policy evaluates the role_permission table.
+type Policy struct {
+	grants map[string]map[string]bool
+}
+
+func NewPolicy(grants map[string][]string) *Policy {
+	p := &Policy{grants: map[string]map[string]bool{}}
+	for role, permissions := range grants {
+		p.grants[role] = map[string]bool{}
+		for _, permission := range permissions {
+			p.grants[role][permission] = true
+		}
+	}
+	return p
+}
+
+// LoadPolicy reads the role grants once; changes to role_permission apply
+// after a restart.
+func LoadPolicy(ctx context.Context, store postgres.RoleStorer) (*Policy, error) {
+	grants, err := store.RolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPolicy(grants), nil
+}
+
+// Resolve gives p the permissions of its roles, plus those of the owner role
+// for its own wallets.
+func (pol *Policy) Resolve(p *Principal) {
+	p.granted = map[string]bool{}
+	for _, role := range p.Roles {
+		for permission := range pol.grants[role] {
+			p.granted[permission] = true
+		}
+	}
+	p.owned = pol.grants[RoleOwner]
+}
+
+// Roles looks up the caller's roles in user_role and resolves its
+// permissions. It must run after Middleware. API keys are left alone, their
+// scopes already are their permissions.
+func Roles(policy *Policy, store postgres.RoleStorer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, ok := FromContext(c)
+			if !ok {
+				return apperrs.NewUnauthorizedError("missing credentials")
+			}
+			if p.KeyID != 0 {
+				return next(c)
+			}
+
+			roles, err := store.FindRolesByUserId(c.Request().Context(), p.UserID)
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "Role lookup failed", "err", err)
+				return apperrs.NewInternalServerError("Role lookup failed")
+			}
+			if p.HasScope(ScopeAdmin) {
+				roles = append(roles, RoleAdmin)
+			}
+			p.Roles = roles
+			policy.Resolve(p)
+
+			return next(c)
+		}
+	}
+}
+
+// Require rejects callers that cannot hold permission on any wallet, not
+// even their own. Checks against a particular wallet are left to the handler.
+func Require(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, ok := FromContext(c)
+			if !ok {
+				return apperrs.NewUnauthorizedError("missing credentials")
+			}
+			if !p.MayHave(permission) {
+				return Forbidden(permission)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Forbidden is the error for a caller that lacks permission.
+func Forbidden(permission string) error {
+	return apperrs.NewForbiddenError("permission " + permission + " required")
+}
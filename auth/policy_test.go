@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testGrants = map[string][]string{
+	RoleOwner:  {PermWalletRead, PermWalletWrite},
+	RoleAdmin:  {PermWalletRead, PermWalletWrite, PermWalletAdjust},
+	"operator": {PermWalletRead, PermWalletAdjust},
+}
+
+type StubRoleStore struct {
+	Roles map[int][]string
+	Err   error
+}
+
+func (s StubRoleStore) RolePermissions(ctx context.Context) (map[string][]string, error) {
+	return testGrants, s.Err
+}
+
+func (s StubRoleStore) FindRolesByUserId(ctx context.Context, userId int) ([]string, error) {
+	return s.Roles[userId], s.Err
+}
+
+func TestPolicyResolve(t *testing.T) {
+	policy := NewPolicy(testGrants)
+
+	t.Run("given no roles should only allow owner permissions on own wallets", func(t *testing.T) {
+		p := &Principal{UserID: 1}
+		policy.Resolve(p)
+
+		assert.True(t, p.Allowed(PermWalletRead, 1))
+		assert.False(t, p.Allowed(PermWalletRead, 2))
+		assert.False(t, p.Granted(PermWalletRead))
+		assert.True(t, p.MayHave(PermWalletWrite))
+		assert.False(t, p.MayHave(PermWalletAdjust))
+	})
+
+	t.Run("given operator role should grant its permissions on every wallet", func(t *testing.T) {
+		p := &Principal{UserID: 1, Roles: []string{"operator"}}
+		policy.Resolve(p)
+
+		assert.True(t, p.Allowed(PermWalletRead, 2))
+		assert.True(t, p.Allowed(PermWalletAdjust, 2))
+		assert.False(t, p.Allowed(PermWalletWrite, 2))
+		assert.True(t, p.Allowed(PermWalletWrite, 1))
+	})
+
+	t.Run("given unknown role should grant nothing", func(t *testing.T) {
+		p := &Principal{UserID: 1, Roles: []string{"intern"}}
+		policy.Resolve(p)
+
+		assert.False(t, p.Granted(PermWalletRead))
+	})
+}
+
+func TestRoles(t *testing.T) {
+	newContext := func(p *Principal) (echo.Context, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil), rec)
+		if p != nil {
+			SetPrincipal(c, p)
+		}
+		return c, rec
+	}
+	policy := NewPolicy(testGrants)
+
+	t.Run("given user roles should resolve permissions", func(t *testing.T) {
+		store := StubRoleStore{Roles: map[int][]string{1: {"operator"}}}
+		p := &Principal{Subject: "1", UserID: 1}
+		c, _ := newContext(p)
+
+		err := Roles(policy, store)(func(c echo.Context) error { return nil })(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"operator"}, p.Roles)
+		assert.True(t, p.Granted(PermWalletAdjust))
+	})
+
+	t.Run("given admin scope should add admin role", func(t *testing.T) {
+		p := &Principal{Subject: "1", UserID: 1, Scopes: []string{ScopeAdmin}}
+		c, _ := newContext(p)
+
+		err := Roles(policy, StubRoleStore{})(func(c echo.Context) error { return nil })(c)
+
+		assert.NoError(t, err)
+		assert.True(t, p.Granted(PermWalletWrite))
+	})
+
+	t.Run("given role lookup fails should return 500", func(t *testing.T) {
+		c, _ := newContext(&Principal{Subject: "1", UserID: 1})
+
+		err := Roles(policy, StubRoleStore{Err: errors.New("db down")})(func(c echo.Context) error { return nil })(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	})
+}
+
+func TestRequire(t *testing.T) {
+	policy := NewPolicy(testGrants)
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+
+	run := func(p *Principal, permission string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil), rec)
+		if p != nil {
+			policy.Resolve(p)
+			SetPrincipal(c, p)
+		}
+		err := apperrs.CustomErrorMiddleware(Require(permission)(ok))(c)
+		assert.NoError(t, err)
+		return rec
+	}
+
+	t.Run("given owner permission should pass", func(t *testing.T) {
+		rec := run(&Principal{UserID: 1}, PermWalletWrite)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("given missing permission should return 403", func(t *testing.T) {
+		rec := run(&Principal{UserID: 1}, PermWalletAdjust)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "permission wallet:adjust required")
+	})
+
+	t.Run("given no principal should return 401", func(t *testing.T) {
+		rec := run(nil, PermWalletRead)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,318 @@
+// Package config holds the settings of the service. Each setting starts at
+// its default and can be overridden, in increasing order of precedence, by
+// a YAML file, an environment variable and a command line flag. The file is
+// named by the -config flag or CONFIG_FILE.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is every setting. The env and flag tags name where a setting can
+// be overridden; secret ones are redacted by Redacted and have no flag, as
+// command lines are visible to other users of the host.
+type Config struct {
+	Server   Server   `yaml:"server"`
+	DB       DB       `yaml:"db"`
+	Auth     Auth     `yaml:"auth"`
+	Features Features `yaml:"features"`
+	Log      Log      `yaml:"log"`
+	Tracing  Tracing  `yaml:"tracing"`
+}
+
+// Server is the HTTP server. On SIGTERM readiness fails at once, and the
+// server keeps serving for DrainDelay so load balancers stop sending it
+// requests before it shuts down. ReadyTimeout bounds the readiness checks.
+// TrustedProxies is a comma-separated list of CIDRs whose X-Forwarded-For is
+// believed; with none the client IP is the address of the connection.
+type Server struct {
+	Port            int           `yaml:"port" env:"SERVER_PORT" flag:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY" flag:"drain-delay"`
+	ReadyTimeout    time.Duration `yaml:"ready_timeout" env:"SERVER_READY_TIMEOUT" flag:"ready-timeout"`
+	TLSCertFile     string        `yaml:"tls_cert_file" env:"TLS_CERT_FILE" flag:"tls-cert-file"`
+	TLSKeyFile      string        `yaml:"tls_key_file" env:"TLS_KEY_FILE" flag:"tls-key-file"`
+	TrustedProxies  string        `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" flag:"trusted-proxies"`
+}
+
+// TrustedProxyRanges parses TrustedProxies.
+func (s Server) TrustedProxyRanges() ([]*net.IPNet, error) {
+	var ranges []*net.IPNet
+	for _, cidr := range strings.Split(s.TrustedProxies, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR such as 10.0.0.0/8", cidr)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges, nil
+}
+
+// DB is the Postgres connection. Timeout bounds the database work of one
+// request; 0 means no deadline. ConnectTimeout is how long startup keeps
+// retrying a database that is not up yet.
+type DB struct {
+	Host            string        `yaml:"host" env:"POSTGRES_HOST" flag:"db-host"`
+	Port            int           `yaml:"port" env:"POSTGRES_PORT" flag:"db-port"`
+	User            string        `yaml:"user" env:"POSTGRES_USER" flag:"db-user"`
+	Password        string        `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"POSTGRES_DB_NAME" flag:"db-name"`
+	SSLMode         string        `yaml:"ssl_mode" env:"POSTGRES_SSL_MODE" flag:"db-ssl-mode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"POSTGRES_MAX_OPEN_CONNS" flag:"db-max-open-conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"POSTGRES_MAX_IDLE_CONNS" flag:"db-max-idle-conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"POSTGRES_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime"`
+	Timeout         time.Duration `yaml:"timeout" env:"DB_TIMEOUT" flag:"db-timeout"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT" flag:"db-connect-timeout"`
+}
+
+// Auth holds the keys that verify bearer tokens: an HS256 secret, an RS256
+// public key in PEM, or both. Each can be given inline or as the path of a
+// file holding it.
+type Auth struct {
+	HS256Secret        string `yaml:"hs256_secret" env:"JWT_HS256_SECRET" secret:"true"`
+	HS256SecretFile    string `yaml:"hs256_secret_file" env:"JWT_HS256_SECRET_FILE" flag:"jwt-hs256-secret-file"`
+	RS256PublicKey     string `yaml:"rs256_public_key" env:"JWT_RS256_PUBLIC_KEY"`
+	RS256PublicKeyFile string `yaml:"rs256_public_key_file" env:"JWT_RS256_PUBLIC_KEY_FILE" flag:"jwt-rs256-public-key-file"`
+}
+
+// Features switch parts of the service. WalletStore picks the wallet
+// backend: postgres, mysql at MySQLDSN, or memory. Audit serves the audit
+// log and Webhooks the webhook subscriptions; only the postgres store writes
+// their events, so the others need both off. WebhookDispatch runs the
+// delivery loop in this process.
+type Features struct {
+	WalletStore     string `yaml:"wallet_store" env:"WALLET_STORE" flag:"wallet-store"`
+	MySQLDSN        string `yaml:"mysql_dsn" env:"MYSQL_DSN" secret:"true"`
+	Audit           bool   `yaml:"audit" env:"AUDIT" flag:"audit"`
+	Webhooks        bool   `yaml:"webhooks" env:"WEBHOOKS" flag:"webhooks"`
+	WebhookDispatch bool   `yaml:"webhook_dispatch" env:"WEBHOOK_DISPATCH" flag:"webhook-dispatch"`
+}
+
+// Log sets what the service logs: records at Level (debug, info, warn or
+// error) and above, as json or text.
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" flag:"log-level"`
+	Format string `yaml:"format" env:"LOG_FORMAT" flag:"log-format"`
+}
+
+// Tracing sends OpenTelemetry spans to the OTLP/HTTP collector at Endpoint,
+// such as http://localhost:4318. An empty Endpoint turns the export off.
+type Tracing struct {
+	Endpoint    string `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" flag:"tracing-endpoint"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME" flag:"tracing-service-name"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:            1323,
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 10 * time.Second,
+			ReadyTimeout:    2 * time.Second,
+		},
+		DB: DB{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			Timeout:         5 * time.Second,
+			ConnectTimeout:  30 * time.Second,
+		},
+		Features: Features{
+			WalletStore:     "postgres",
+			Audit:           true,
+			Webhooks:        true,
+			WebhookDispatch: true,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: Tracing{
+			ServiceName: "wallet-api",
+		},
+	}
+}
+
+// Load builds the configuration from the file, the environment and the
+// flags in args, and validates it.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("wallet-app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", "", "YAML configuration file")
+
+	cfg := Default()
+	settings := fields(&cfg)
+	values := map[string]*string{}
+	for _, s := range settings {
+		if s.flag != "" {
+			values[s.flag] = fs.String(s.flag, "", "overrides "+s.path)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("config: unexpected argument %q", fs.Arg(0))
+	}
+
+	if *path == "" {
+		*path = os.Getenv("CONFIG_FILE")
+	}
+	if *path != "" {
+		if err := readFile(*path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		if v := os.Getenv(s.env); v != "" {
+			if err := s.set(v); err != nil {
+				return nil, fmt.Errorf("config: %s: %w", s.env, err)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if err == nil && s.flag == f.Name {
+				if setErr := s.set(*values[f.Name]); setErr != nil {
+					err = fmt.Errorf("config: -%s: %w", f.Name, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// readFile decodes the YAML file at path over cfg. Unknown keys are an
+// error, so a misspelt setting is not silently ignored.
+func readFile(path string, cfg *Config) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// Redacted returns a copy of c with every secret that is set replaced, fit
+// for printing.
+func (c Config) Redacted() Config {
+	for _, s := range fields(&c) {
+		if s.secret && !s.value.IsZero() {
+			s.value.SetString("REDACTED")
+		}
+	}
+	return c
+}
+
+// Print writes c as YAML with its secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// setting is one leaf field of Config.
+type setting struct {
+	path   string
+	env    string
+	flag   string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists the settings of cfg, addressable so they can be set.
+func fields(cfg *Config) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			path := prefix + f.Tag.Get("yaml")
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			settings = append(settings, setting{
+				path:   path,
+				env:    f.Tag.Get("env"),
+				flag:   f.Tag.Get("flag"),
+				secret: f.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case string:
+		s.value.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		s.value.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		s.value.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 5s", raw)
+		}
+		s.value.SetInt(int64(d))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
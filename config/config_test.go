@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// setRequired sets the settings that have no default.
+func setRequired(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("POSTGRES_HOST", "localhost")
+	t.Setenv("POSTGRES_USER", "root")
+	t.Setenv("POSTGRES_DB_NAME", "wallet")
+	t.Setenv("JWT_HS256_SECRET", "secret")
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("given only env should keep the defaults", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("POSTGRES_PORT", "")
+
+		cfg, err := config.Load(nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1323, cfg.Server.Port)
+		assert.Equal(t, 5432, cfg.DB.Port)
+		assert.Equal(t, 5*time.Second, cfg.DB.Timeout)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, "postgres", cfg.Features.WalletStore)
+	})
+
+	t.Run("given file, env and flag should prefer flag over env over file", func(t *testing.T) {
+		setRequired(t)
+		path := writeFile(t, `
+server:
+  port: 8000
+  read_timeout: 5s
+db:
+  timeout: 1s
+  max_open_conns: 10
+`)
+		t.Setenv("SERVER_PORT", "9000")
+		t.Setenv("DB_TIMEOUT", "2s")
+
+		cfg, err := config.Load([]string{"-config", path, "-db-timeout", "3s"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9000, cfg.Server.Port)
+		assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+		assert.Equal(t, 3*time.Second, cfg.DB.Timeout)
+		assert.Equal(t, 10, cfg.DB.MaxOpenConns)
+	})
+
+	t.Run("given CONFIG_FILE should read it", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("SERVER_PORT", "")
+		t.Setenv("CONFIG_FILE", writeFile(t, "server:\n  port: 8000\n"))
+
+		cfg, err := config.Load(nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8000, cfg.Server.Port)
+	})
+
+	t.Run("given unknown key in file should return error", func(t *testing.T) {
+		setRequired(t)
+		path := writeFile(t, "server:\n  prot: 8000\n")
+
+		_, err := config.Load([]string{"-config", path})
+
+		assert.ErrorContains(t, err, "field prot not found")
+	})
+
+	t.Run("given malformed env value should name the variable", func(t *testing.T) {
+		setRequired(t)
+		t.Setenv("DB_TIMEOUT", "five")
+
+		_, err := config.Load(nil)
+
+		assert.EqualError(t, err, `config: DB_TIMEOUT: "five" is not a duration such as 5s`)
+	})
+
+	t.Run("given unknown flag should return error", func(t *testing.T) {
+		setRequired(t)
+
+		_, err := config.Load([]string{"-db-password", "secret"})
+
+		assert.ErrorContains(t, err, "flag provided but not defined: -db-password")
+	})
+}
+
+func TestPrint(t *testing.T) {
+	cfg := config.Default()
+	cfg.DB.Host = "localhost"
+	cfg.DB.Password = "s3cret"
+	cfg.Auth.HS256Secret = "s3cret"
+	cfg.Features.MySQLDSN = "wallet:s3cret@tcp(localhost:3306)/wallet"
+
+	var out strings.Builder
+	err := cfg.Print(&out)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "host: localhost")
+	assert.Contains(t, out.String(), "password: REDACTED")
+	assert.Contains(t, out.String(), "mysql_dsn: REDACTED")
+	assert.Contains(t, out.String(), "hs256_secret: REDACTED")
+	assert.Contains(t, out.String(), "timeout: 5s")
+	assert.NotContains(t, out.String(), "s3cret")
+	assert.Equal(t, "s3cret", cfg.DB.Password, "printing should not change the config")
+}
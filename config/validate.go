@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Validate reports every invalid setting at once, each naming the variable
+// that sets it.
+func (c Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("config: "+format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port (SERVER_PORT) must be between 1 and 65535")
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 || c.Server.ShutdownTimeout < 0 || c.Server.DrainDelay < 0 {
+		add("server timeouts must not be negative")
+	}
+	if c.Server.ReadyTimeout <= 0 {
+		add("server.ready_timeout (SERVER_READY_TIMEOUT) must be positive")
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		add("server.tls_cert_file (TLS_CERT_FILE) and server.tls_key_file (TLS_KEY_FILE) must be set together")
+	}
+	if _, err := c.Server.TrustedProxyRanges(); err != nil {
+		add("server.trusted_proxies (SERVER_TRUSTED_PROXIES): %v", err)
+	}
+
+	if c.DB.Host == "" {
+		add("db.host (POSTGRES_HOST) is required")
+	}
+	if c.DB.Port < 1 || c.DB.Port > 65535 {
+		add("db.port (POSTGRES_PORT) must be between 1 and 65535")
+	}
+	if c.DB.User == "" {
+		add("db.user (POSTGRES_USER) is required")
+	}
+	if c.DB.Name == "" {
+		add("db.name (POSTGRES_DB_NAME) is required")
+	}
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 {
+		add("db pool sizes must not be negative")
+	}
+	if c.DB.ConnMaxLifetime < 0 || c.DB.Timeout < 0 || c.DB.ConnectTimeout < 0 {
+		add("db durations must not be negative")
+	}
+
+	if c.Auth.HS256Secret == "" && c.Auth.HS256SecretFile == "" && c.Auth.RS256PublicKey == "" && c.Auth.RS256PublicKeyFile == "" {
+		add("auth.hs256_secret (JWT_HS256_SECRET) or auth.rs256_public_key (JWT_RS256_PUBLIC_KEY), or their _file variants, is required")
+	}
+
+	switch c.Features.WalletStore {
+	case "postgres", "memory":
+	case "mysql":
+		if c.Features.MySQLDSN == "" {
+			add("features.mysql_dsn (MYSQL_DSN) is required with the mysql wallet store")
+		}
+	default:
+		add("features.wallet_store (WALLET_STORE) must be postgres, mysql or memory, not %q", c.Features.WalletStore)
+	}
+	if store := c.Features.WalletStore; store == "mysql" || store == "memory" {
+		// These stores write no audit or outbox events with their changes
+		if c.Features.Audit {
+			add("features.audit (AUDIT) needs the postgres wallet store; set it to false to run the %s store without an audit log", store)
+		}
+		if c.Features.Webhooks {
+			add("features.webhooks (WEBHOOKS) needs the postgres wallet store; set it to false to run the %s store without webhooks", store)
+		}
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		add("log.level (LOG_LEVEL) must be debug, info, warn or error, not %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		add("log.format (LOG_FORMAT) must be json or text, not %q", c.Log.Format)
+	}
+
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("tracing.endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) must be an http or https URL such as http://localhost:4318, not %q", c.Tracing.Endpoint)
+		}
+		if c.Tracing.ServiceName == "" {
+			add("tracing.service_name (OTEL_SERVICE_NAME) is required with a tracing endpoint")
+		}
+	}
+
+	return errors.Join(errs...)
+}
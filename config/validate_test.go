@@ -0,0 +1,110 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() config.Config {
+	cfg := config.Default()
+	cfg.DB.Host = "localhost"
+	cfg.DB.User = "root"
+	cfg.DB.Name = "wallet"
+	cfg.Auth.HS256Secret = "secret"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("given defaults and a database should pass", func(t *testing.T) {
+		assert.NoError(t, validConfig().Validate())
+	})
+
+	t.Run("given missing database settings should report each of them", func(t *testing.T) {
+		err := config.Default().Validate()
+
+		assert.EqualError(t, err, "config: db.host (POSTGRES_HOST) is required\n"+
+			"config: db.user (POSTGRES_USER) is required\n"+
+			"config: db.name (POSTGRES_DB_NAME) is required\n"+
+			"config: auth.hs256_secret (JWT_HS256_SECRET) or auth.rs256_public_key (JWT_RS256_PUBLIC_KEY), or their _file variants, is required")
+	})
+
+	t.Run("given a key file only should pass", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Auth.HS256Secret = ""
+		cfg.Auth.RS256PublicKeyFile = "/etc/wallet/jwt.pub"
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("given invalid trusted proxy should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Server.TrustedProxies = "10.0.0.0/8, 10.0.0.1"
+
+		assert.EqualError(t, cfg.Validate(), `config: server.trusted_proxies (SERVER_TRUSTED_PROXIES): "10.0.0.1" is not a CIDR such as 10.0.0.0/8`)
+	})
+
+	t.Run("given tls cert without key should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Server.TLSCertFile = "server.crt"
+
+		assert.EqualError(t, cfg.Validate(), "config: server.tls_cert_file (TLS_CERT_FILE) and server.tls_key_file (TLS_KEY_FILE) must be set together")
+	})
+
+	t.Run("given port out of range should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Server.Port = 70000
+
+		assert.EqualError(t, cfg.Validate(), "config: server.port (SERVER_PORT) must be between 1 and 65535")
+	})
+
+	t.Run("given mysql store without dsn should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Features.WalletStore = "mysql"
+		cfg.Features.Audit = false
+		cfg.Features.Webhooks = false
+
+		assert.EqualError(t, cfg.Validate(), "config: features.mysql_dsn (MYSQL_DSN) is required with the mysql wallet store")
+	})
+
+	t.Run("given memory store with audit and webhooks should report both", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Features.WalletStore = "memory"
+
+		assert.EqualError(t, cfg.Validate(), "config: features.audit (AUDIT) needs the postgres wallet store; set it to false to run the memory store without an audit log\n"+
+			"config: features.webhooks (WEBHOOKS) needs the postgres wallet store; set it to false to run the memory store without webhooks")
+	})
+
+	t.Run("given memory store without audit and webhooks should pass", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Features.WalletStore = "memory"
+		cfg.Features.Audit = false
+		cfg.Features.Webhooks = false
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("given unknown store should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Features.WalletStore = "redis"
+
+		assert.EqualError(t, cfg.Validate(), `config: features.wallet_store (WALLET_STORE) must be postgres, mysql or memory, not "redis"`)
+	})
+
+	t.Run("given tracing endpoint that is not a url should return error", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Tracing.Endpoint = "localhost:4318"
+
+		assert.EqualError(t, cfg.Validate(), `config: tracing.endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) must be an http or https URL such as http://localhost:4318, not "localhost:4318"`)
+	})
+
+	t.Run("given unknown log level and format should report both", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Log.Level = "verbose"
+		cfg.Log.Format = "xml"
+
+		assert.EqualError(t, cfg.Validate(), `config: log.level (LOG_LEVEL) must be debug, info, warn or error, not "verbose"`+"\n"+
+			`config: log.format (LOG_FORMAT) must be json or text, not "xml"`)
+	})
+}
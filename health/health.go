@@ -0,0 +1,146 @@
+// Package health serves the liveness and readiness probes. Liveness only
+// says the process is serving; readiness also needs the database to answer,
+// its schema to be migrated and the server not to be shutting down.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Pinger is the database, checked by PingContext.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Schema reports the migration version of the database and the one the
+// binary expects.
+type Schema interface {
+	Current(ctx context.Context) (int, error)
+	Latest() int
+}
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check is the result of one readiness check.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the body of a probe response.
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks,omitempty"`
+}
+
+type Handler struct {
+	db           Pinger
+	schema       Schema
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewHandler checks db and schema, allowing timeout for both together.
+func NewHandler(db Pinger, schema Schema, timeout time.Duration) *Handler {
+	return &Handler{db: db, schema: schema, timeout: timeout}
+}
+
+// ShutDown makes readiness fail from now on, so load balancers stop
+// sending requests before the server stops taking them.
+func (h *Handler) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// LiveHandler
+//
+// @Summary Liveness probe
+// @Description The process is up and serving requests
+// @Tags health
+// @Produce json
+// @Router /healthz [get]
+// @Success 200 {object} Report
+func (h *Handler) LiveHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// ReadyHandler
+//
+// @Summary Readiness probe
+// @Description The database answers, its schema is at the expected migration and the server is not shutting down
+// @Tags health
+// @Produce json
+// @Router /readyz [get]
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+func (h *Handler) ReadyHandler(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.timeout)
+	defer cancel()
+
+	checks := []Check{
+		h.check(ctx, "shutdown", func(ctx context.Context) error {
+			if h.shuttingDown.Load() {
+				return notReady("shutting down")
+			}
+			return nil
+		}),
+		h.check(ctx, "database", h.db.PingContext),
+		h.check(ctx, "migrations", func(ctx context.Context) error {
+			current, err := h.schema.Current(ctx)
+			if err != nil {
+				return err
+			}
+			if latest := h.schema.Latest(); current != latest {
+				return notReady(fmt.Sprintf("schema at version %d, want %d", current, latest))
+			}
+			return nil
+		}),
+	}
+
+	report := Report{Status: StatusOK, Checks: checks}
+	code := http.StatusOK
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			report.Status = StatusUnavailable
+			code = http.StatusServiceUnavailable
+		}
+	}
+	return c.JSON(code, report)
+}
+
+// notReady is a failed check whose message is safe to show to anyone.
+type notReady string
+
+func (e notReady) Error() string {
+	return string(e)
+}
+
+// check runs fn as the check called name. The probe is unauthenticated, so
+// other errors, which may name hosts or users, are only logged.
+func (h *Handler) check(ctx context.Context, name string, fn func(ctx context.Context) error) Check {
+	err := fn(ctx)
+	if err == nil {
+		return Check{Name: name, Status: StatusOK}
+	}
+
+	slog.WarnContext(ctx, "readiness check failed", "check", name, "err", err)
+	var reason notReady
+	switch {
+	case errors.As(err, &reason):
+		return Check{Name: name, Status: StatusUnavailable, Error: string(reason)}
+	case errors.Is(err, context.DeadlineExceeded):
+		return Check{Name: name, Status: StatusUnavailable, Error: "timed out"}
+	}
+	return Check{Name: name, Status: StatusUnavailable, Error: "failed, see the service logs"}
+}
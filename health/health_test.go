@@ -0,0 +1,133 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/health"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubDB struct {
+	err  error
+	wait bool
+}
+
+func (s stubDB) PingContext(ctx context.Context) error {
+	if s.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+type stubSchema struct {
+	current int
+	latest  int
+	err     error
+}
+
+func (s stubSchema) Current(ctx context.Context) (int, error) {
+	return s.current, s.err
+}
+
+func (s stubSchema) Latest() int {
+	return s.latest
+}
+
+func serve(t *testing.T, h *health.Handler, path string) (int, health.Report) {
+	e := echo.New()
+	e.GET("/healthz", h.LiveHandler)
+	e.GET("/readyz", h.ReadyHandler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report health.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestLiveHandler(t *testing.T) {
+	h := health.NewHandler(stubDB{err: errors.New("connection refused")}, stubSchema{}, time.Second)
+
+	code, report := serve(t, h, "/healthz")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, report.Status)
+}
+
+func TestReadyHandler(t *testing.T) {
+	ok := health.Check{Status: health.StatusOK}
+	check := func(name string, c health.Check) health.Check {
+		c.Name = name
+		return c
+	}
+	failed := func(message string) health.Check {
+		return health.Check{Status: health.StatusUnavailable, Error: message}
+	}
+
+	tests := []struct {
+		name     string
+		db       stubDB
+		schema   stubSchema
+		shutDown bool
+		wantCode int
+		want     []health.Check
+	}{
+		{
+			name:     "given a migrated database should be ready",
+			schema:   stubSchema{current: 8, latest: 8},
+			wantCode: http.StatusOK,
+			want:     []health.Check{check("shutdown", ok), check("database", ok), check("migrations", ok)},
+		},
+		{
+			name:     "given an unreachable database should hide the error",
+			db:       stubDB{err: errors.New("dial tcp 10.0.0.5:5432: connection refused")},
+			schema:   stubSchema{current: 8, latest: 8},
+			wantCode: http.StatusServiceUnavailable,
+			want:     []health.Check{check("shutdown", ok), check("database", failed("failed, see the service logs")), check("migrations", ok)},
+		},
+		{
+			name:     "given a slow database should time out",
+			db:       stubDB{wait: true},
+			schema:   stubSchema{current: 8, latest: 8},
+			wantCode: http.StatusServiceUnavailable,
+			want:     []health.Check{check("shutdown", ok), check("database", failed("timed out")), check("migrations", ok)},
+		},
+		{
+			name:     "given pending migrations should not be ready",
+			schema:   stubSchema{current: 7, latest: 8},
+			wantCode: http.StatusServiceUnavailable,
+			want:     []health.Check{check("shutdown", ok), check("database", ok), check("migrations", failed("schema at version 7, want 8"))},
+		},
+		{
+			name:     "given shutdown should not be ready",
+			schema:   stubSchema{current: 8, latest: 8},
+			shutDown: true,
+			wantCode: http.StatusServiceUnavailable,
+			want:     []health.Check{check("shutdown", failed("shutting down")), check("database", ok), check("migrations", ok)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.NewHandler(tt.db, tt.schema, 50*time.Millisecond)
+			if tt.shutDown {
+				h.ShutDown()
+			}
+
+			code, report := serve(t, h, "/readyz")
+
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.want, report.Checks)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	keyTTL       = 24 * time.Hour
+)
+
+// replayedHeaders are the response headers stored with the body, so a retry
+// sees the same version, location and media type as the first response.
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag"}
+
+// Middleware makes mutating requests that carry an Idempotency-Key header
+// safe to retry. The first request with a key runs normally and its response
+// is stored; a retry with the same key and body gets the stored response
+// back, and a retry with a different body is rejected with 422. Keys are
+// scoped to the caller, so two callers never see each other's responses.
+//
+// Failed requests release their key so the client can try again.
+func Middleware(store postgres.IdempotencyStorer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(HeaderIdempotencyKey)
+
+			if key == "" || !isMutating(req.Method) {
+				return next(c)
+			}
+
+			if len(key) > maxKeyLength {
+				return apperrs.NewBadRequestError("Idempotency-Key must be at most 255 characters")
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return apperrs.NewBadRequestError("unable to read request body")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(req, body)
+			caller := principal(c)
+
+			record, reserved, err := store.ReserveIdempotencyKey(req.Context(), caller, key, hash, keyTTL)
+			if err != nil {
+				slog.ErrorContext(req.Context(), "Idempotency check failed", "err", err)
+				return apperrs.NewInternalServerError("Idempotency check failed")
+			}
+
+			if !reserved {
+				return replay(c, record, hash)
+			}
+
+			// The outcome is recorded even when the request ran out of time,
+			// or the key would stay reserved until it expires
+			ctx := context.WithoutCancel(req.Context())
+
+			res := c.Response()
+			recorder := &bodyRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+
+			err = next(c)
+
+			if err != nil || res.Status >= http.StatusInternalServerError {
+				if releaseErr := store.ReleaseIdempotencyKey(ctx, caller, key); releaseErr != nil {
+					slog.ErrorContext(ctx, "Release idempotency key failed", "err", releaseErr)
+				}
+				return err
+			}
+
+			if err := store.CompleteIdempotencyKey(ctx, caller, key, res.Status, storedHeaders(res.Header()), recorder.body.Bytes()); err != nil {
+				slog.ErrorContext(ctx, "Complete idempotency key failed", "err", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, record *postgres.IdempotencyRecord, hash string) error {
+	if record.RequestHash != hash {
+		return apperrs.NewUnprocessableEntity("Idempotency-Key was already used with a different request")
+	}
+
+	if record.ResponseStatus == 0 {
+		return apperrs.NewConflictError("a request with this Idempotency-Key is still in progress")
+	}
+
+	header := c.Response().Header()
+	for name, values := range record.ResponseHeaders {
+		for _, v := range values {
+			header.Add(name, v)
+		}
+	}
+	header.Set(HeaderReplayed, "true")
+
+	contentType := header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSONCharsetUTF8
+	}
+	return c.Blob(record.ResponseStatus, contentType, record.ResponseBody)
+}
+
+// storedHeaders picks the replayed headers out of header.
+func storedHeaders(header http.Header) http.Header {
+	stored := http.Header{}
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			stored[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return stored
+}
+
+// principal names the caller that owns the key: the token subject, or the
+// id of the API key. Unauthenticated requests share the empty principal.
+func principal(c echo.Context) string {
+	p, ok := auth.FromContext(c)
+	switch {
+	case !ok:
+		return ""
+	case p.KeyID != 0:
+		return "apikey:" + strconv.Itoa(p.KeyID)
+	}
+	return p.Subject
+}
+
+// requestHash fingerprints the method, target and body so a key cannot be
+// replayed against a different endpoint either.
+func requestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte("\n"))
+	h.Write([]byte(req.URL.RequestURI()))
+	h.Write([]byte("\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,214 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// StubStore keeps records by principal and key, written "principal/key".
+type StubStore struct {
+	Records  map[string]*postgres.IdempotencyRecord
+	Released []string
+}
+
+func NewStubStore() *StubStore {
+	return &StubStore{Records: map[string]*postgres.IdempotencyRecord{}}
+}
+
+func (s *StubStore) ReserveIdempotencyKey(ctx context.Context, principal string, key string, requestHash string, ttl time.Duration) (*postgres.IdempotencyRecord, bool, error) {
+	if record, ok := s.Records[principal+"/"+key]; ok {
+		return record, false, nil
+	}
+	s.Records[principal+"/"+key] = &postgres.IdempotencyRecord{Principal: principal, Key: key, RequestHash: requestHash}
+	return s.Records[principal+"/"+key], true, nil
+}
+
+func (s *StubStore) CompleteIdempotencyKey(ctx context.Context, principal string, key string, status int, header http.Header, body []byte) error {
+	s.Records[principal+"/"+key].ResponseStatus = status
+	s.Records[principal+"/"+key].ResponseHeaders = header
+	s.Records[principal+"/"+key].ResponseBody = body
+	return nil
+}
+
+func (s *StubStore) ReleaseIdempotencyKey(ctx context.Context, principal string, key string) error {
+	delete(s.Records, principal+"/"+key)
+	s.Released = append(s.Released, principal+"/"+key)
+	return nil
+}
+
+func newServer(store postgres.IdempotencyStorer, handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(apperrs.CustomErrorMiddleware)
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if subject := c.Request().Header.Get("X-Test-Subject"); subject != "" {
+				auth.SetPrincipal(c, &auth.Principal{Subject: subject})
+			}
+			return next(c)
+		}
+	})
+	e.Use(Middleware(store))
+	e.POST("/api/v1/wallets", handler)
+	return e
+}
+
+func doRequest(e *echo.Echo, key string, body string) *httptest.ResponseRecorder {
+	return doRequestAs(e, "", key, body)
+}
+
+func doRequestAs(e *echo.Echo, subject string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if subject != "" {
+		req.Header.Set("X-Test-Subject", subject)
+	}
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("given retry with same key and body should replay stored response", func(t *testing.T) {
+		store := NewStubStore()
+		calls := 0
+		e := newServer(store, func(c echo.Context) error {
+			calls++
+			return c.JSON(http.StatusCreated, map[string]int{"id": calls})
+		})
+
+		first := doRequest(e, "key-1", `{"wallet_name":"a"}`)
+		second := doRequest(e, "key-1", `{"wallet_name":"a"}`)
+
+		assert.Equal(t, 1, calls, "handler should run once")
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(HeaderReplayed))
+	})
+
+	t.Run("given retry should replay the stored headers", func(t *testing.T) {
+		store := NewStubStore()
+		e := newServer(store, func(c echo.Context) error {
+			c.Response().Header().Set("ETag", `"1"`)
+			c.Response().Header().Set(echo.HeaderLocation, "/api/v1/wallets/7")
+			c.Response().Header().Set("X-Other", "not stored")
+			return c.JSON(http.StatusCreated, map[string]int{"id": 7})
+		})
+
+		first := doRequest(e, "key-1", `{"wallet_name":"a"}`)
+		second := doRequest(e, "key-1", `{"wallet_name":"a"}`)
+
+		assert.Equal(t, "true", second.Header().Get(HeaderReplayed))
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+		assert.Equal(t, "/api/v1/wallets/7", second.Header().Get(echo.HeaderLocation))
+		assert.Equal(t, first.Header().Get(echo.HeaderContentType), second.Header().Get(echo.HeaderContentType))
+		assert.Empty(t, second.Header().Get("X-Other"))
+	})
+
+	t.Run("given same key with different body should return 422", func(t *testing.T) {
+		store := NewStubStore()
+		e := newServer(store, func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+		})
+
+		doRequest(e, "key-1", `{"wallet_name":"a"}`)
+		rec := doRequest(e, "key-1", `{"wallet_name":"b"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("given key still in progress should return 409", func(t *testing.T) {
+		store := NewStubStore()
+		e := newServer(store, func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(`{}`))
+		store.Records["/key-1"] = &postgres.IdempotencyRecord{Key: "key-1", RequestHash: requestHash(req, []byte(`{}`))}
+
+		rec := doRequest(e, "key-1", `{}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("given handler error should release key for retry", func(t *testing.T) {
+		store := NewStubStore()
+		e := newServer(store, func(c echo.Context) error {
+			return apperrs.NewInternalServerError("Create wallet failed")
+		})
+
+		rec := doRequest(e, "key-1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, []string{"/key-1"}, store.Released)
+		assert.Empty(t, store.Records)
+	})
+
+	t.Run("given two callers with the same key should run both requests", func(t *testing.T) {
+		store := NewStubStore()
+		calls := 0
+		e := newServer(store, func(c echo.Context) error {
+			calls++
+			return c.JSON(http.StatusCreated, map[string]int{"id": calls})
+		})
+
+		first := doRequestAs(e, "1", "key-1", `{"wallet_name":"a"}`)
+		second := doRequestAs(e, "2", "key-1", `{"wallet_name":"b"}`)
+
+		assert.Equal(t, 2, calls, "handler should run for each caller")
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.NotEqual(t, first.Body.String(), second.Body.String())
+		assert.Empty(t, second.Header().Get(HeaderReplayed))
+		assert.Contains(t, store.Records, "1/key-1")
+		assert.Contains(t, store.Records, "2/key-1")
+	})
+
+	t.Run("given no key should not touch store", func(t *testing.T) {
+		store := NewStubStore()
+		calls := 0
+		e := newServer(store, func(c echo.Context) error {
+			calls++
+			return c.JSON(http.StatusCreated, map[string]int{"id": calls})
+		})
+
+		doRequest(e, "", `{}`)
+		doRequest(e, "", `{}`)
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, store.Records)
+	})
+}
+
+func TestPrincipal(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      string
+	}{
+		{"given a token should use its subject", &auth.Principal{Subject: "7", UserID: 7}, "7"},
+		{"given an api key should use its id", &auth.Principal{Subject: "apikey:wk_abc", KeyID: 3}, "apikey:3"},
+		{"given no caller should be empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+			if tt.principal != nil {
+				auth.SetPrincipal(c, tt.principal)
+			}
+
+			assert.Equal(t, tt.want, principal(c))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/logging"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// records decodes the JSON lines in out.
+func records(t *testing.T, out *bytes.Buffer) []map[string]any {
+	var recs []map[string]any
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r)
+	}
+	return recs
+}
+
+func TestNew(t *testing.T) {
+	t.Run("given level warn should drop info records", func(t *testing.T) {
+		var out bytes.Buffer
+		logger, err := logging.New(&out, "warn", "text")
+		assert.NoError(t, err)
+
+		logger.Info("quiet")
+		logger.Warn("loud")
+
+		assert.NotContains(t, out.String(), "quiet")
+		assert.Contains(t, out.String(), "msg=loud")
+	})
+
+	t.Run("given unknown level should return error", func(t *testing.T) {
+		_, err := logging.New(&bytes.Buffer{}, "verbose", "json")
+
+		assert.EqualError(t, err, `logging: unknown level "verbose"`)
+	})
+
+	t.Run("given unknown format should return error", func(t *testing.T) {
+		_, err := logging.New(&bytes.Buffer{}, "info", "xml")
+
+		assert.EqualError(t, err, `logging: unknown format "xml", want json or text`)
+	})
+
+	t.Run("given context attrs should add them to the record", func(t *testing.T) {
+		var out bytes.Buffer
+		logger, _ := logging.New(&out, "info", "json")
+		ctx := logging.WithAttrs(context.Background(), slog.String("request_id", "abc"))
+
+		logger.InfoContext(ctx, "hello")
+
+		recs := records(t, &out)
+		if assert.Len(t, recs, 1) {
+			assert.Equal(t, "abc", recs[0]["request_id"])
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	var out bytes.Buffer
+	logger, _ := logging.New(&out, "info", "json")
+
+	e := echo.New()
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: func() string { return "req-1" }}))
+	e.Use(logging.Middleware(logger))
+	e.GET("/wallets/:id", func(c echo.Context) error {
+		auth.SetPrincipal(c, &auth.Principal{UserID: 7})
+		logger.InfoContext(c.Request().Context(), "inside")
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets/1", nil))
+
+	recs := records(t, &out)
+	if assert.Len(t, recs, 2) {
+		inside, request := recs[0], recs[1]
+		assert.Equal(t, "inside", inside["msg"])
+		assert.Equal(t, "req-1", inside["request_id"])
+		assert.Equal(t, "/wallets/:id", inside["route"])
+
+		assert.Equal(t, "request", request["msg"])
+		assert.Equal(t, "INFO", request["level"])
+		assert.Equal(t, "req-1", request["request_id"])
+		assert.Equal(t, "GET", request["method"])
+		assert.Equal(t, float64(http.StatusNoContent), request["status"])
+		assert.Equal(t, float64(7), request["user_id"])
+		assert.Contains(t, request, "latency_ms")
+	}
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware logs one record per request and gives the request context the
+// request id, method, route and trace id, so every record logged while
+// serving it carries them. It goes after middleware.RequestID and
+// tracing.Middleware and before apperrs.CustomErrorMiddleware, so the ids
+// are known and errors are already written when the status is logged.
+func Middleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			attrs := []slog.Attr{
+				slog.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
+				slog.String("method", req.Method),
+				slog.String("route", c.Path()),
+			}
+			if traceID := tracing.TraceID(req.Context()); traceID != "" {
+				attrs = append(attrs, slog.String("trace_id", traceID))
+			}
+			ctx := WithAttrs(req.Context(), attrs...)
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			status := c.Response().Status
+			attrs = []slog.Attr{
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			}
+			if p, ok := auth.FromContext(c); ok {
+				if p.KeyID != 0 {
+					attrs = append(attrs, slog.Int("key_id", p.KeyID))
+				} else {
+					attrs = append(attrs, slog.Int("user_id", p.UserID))
+				}
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(ctx, level, "request", attrs...)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"postgres key value", "host=db port=5432 user=root password=s3cret dbname=wallet", "host=db port=5432 user=root password=REDACTED dbname=wallet"},
+		{"postgres quoted key value", "user=root password='s3 cret' dbname=wallet", "user=root password=REDACTED dbname=wallet"},
+		{"url", "dial postgres://root:s3cret@db:5432/wallet failed", "dial postgres://root:REDACTED@db:5432/wallet failed"},
+		{"mysql", "wallet:s3cret@tcp(localhost:3306)/wallet", "wallet:REDACTED@tcp(localhost:3306)/wallet"},
+		{"no credentials", "connected to postgres", "connected to postgres"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, logging.RedactString(tt.in))
+		})
+	}
+}
+
+func TestNewRedacts(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info", "json")
+	assert.NoError(t, err)
+
+	logger.Info("connecting",
+		"db_password", "s3cret",
+		"api_key", "wk_s3cret",
+		"dsn", "anything",
+		"err", errors.New("open postgres://root:s3cret@db/wallet: refused"),
+	)
+
+	assert.NotContains(t, out.String(), "s3cret")
+	assert.Contains(t, out.String(), `"db_password":"REDACTED"`)
+	assert.Contains(t, out.String(), `"err":"open postgres://root:REDACTED@db/wallet: refused"`)
+}
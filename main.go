@@ -2,15 +2,32 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"os"
 	"net/http"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apikey"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/audit"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/health"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/idempotency"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/logging"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/memory"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/metrics"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/migrate"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/mysql"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 
 	_ "github.com/KKGo-Software-engineering/fun-exercise-api/docs"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -20,51 +37,352 @@ import (
 //	@version		1.0
 //	@description	Sophisticated Wallet API
 //	@host			localhost:1323
+//
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer JWT whose subject is the user id
+//
+//	@securityDefinitions.apikey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key issued by POST /api/v1/api-keys
 func main() {
 
-	//init database connection
-	p, err := postgres.New()
+	//"migrate up|down|status" and "config print" run instead of serving
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == "migrate" || args[0] == "config") {
+		if err := runCommand(args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//settings from the config file, the environment and flags
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	//structured logs; the standard log package writes through it too
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	//spans exported to an OTLP collector, if one is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Error("tracing setup failed", "err", err)
+		os.Exit(1)
+	}
+
+	//load keys used to verify bearer tokens
+	keys, err := auth.LoadKeys(cfg.Auth)
+	if err != nil {
+		logger.Error("JWT verification keys unavailable", "err", err)
+		os.Exit(1)
+	}
+
+	//init database connection, waiting for it to come up
+	p, err := postgres.New(cfg.DB, logger)
 	if err != nil {
-		panic(err)
+		logger.Error("database unavailable", "err", err)
+		os.Exit(1)
 	}
 
-	//add database to service
-	walletService := wallet.NewService(p)
+	//role grants for the permission checks
+	policy, err := auth.LoadPolicy(context.Background(), p)
+	if err != nil {
+		logger.Error("role grants unavailable", "err", err)
+		os.Exit(1)
+	}
+
+	//wallets can live in another backend; everything else stays in postgres
+	store, err := newWalletStore(p, cfg.Features)
+	if err != nil {
+		logger.Error("wallet store unavailable", "err", err)
+		os.Exit(1)
+	}
+
+	//pool stats of every database in use
+	if err := metrics.RegisterDB(p.Db, "postgres"); err != nil {
+		logger.Error("database metrics registration failed", "err", err)
+		os.Exit(1)
+	}
+	if m, ok := store.(*mysql.MySQL); ok {
+		if err := metrics.RegisterDB(m.Db, "mysql"); err != nil {
+			logger.Error("database metrics registration failed", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	//add database to service, timing every store call and tracing every
+	//service call
+	walletService := wallet.NewTracedService(wallet.NewService(metrics.NewStore(store), store, logger))
 
 	//add service to handler
-	handler := wallet.NewHandler(walletService)
+	handler := wallet.NewHandler(walletService, logger)
+	keyHandler := apikey.NewHandler(apikey.NewService(p))
+	auditHandler := audit.NewHandler(audit.NewService(p))
+	webhookHandler := webhook.NewHandler(webhook.NewService(p))
+
+	//probes: alive, and ready while the database is up and migrated
+	migrator, err := migrate.New(p.Db)
+	if err != nil {
+		logger.Error("migrations unavailable", "err", err)
+		os.Exit(1)
+	}
+	healthHandler := health.NewHandler(p.Db, migrator, cfg.Server.ReadyTimeout)
 	
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	//the audit log records the connection's address unless it is a trusted proxy
+	trustedProxies, err := cfg.Server.TrustedProxyRanges()
+	if err != nil {
+		logger.Error("invalid trusted proxies", "err", err)
+		os.Exit(1)
+	}
+	e.IPExtractor = wallet.ClientIPExtractor(trustedProxies)
+
+	//every request gets an id, echoed in X-Request-Id and kept in the audit log
+	e.Use(middleware.RequestID())
+
+	//a span per request, continuing the caller's trace
+	e.Use(tracing.Middleware())
+
+	//request counts and latency per route and status
+	e.Use(metrics.Middleware())
+
+	//one log record per request, and the request's fields on every other
+	e.Use(logging.Middleware(logger))
 
 	//set up error
 	e.Use(apperrs.CustomErrorMiddleware)
-	
+
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.GET("/metrics", metrics.Handler())
+	e.GET("/healthz", healthHandler.LiveHandler)
+	e.GET("/readyz", healthHandler.ReadyHandler)
+
+	//every api route needs an api key or a bearer token; idempotent replays
+	//come after authentication so a stored response is never served to
+	//anonymous callers. The deadline covers every database call on the way
+	api := e.Group("/api/v1", apperrs.Timeout(cfg.DB.Timeout), auth.APIKeys(p), auth.Middleware(keys), auth.Roles(policy, p), idempotency.Middleware(p))
+
+	//each route names the permission it needs; handlers check it against
+	//the wallet's owner
+	read := auth.Require(auth.PermWalletRead)
+	write := auth.Require(auth.PermWalletWrite)
+	transact := auth.Require(auth.PermWalletTransact)
+	adjust := auth.Require(auth.PermWalletAdjust)
+	remove := auth.Require(auth.PermWalletDelete)
+	freeze := auth.Require(auth.PermWalletFreeze)
+	manageKeys := auth.Require(auth.PermAPIKeyManage)
+	readAudit := auth.Require(auth.PermAuditRead)
+	manageWebhooks := auth.Require(auth.PermWebhookManage)
 	
-	e.GET("/api/v1/wallets", handler.WalletHandler)
+	api.GET("/wallets", handler.WalletHandler, read)
 	
-	e.POST("/api/v1/wallets", handler.CreateWalletHandler)
-	e.PUT("/api/v1/wallets/:id",handler.UpdateWalletHandler)
+	api.POST("/wallets", handler.CreateWalletHandler, write)
+	api.GET("/wallets/:id", handler.GetWalletHandler, read)
+	api.PUT("/wallets/:id",handler.UpdateWalletHandler, write)
+	api.DELETE("/wallets/:id", handler.DeleteWalletByIdHandler, remove)
+	api.GET("/wallets/:id/transactions", handler.WalletTransactionsHandler, read)
+	api.GET("/wallets/:id/reconciliation", handler.ReconcileWalletHandler, read)
+	api.POST("/wallets/:id/deposits", handler.DepositHandler, transact)
+	api.POST("/wallets/:id/withdrawals", handler.WithdrawalHandler, transact)
+	api.POST("/wallets/:id/adjustments", handler.AdjustmentHandler, adjust)
+	api.POST("/wallets/:id/freeze", handler.FreezeWalletHandler, freeze)
+	api.POST("/wallets/:id/unfreeze", handler.UnfreezeWalletHandler, freeze)
+	api.POST("/wallets/:id/close", handler.CloseWalletHandler, remove)
+
+	api.GET("/users/:id/wallets", handler.WalletByUserIdHandler, read)
+	api.DELETE("/users/:id/wallets", handler.DeleteWalletHandler, remove)
+
+	api.POST("/transfers", handler.TransferHandler, transact)
+	api.POST("/fx/quotes", handler.FXQuoteHandler, transact)
+
+	api.POST("/api-keys", keyHandler.CreateAPIKeyHandler, manageKeys)
+	api.GET("/api-keys", keyHandler.ListAPIKeysHandler, manageKeys)
+	api.DELETE("/api-keys/:id", keyHandler.RevokeAPIKeyHandler, manageKeys)
+
+	//the audit log and webhooks are only served with the postgres store,
+	//which records their events; config validation enforces it
+	if cfg.Features.Audit {
+		api.GET("/audit", auditHandler.ListEventsHandler, readAudit)
+	}
+
+	if cfg.Features.Webhooks {
+		api.POST("/webhooks", webhookHandler.CreateWebhookHandler, manageWebhooks)
+		api.GET("/webhooks", webhookHandler.ListWebhooksHandler, manageWebhooks)
+		api.DELETE("/webhooks/:id", webhookHandler.DeleteWebhookHandler, manageWebhooks)
+		api.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveriesHandler, manageWebhooks)
+		api.POST("/webhooks/:id/deliveries/:deliveryId/retry", webhookHandler.RetryDeliveryHandler, manageWebhooks)
+	}
 
-	e.GET("/api/v1/users/:id/wallets", handler.WalletByUserIdHandler)
-	e.POST("/api/v1/users/:id/wallets", handler.DeleteWalletHandler)
+	//deliver outbox events to webhook subscribers in the background
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	if cfg.Features.Webhooks && cfg.Features.WebhookDispatch {
+		go webhook.NewDispatcher(p).Run(dispatchCtx)
+	}
 	
 	//e.Logger.Fatal(e.Start(":1323"))
 
+	for _, s := range []*http.Server{e.Server, e.TLSServer} {
+		s.ReadTimeout = cfg.Server.ReadTimeout
+		s.WriteTimeout = cfg.Server.WriteTimeout
+		s.IdleTimeout = cfg.Server.IdleTimeout
+	}
+	addr := fmt.Sprintf(":%d", cfg.Server.Port)
+
 	//graceful shutdown
+	logger.Info("listening", "addr", addr, "tls", cfg.Server.TLSCertFile != "")
 	go func() {
-		if err := e.Start(":1323"); err != nil && err != http.ErrServerClosed { // Start server
+		var err error
+		if cfg.Server.TLSCertFile != "" {
+			err = e.StartTLS(addr, cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			err = e.Start(addr)
+		}
+		if err != nil && err != http.ErrServerClosed { // Start server
 			e.Logger.Fatal("shutting down the server")
 		}
 	}()
 
 	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 	<-shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+	//fail readiness first and keep serving while load balancers notice
+	healthHandler.ShutDown()
+	logger.Info("shutting down", "drain_delay", cfg.Server.DrainDelay.String())
+	time.Sleep(cfg.Server.DrainDelay)
+	stopDispatch()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("flush traces failed", "err", err)
+	}
+
+}
+
+// runCommand runs "migrate up|down|status" or "config print", each followed
+// by the same flags as the server.
+func runCommand(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %s migrate up|down|status | config print [flags]", os.Args[0])
+	}
+
+	command := args[0] + " " + args[1]
+	switch command {
+	case "migrate up", "migrate down", "migrate status", "config print":
+	default:
+		return fmt.Errorf("unknown command %q, want migrate up|down|status or config print", command)
+	}
 
+	cfg, err := config.Load(args[2:])
+	if err != nil {
+		return err
+	}
+
+	if command == "config print" {
+		return cfg.Print(os.Stdout)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return err
+	}
+	return runMigrate(cfg.DB, logger, args[1])
+}
+
+// runMigrate applies the embedded migrations to the Postgres database in
+// cfg.
+func runMigrate(cfg config.DB, logger *slog.Logger, command string) error {
+	p, err := postgres.New(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer p.Db.Close()
+
+	m, err := migrate.New(p.Db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := m.Down(ctx)
+		if reverted != nil {
+			fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+		}
+		if err == nil && reverted == nil {
+			fmt.Println("no applied migrations")
+		}
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+		return err
+	}
+	return nil
+}
+
+// walletStore is what the wallet service needs from a storage backend.
+type walletStore interface {
+	postgres.Storer
+	wallet.FXRateProvider
+}
+
+// newWalletStore opens the backend named by features.WalletStore: postgres,
+// mysql, or memory for demos.
+func newWalletStore(p *postgres.Postgres, features config.Features) (walletStore, error) {
+	switch features.WalletStore {
+	case "postgres":
+		return p, nil
+	case "mysql":
+		m, err := mysql.New(features.MySQLDSN)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "memory":
+		m := memory.New()
+		for _, r := range []postgres.FXRate{
+			{From: "USD", To: "THB", Rate: "36.5000", Spread: "0.005"},
+			{From: "THB", To: "USD", Rate: "0.0274", Spread: "0.005"},
+			{From: "EUR", To: "THB", Rate: "39.2000", Spread: "0.005"},
+			{From: "THB", To: "EUR", Rate: "0.0255", Spread: "0.005"},
+		} {
+			m.SetRate(r)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown wallet store %q", features.WalletStore)
 }
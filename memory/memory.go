@@ -0,0 +1,499 @@
+// Package memory keeps wallets, their ledger and FX quotes in process memory.
+// It implements postgres.Storer with the same semantics as the Postgres
+// store, for unit tests and local demos; everything is lost on exit. Like
+// the mysql store it records no audit or outbox events, so it only runs with
+// the audit log and webhooks off.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// Memory is safe for concurrent use. One lock guards all of it, which gives
+// every method the isolation of a database transaction.
+type Memory struct {
+	mu           sync.Mutex
+	wallets      map[int]postgres.Wallet
+	transactions []postgres.Transaction
+	quotes       map[string]postgres.FXQuote
+	rates        map[[2]string]postgres.FXRate
+	lastWalletID int
+	now          func() time.Time
+}
+
+func New() *Memory {
+	return &Memory{
+		wallets: map[int]postgres.Wallet{},
+		quotes:  map[string]postgres.FXQuote{},
+		rates:   map[[2]string]postgres.FXRate{},
+		now:     time.Now,
+	}
+}
+
+// SetRate adds or replaces the rate for r.From to r.To.
+func (m *Memory) SetRate(r postgres.FXRate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.UpdatedAt = m.now()
+	m.rates[[2]string{r.From, r.To}] = r
+}
+
+func (m *Memory) Rate(ctx context.Context, from string, to string) (*postgres.FXRate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rates[[2]string{from, to}]
+	if !ok {
+		return nil, postgres.ErrRateNotFound
+	}
+	return &r, nil
+}
+
+func (m *Memory) FindAll(ctx context.Context) ([]postgres.Wallet, error) {
+	return m.open(func(w postgres.Wallet) bool { return true }), nil
+}
+
+func (m *Memory) FindByWalletType(ctx context.Context, walletType string) ([]postgres.Wallet, error) {
+	return m.open(func(w postgres.Wallet) bool { return w.WalletType == walletType }), nil
+}
+
+func (m *Memory) FindByUserId(ctx context.Context, userId int) ([]postgres.Wallet, error) {
+	return m.open(func(w postgres.Wallet) bool { return w.UserID == userId }), nil
+}
+
+// open returns the wallets that are not closed and match, in id order.
+func (m *Memory) open(match func(postgres.Wallet) bool) []postgres.Wallet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var wallets []postgres.Wallet
+	for _, w := range m.sorted() {
+		if w.Status != postgres.WalletClosed && match(w) {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets
+}
+
+func (m *Memory) sorted() []postgres.Wallet {
+	wallets := make([]postgres.Wallet, 0, len(m.wallets))
+	for _, w := range m.wallets {
+		wallets = append(wallets, w)
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].ID < wallets[j].ID })
+	return wallets
+}
+
+func (m *Memory) FindByWalletId(ctx context.Context, walletID int) (*postgres.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, err := m.wallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (m *Memory) wallet(walletId int) (postgres.Wallet, error) {
+	w, ok := m.wallets[walletId]
+	if !ok {
+		return w, fmt.Errorf("wallet %d: %w", walletId, postgres.ErrNotFound)
+	}
+	return w, nil
+}
+
+func (m *Memory) Create(ctx context.Context, w *postgres.Wallet, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastWalletID++
+	w.ID = m.lastWalletID
+	w.Balance = w.Balance.WithCurrency(w.Currency)
+	w.Status = postgres.WalletActive
+	w.Version = 1
+	w.CreatedAt = m.now()
+	m.wallets[w.ID] = *w
+
+	m.record(postgres.Transaction{
+		WalletID:     w.ID,
+		Type:         postgres.TransactionCreate,
+		Amount:       w.Balance,
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+	})
+
+	return w, nil
+}
+
+// CountByCriteria counts wallets, closed ones included, whose fields equal
+// every non-zero field of criteria.
+func (m *Memory) CountByCriteria(ctx context.Context, criteria postgres.Wallet) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := reflect.ValueOf(criteria)
+	count := 0
+	for _, w := range m.wallets {
+		v := reflect.ValueOf(w)
+		match := true
+		for i := 0; i < c.NumField() && match; i++ {
+			if c.Field(i).IsZero() {
+				continue
+			}
+			// Balances compare by amount, the way NUMERIC columns do
+			if want, ok := c.Field(i).Interface().(money.Money); ok {
+				match = want.Cmp(w.Balance) == 0
+			} else {
+				match = reflect.DeepEqual(c.Field(i).Interface(), v.Field(i).Interface())
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *Memory) DeleteByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var open []postgres.Wallet
+	for _, w := range m.sorted() {
+		if w.UserID == id && w.Status != postgres.WalletClosed {
+			open = append(open, w)
+		}
+	}
+
+	for _, w := range open {
+		if err := requireStatus(w, postgres.WalletActive); err != nil {
+			return 0, err
+		}
+		if !w.Balance.IsZero() {
+			return 0, fmt.Errorf("wallet %d: %w", w.ID, postgres.ErrBalanceNotZero)
+		}
+	}
+
+	for _, w := range open {
+		m.setStatus(w, postgres.WalletClosed)
+	}
+
+	return int64(len(open)), nil
+}
+
+func (m *Memory) DeleteByWalletId(ctx context.Context, walletId int, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, err := m.wallet(walletId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStatus(w, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+	if !w.Balance.IsZero() {
+		return nil, fmt.Errorf("wallet %d: %w", walletId, postgres.ErrBalanceNotZero)
+	}
+
+	w = m.setStatus(w, postgres.WalletClosed)
+	return &w, nil
+}
+
+// UpdateByWalletId sets the non-zero fields of wallet other than its
+// balance. Like the Postgres store it reports 0 rows for a wallet that does
+// not exist and refuses wallets that are not active.
+func (m *Memory) UpdateByWalletId(ctx context.Context, walletId int, version int, wallet postgres.Wallet, meta postgres.AuditMeta) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, ok := m.wallets[walletId]
+	if !ok {
+		return 0, nil
+	}
+	if w.Version != version {
+		return 0, postgres.ErrVersionConflict
+	}
+	if err := requireStatus(w, postgres.WalletActive); err != nil {
+		return 0, err
+	}
+	if wallet.UserID != 0 {
+		w.UserID = wallet.UserID
+	}
+	if wallet.UserName != "" {
+		w.UserName = wallet.UserName
+	}
+	if wallet.WalletName != "" {
+		w.WalletName = wallet.WalletName
+	}
+	if wallet.WalletType != "" {
+		w.WalletType = wallet.WalletType
+	}
+	w.Version++
+	m.wallets[walletId] = w
+
+	return 1, nil
+}
+
+func (m *Memory) SetStatus(ctx context.Context, walletId int, version int, status string, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, err := m.wallet(walletId)
+	if err != nil {
+		return nil, err
+	}
+	if w.Version != version {
+		return nil, postgres.ErrVersionConflict
+	}
+
+	w = m.setStatus(w, status)
+	return &w, nil
+}
+
+func (m *Memory) setStatus(w postgres.Wallet, status string) postgres.Wallet {
+	w.Status = status
+	w.Version++
+	m.wallets[w.ID] = w
+	return w
+}
+
+// requireStatus fails unless w is in one of the given statuses.
+func requireStatus(w postgres.Wallet, statuses ...string) error {
+	for _, s := range statuses {
+		if w.Status == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("wallet %d is %s: %w", w.ID, w.Status, postgres.ErrWalletInactive)
+}
+
+func (m *Memory) Deposit(ctx context.Context, walletId int, amount money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(walletId, amount, nil, postgres.TransactionDeposit, "", postgres.WalletActive)
+}
+
+func (m *Memory) Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(walletId, amount.Neg(), &minBalance, postgres.TransactionWithdrawal, "", postgres.WalletActive)
+}
+
+func (m *Memory) Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(walletId, amount, nil, postgres.TransactionAdjustment, reason, postgres.WalletActive, postgres.WalletFrozen)
+}
+
+// changeBalance adds the signed amount to a wallet in one of statuses. A
+// minBalance is the floor the new balance may not go below.
+func (m *Memory) changeBalance(walletId int, amount money.Money, minBalance *money.Money, transactionType string, reason string, statuses ...string) (*postgres.BalanceChange, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, err := m.wallet(walletId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStatus(w, statuses...); err != nil {
+		return nil, err
+	}
+
+	w, err = m.apply(w, amount, minBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	t := m.record(postgres.Transaction{
+		WalletID:     w.ID,
+		Type:         transactionType,
+		Amount:       amount.WithCurrency(w.Currency),
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+		Reason:       reason,
+	})
+
+	return &postgres.BalanceChange{Wallet: w, Transaction: t}, nil
+}
+
+// apply stores w with amount added to its balance, failing with
+// ErrInsufficientFunds if that takes it below a non-nil minBalance.
+func (m *Memory) apply(w postgres.Wallet, amount money.Money, minBalance *money.Money) (postgres.Wallet, error) {
+	balance, err := w.Balance.Add(amount.WithCurrency(w.Currency))
+	if err != nil {
+		return w, err
+	}
+	if minBalance != nil && balance.Cmp(*minBalance) < 0 {
+		return w, postgres.ErrInsufficientFunds
+	}
+
+	w.Balance = balance
+	w.Version++
+	m.wallets[w.ID] = w
+	return w, nil
+}
+
+func (m *Memory) Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *postgres.FXQuote, meta postgres.AuditMeta) (*postgres.Transfer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from, err := m.wallet(fromWalletId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := m.wallet(toWalletId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireStatus(from, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+	if err := requireStatus(to, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+
+	credited := amount
+	var fxRate, fxSpread, fxQuoteID string
+	if quote == nil {
+		if from.Currency != to.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+	} else {
+		if from.Currency != quote.FromCurrency || to.Currency != quote.ToCurrency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		stored, ok := m.quotes[quote.ID]
+		if !ok || stored.UsedAt != nil || !stored.ExpiresAt.After(m.now()) {
+			return nil, postgres.ErrQuoteExpired
+		}
+		credited = quote.ConvertedAmount
+		fxRate, fxSpread, fxQuoteID = quote.Rate, quote.Spread, quote.ID
+	}
+
+	// Check both legs before touching either, so a failure changes nothing
+	if _, err := from.Balance.Add(amount.Neg().WithCurrency(from.Currency)); err != nil {
+		return nil, err
+	}
+	if _, err := to.Balance.Add(credited.WithCurrency(to.Currency)); err != nil {
+		return nil, err
+	}
+
+	from, err = m.apply(from, amount.Neg(), &minBalance)
+	if err != nil {
+		return nil, err
+	}
+	to, err = m.apply(to, credited, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote != nil {
+		stored := m.quotes[quote.ID]
+		usedAt := m.now()
+		stored.UsedAt = &usedAt
+		m.quotes[quote.ID] = stored
+	}
+
+	m.record(postgres.Transaction{
+		WalletID:             from.ID,
+		Type:                 postgres.TransactionTransferOut,
+		Amount:               amount.Neg().WithCurrency(from.Currency),
+		BalanceAfter:         from.Balance,
+		Currency:             from.Currency,
+		CounterpartyWalletID: to.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+	m.record(postgres.Transaction{
+		WalletID:             to.ID,
+		Type:                 postgres.TransactionTransferIn,
+		Amount:               credited.WithCurrency(to.Currency),
+		BalanceAfter:         to.Balance,
+		Currency:             to.Currency,
+		CounterpartyWalletID: from.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+
+	return &postgres.Transfer{FromWallet: from, ToWallet: to, Amount: amount, CreditedAmount: credited, Quote: quote}, nil
+}
+
+// record appends t to the ledger with the next id.
+func (m *Memory) record(t postgres.Transaction) postgres.Transaction {
+	t.ID = int64(len(m.transactions) + 1)
+	t.CreatedAt = m.now()
+	m.transactions = append(m.transactions, t)
+	return t
+}
+
+func (m *Memory) FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) ([]postgres.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var transactions []postgres.Transaction
+	for i := len(m.transactions) - 1; i >= 0 && len(transactions) < limit; i-- {
+		if m.transactions[i].WalletID != walletId {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		transactions = append(transactions, m.transactions[i])
+	}
+	return transactions, nil
+}
+
+// SumTransactionsByWalletId returns the balance derived from the ledger.
+func (m *Memory) SumTransactionsByWalletId(ctx context.Context, walletId int) (money.Money, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sum money.Money
+	for _, t := range m.transactions {
+		if t.WalletID != walletId {
+			continue
+		}
+		var err error
+		sum, err = sum.Add(t.Amount.WithCurrency(""))
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return sum, nil
+}
+
+func (m *Memory) CreateFXQuote(ctx context.Context, q *postgres.FXQuote) (*postgres.FXQuote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.quotes[q.ID]; ok {
+		return nil, fmt.Errorf("fx quote %s already exists", q.ID)
+	}
+	q.CreatedAt = m.now()
+	m.quotes[q.ID] = *q
+	return q, nil
+}
+
+func (m *Memory) FindFXQuote(ctx context.Context, id string) (*postgres.FXQuote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.quotes[id]
+	if !ok {
+		return nil, fmt.Errorf("fx quote %s: %w", id, postgres.ErrNotFound)
+	}
+	return &q, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// ListWallets applies filter the way the Postgres keyset query does: it
+// orders by the sort key and id and resumes strictly after filter.After.
+func (m *Memory) ListWallets(ctx context.Context, filter postgres.WalletFilter) ([]postgres.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var wallets []postgres.Wallet
+	for _, w := range m.wallets {
+		if matches(w, filter) {
+			wallets = append(wallets, w)
+		}
+	}
+
+	// less orders by the sort key, then by id
+	less := func(a, b postgres.Wallet) bool {
+		if c := compare(a, b, filter.Sort); c != 0 {
+			return c < 0
+		}
+		return a.ID < b.ID
+	}
+	if filter.Descending {
+		ascending := less
+		less = func(a, b postgres.Wallet) bool { return ascending(b, a) }
+	}
+	sort.Slice(wallets, func(i, j int) bool { return less(wallets[i], wallets[j]) })
+
+	if filter.After != nil {
+		after := postgres.Wallet{
+			ID:         filter.After.ID,
+			CreatedAt:  filter.After.CreatedAt,
+			Balance:    filter.After.Balance,
+			WalletName: filter.After.WalletName,
+		}
+		start := sort.Search(len(wallets), func(i int) bool { return less(after, wallets[i]) })
+		wallets = wallets[start:]
+	}
+
+	if len(wallets) > filter.Limit {
+		wallets = wallets[:filter.Limit]
+	}
+	if len(wallets) == 0 {
+		return nil, nil
+	}
+	return wallets, nil
+}
+
+func matches(w postgres.Wallet, filter postgres.WalletFilter) bool {
+	switch {
+	case filter.WalletType != "" && w.WalletType != filter.WalletType:
+		return false
+	case filter.Status != "" && w.Status != filter.Status:
+		return false
+	case filter.Status == "" && w.Status == postgres.WalletClosed:
+		return false
+	case filter.UserID != 0 && w.UserID != filter.UserID:
+		return false
+	case filter.MinBalance != nil && w.Balance.Cmp(*filter.MinBalance) < 0:
+		return false
+	case filter.MaxBalance != nil && w.Balance.Cmp(*filter.MaxBalance) > 0:
+		return false
+	case filter.CreatedFrom != nil && w.CreatedAt.Before(*filter.CreatedFrom):
+		return false
+	case filter.CreatedTo != nil && !w.CreatedAt.Before(*filter.CreatedTo):
+		return false
+	}
+	return true
+}
+
+// compare orders a and b by the sort key alone; unknown keys sort by
+// creation time like the Postgres store.
+func compare(a, b postgres.Wallet, sortKey string) int {
+	switch sortKey {
+	case postgres.SortBalance:
+		return a.Balance.Cmp(b.Balance)
+	case postgres.SortWalletName:
+		return strings.Compare(a.WalletName, b.WalletName)
+	}
+	return a.CreatedAt.Compare(b.CreatedAt)
+}
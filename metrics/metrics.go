@@ -0,0 +1,79 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP traffic,
+// database pool and query latency, and business counters. Everything is
+// registered on Registry and served by Handler.
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric of the service, along with the Go runtime and
+// process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to serve an HTTP request, by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wallet_store_duration_seconds",
+		Help:    "Time spent in a wallet store method, by method and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "result"})
+
+	// WalletsCreated counts wallets created.
+	WalletsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_wallets_created_total",
+		Help: "Wallets created.",
+	})
+
+	// TransfersCompleted counts transfers committed between two wallets.
+	TransfersCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "wallet_transfers_completed_total",
+		Help: "Transfers completed between two wallets.",
+	})
+
+	// ValidationFailures counts requests rejected by a validation rule. A
+	// request breaking several rules counts once for each.
+	ValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_validation_failures_total",
+		Help: "Validation rules broken by requests, by rule.",
+	}, []string{"rule"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		storeDuration,
+		WalletsCreated,
+		TransfersCompleted,
+		ValidationFailures,
+	)
+}
+
+// RegisterDB adds the connection pool stats of db, labelled with name, from
+// db.Stats on every scrape.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves Registry in the Prometheus text format.
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
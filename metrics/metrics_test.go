@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/memory"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/metrics"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// scrape returns the body served by metrics.Handler.
+func scrape(t *testing.T) string {
+	e := echo.New()
+	e.GET("/metrics", metrics.Handler())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(metrics.Middleware())
+	e.GET("/probe/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusAccepted)
+	})
+
+	for _, path := range []string{"/probe/1", "/probe/2", "/nowhere"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/probe/:id",status="202"} 2`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/probe/:id",status="202"} 2`)
+	assert.NotContains(t, body, `route="/probe/1"`)
+	assert.NotContains(t, body, `route="/nowhere"`)
+}
+
+func TestStore(t *testing.T) {
+	store := metrics.NewStore(memory.New())
+
+	_, err := store.Create(context.Background(), &postgres.Wallet{UserID: 1, WalletName: "Probe"}, postgres.AuditMeta{})
+	assert.NoError(t, err)
+	_, err = store.FindByWalletId(context.Background(), 999999)
+	assert.Error(t, err)
+
+	body := scrape(t)
+	assert.Contains(t, body, `wallet_store_duration_seconds_count{method="Create",result="ok"} 1`)
+	assert.Contains(t, body, `wallet_store_duration_seconds_count{method="FindByWalletId",result="error"} 1`)
+}
+
+func TestRegisterDB(t *testing.T) {
+	db, err := sql.Open("postgres", "host=localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	assert.NoError(t, metrics.RegisterDB(db, "probe"))
+
+	body := scrape(t)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="probe"} 0`)
+	assert.Contains(t, body, "wallet_transfers_completed_total 0")
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// unmatched labels requests that matched no route, so unknown paths do not
+// each get a series of their own.
+const unmatched = "unmatched"
+
+// Middleware counts and times every request by method, route pattern and
+// status. It goes before apperrs.CustomErrorMiddleware, so errors are
+// already written when the status is read.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = unmatched
+			}
+			labels := []string{c.Request().Method, route, strconv.Itoa(c.Response().Status)}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// Store times every call to a wallet store, whichever backend it is.
+type Store struct {
+	postgres.Storer
+}
+
+// NewStore returns s with its methods timed.
+func NewStore(s postgres.Storer) *Store {
+	return &Store{Storer: s}
+}
+
+// observe records a call to method that started at start and returned
+// *err. It is deferred, so it takes the error by pointer.
+func observe(method string, start time.Time, err *error) {
+	result := "ok"
+	if *err != nil {
+		result = "error"
+	}
+	storeDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (s *Store) FindAll(ctx context.Context) (result []postgres.Wallet, err error) {
+	defer observe("FindAll", time.Now(), &err)
+	return s.Storer.FindAll(ctx)
+}
+
+func (s *Store) ListWallets(ctx context.Context, filter postgres.WalletFilter) (result []postgres.Wallet, err error) {
+	defer observe("ListWallets", time.Now(), &err)
+	return s.Storer.ListWallets(ctx, filter)
+}
+
+func (s *Store) FindByWalletType(ctx context.Context, walletType string) (result []postgres.Wallet, err error) {
+	defer observe("FindByWalletType", time.Now(), &err)
+	return s.Storer.FindByWalletType(ctx, walletType)
+}
+
+func (s *Store) FindByWalletId(ctx context.Context, walletID int) (result *postgres.Wallet, err error) {
+	defer observe("FindByWalletId", time.Now(), &err)
+	return s.Storer.FindByWalletId(ctx, walletID)
+}
+
+func (s *Store) FindByUserId(ctx context.Context, userId int) (result []postgres.Wallet, err error) {
+	defer observe("FindByUserId", time.Now(), &err)
+	return s.Storer.FindByUserId(ctx, userId)
+}
+
+func (s *Store) Create(ctx context.Context, wallet *postgres.Wallet, meta postgres.AuditMeta) (result *postgres.Wallet, err error) {
+	defer observe("Create", time.Now(), &err)
+	return s.Storer.Create(ctx, wallet, meta)
+}
+
+func (s *Store) CountByCriteria(ctx context.Context, criteria postgres.Wallet) (result int, err error) {
+	defer observe("CountByCriteria", time.Now(), &err)
+	return s.Storer.CountByCriteria(ctx, criteria)
+}
+
+func (s *Store) DeleteByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (result int64, err error) {
+	defer observe("DeleteByUserId", time.Now(), &err)
+	return s.Storer.DeleteByUserId(ctx, userId, meta)
+}
+
+func (s *Store) DeleteByWalletId(ctx context.Context, walletId int, meta postgres.AuditMeta) (result *postgres.Wallet, err error) {
+	defer observe("DeleteByWalletId", time.Now(), &err)
+	return s.Storer.DeleteByWalletId(ctx, walletId, meta)
+}
+
+func (s *Store) UpdateByWalletId(ctx context.Context, walletId int, version int, wallet postgres.Wallet, meta postgres.AuditMeta) (result int64, err error) {
+	defer observe("UpdateByWalletId", time.Now(), &err)
+	return s.Storer.UpdateByWalletId(ctx, walletId, version, wallet, meta)
+}
+
+func (s *Store) SetStatus(ctx context.Context, walletId int, version int, status string, meta postgres.AuditMeta) (result *postgres.Wallet, err error) {
+	defer observe("SetStatus", time.Now(), &err)
+	return s.Storer.SetStatus(ctx, walletId, version, status, meta)
+}
+
+func (s *Store) Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *postgres.FXQuote, meta postgres.AuditMeta) (result *postgres.Transfer, err error) {
+	defer observe("Transfer", time.Now(), &err)
+	return s.Storer.Transfer(ctx, fromWalletId, toWalletId, amount, minBalance, quote, meta)
+}
+
+func (s *Store) Deposit(ctx context.Context, walletId int, amount money.Money, meta postgres.AuditMeta) (result *postgres.BalanceChange, err error) {
+	defer observe("Deposit", time.Now(), &err)
+	return s.Storer.Deposit(ctx, walletId, amount, meta)
+}
+
+func (s *Store) Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta postgres.AuditMeta) (result *postgres.BalanceChange, err error) {
+	defer observe("Withdraw", time.Now(), &err)
+	return s.Storer.Withdraw(ctx, walletId, amount, minBalance, meta)
+}
+
+func (s *Store) Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta postgres.AuditMeta) (result *postgres.BalanceChange, err error) {
+	defer observe("Adjust", time.Now(), &err)
+	return s.Storer.Adjust(ctx, walletId, amount, reason, meta)
+}
+
+func (s *Store) FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (result []postgres.Transaction, err error) {
+	defer observe("FindTransactionsByWalletId", time.Now(), &err)
+	return s.Storer.FindTransactionsByWalletId(ctx, walletId, limit, offset)
+}
+
+func (s *Store) SumTransactionsByWalletId(ctx context.Context, walletId int) (result money.Money, err error) {
+	defer observe("SumTransactionsByWalletId", time.Now(), &err)
+	return s.Storer.SumTransactionsByWalletId(ctx, walletId)
+}
+
+func (s *Store) CreateFXQuote(ctx context.Context, quote *postgres.FXQuote) (result *postgres.FXQuote, err error) {
+	defer observe("CreateFXQuote", time.Now(), &err)
+	return s.Storer.CreateFXQuote(ctx, quote)
+}
+
+func (s *Store) FindFXQuote(ctx context.Context, id string) (result *postgres.FXQuote, err error) {
+	defer observe("FindFXQuote", time.Now(), &err)
+	return s.Storer.FindFXQuote(ctx, id)
+}
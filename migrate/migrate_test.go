@@ -0,0 +1,127 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/migrate"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func file(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("given up and down files should pair them by version in order", func(t *testing.T) {
+		migrations, err := migrate.Load(fstest.MapFS{
+			"0002_ledger.up.sql":        file("CREATE TABLE ledger ();"),
+			"0002_ledger.down.sql":      file("DROP TABLE ledger;"),
+			"0001_user_wallet.up.sql":   file("CREATE TABLE user_wallet ();"),
+			"0001_user_wallet.down.sql": file("DROP TABLE user_wallet;"),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []migrate.Migration{
+			{Version: 1, Name: "user_wallet", Up: "CREATE TABLE user_wallet ();", Down: "DROP TABLE user_wallet;"},
+			{Version: 2, Name: "ledger", Up: "CREATE TABLE ledger ();", Down: "DROP TABLE ledger;"},
+		}, migrations)
+	})
+
+	t.Run("given an up file without a down file should return error", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{
+			"0001_user_wallet.up.sql": file("CREATE TABLE user_wallet ();"),
+		})
+
+		assert.EqualError(t, err, "migration 0001_user_wallet needs both an up and a down file")
+	})
+
+	t.Run("given a badly named file should return error", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{
+			"user_wallet.sql": file("CREATE TABLE user_wallet ();"),
+		})
+
+		assert.EqualError(t, err, "migration file user_wallet.sql is not named NNNN_name.up.sql or NNNN_name.down.sql")
+	})
+
+	t.Run("given one version under two names should return error", func(t *testing.T) {
+		_, err := migrate.Load(fstest.MapFS{
+			"0001_user_wallet.up.sql": file("CREATE TABLE user_wallet ();"),
+			"0001_wallet.down.sql":    file("DROP TABLE user_wallet;"),
+		})
+
+		assert.EqualError(t, err, "migration 0001 is named both user_wallet and wallet")
+	})
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := migrate.New(nil)
+
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, m.Migrations) {
+		first := m.Migrations[0]
+		assert.Equal(t, 1, first.Version)
+		assert.Equal(t, "user_wallet", first.Name)
+		assert.Contains(t, first.Up, "CREATE TYPE wallet_type")
+		assert.Contains(t, first.Up, "WHEN duplicate_object THEN NULL")
+		assert.Contains(t, first.Up, "CREATE TABLE IF NOT EXISTS user_wallet")
+		assert.Contains(t, first.Up, "balance DECIMAL(10, 2) NOT NULL")
+		assert.NotContains(t, first.Up, "currency", "later columns belong in their own migrations")
+	}
+	for i, migration := range m.Migrations {
+		assert.Equal(t, i+1, migration.Version, "versions should have no gaps")
+	}
+	assert.Equal(t, len(m.Migrations), m.Latest())
+}
+
+// TestUpConcurrently needs a database named by the connection string in
+// POSTGRES_TEST_DSN. Pending migrations are applied to it.
+func TestUpConcurrently(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replicas starting together must not apply a migration twice
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = m.Up(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	statuses, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, statuses, len(m.Migrations))
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt, "migration %04d_%s should be applied", s.Version, s.Name)
+	}
+
+	current, err := m.Current(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, m.Latest(), current)
+}
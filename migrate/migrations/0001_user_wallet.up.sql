@@ -0,0 +1,29 @@
+-- Wallets, the first table of the service, as init.sql created it. The type
+-- and the seed are skipped on a database that already has them, so one built
+-- by init.sql can be adopted by running every migration.
+DO $$ BEGIN
+	CREATE TYPE wallet_type AS ENUM ('Savings', 'Credit Card', 'Crypto Wallet');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS user_wallet (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL,
+	user_name VARCHAR(255) NOT NULL,
+	wallet_name VARCHAR(255) NOT NULL,
+	wallet_type wallet_type NOT NULL,
+	balance DECIMAL(10, 2) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance)
+SELECT * FROM (VALUES
+(1, 'John Doe', 'John Savings', 'Savings'::wallet_type, 1000.00),
+(1, 'John Doe', 'John Credit Card', 'Credit Card'::wallet_type, 500.00),
+(1, 'John Doe', 'John Crypto Wallet', 'Crypto Wallet'::wallet_type, 100.00),
+(2, 'Jane Doe', 'Jane Savings', 'Savings'::wallet_type, 2000.00),
+(2, 'Jane Doe', 'Jane Credit Card', 'Credit Card'::wallet_type, 1000.00),
+(2, 'Jane Doe', 'Jane Crypto Wallet', 'Crypto Wallet'::wallet_type, 200.00)
+) AS seed
+WHERE NOT EXISTS (SELECT 1 FROM user_wallet);
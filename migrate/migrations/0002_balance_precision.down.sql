@@ -0,0 +1 @@
+ALTER TABLE user_wallet ALTER COLUMN balance TYPE DECIMAL(10, 2);
@@ -0,0 +1,2 @@
+-- Balances are fixed-point with 8 decimal places, enough for crypto amounts.
+ALTER TABLE user_wallet ALTER COLUMN balance TYPE DECIMAL(18, 8);
@@ -0,0 +1,8 @@
+-- Every wallet holds one currency. Wallets created before currencies are
+-- taken to hold BTC when they are crypto wallets and THB otherwise.
+ALTER TABLE user_wallet ADD COLUMN IF NOT EXISTS currency VARCHAR(10);
+
+UPDATE user_wallet SET currency = CASE wallet_type WHEN 'Crypto Wallet' THEN 'BTC' ELSE 'THB' END
+WHERE currency IS NULL;
+
+ALTER TABLE user_wallet ALTER COLUMN currency SET NOT NULL;
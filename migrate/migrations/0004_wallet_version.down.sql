@@ -0,0 +1 @@
+ALTER TABLE user_wallet DROP COLUMN IF EXISTS version;
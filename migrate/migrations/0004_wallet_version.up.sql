@@ -0,0 +1,3 @@
+-- Optimistic locking: every update bumps version and is refused when the
+-- caller's If-Match names an older one.
+ALTER TABLE user_wallet ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_wallet_user_id_idx;
+DROP INDEX IF EXISTS user_wallet_wallet_name_idx;
+DROP INDEX IF EXISTS user_wallet_balance_idx;
+DROP INDEX IF EXISTS user_wallet_created_at_idx;
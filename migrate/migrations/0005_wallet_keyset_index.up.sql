@@ -0,0 +1,5 @@
+-- Keyset pagination resumes after (sort column, id), one index per sort key
+CREATE INDEX IF NOT EXISTS user_wallet_created_at_idx ON user_wallet (created_at, id);
+CREATE INDEX IF NOT EXISTS user_wallet_balance_idx ON user_wallet (balance, id);
+CREATE INDEX IF NOT EXISTS user_wallet_wallet_name_idx ON user_wallet (wallet_name, id);
+CREATE INDEX IF NOT EXISTS user_wallet_user_id_idx ON user_wallet (user_id);
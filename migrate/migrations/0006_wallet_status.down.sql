@@ -0,0 +1,2 @@
+ALTER TABLE user_wallet DROP COLUMN IF EXISTS status;
+DROP TYPE IF EXISTS wallet_status;
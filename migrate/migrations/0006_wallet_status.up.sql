@@ -0,0 +1,9 @@
+-- Wallets are never deleted. active -> frozen -> active and active -> closed
+-- are the only moves, checked by the service. Existing wallets are active.
+DO $$ BEGIN
+	CREATE TYPE wallet_status AS ENUM ('active', 'frozen', 'closed');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE user_wallet ADD COLUMN IF NOT EXISTS status wallet_status NOT NULL DEFAULT 'active';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wallet_transaction;
+DROP FUNCTION IF EXISTS wallet_transaction_immutable();
+DROP TYPE IF EXISTS transaction_type;
@@ -0,0 +1,37 @@
+-- Append-only ledger: one row per balance change. Rows are never updated or
+-- deleted, and outlive the wallet they belong to, so there is no foreign key.
+DO $$ BEGIN
+	CREATE TYPE transaction_type AS ENUM ('create', 'deposit', 'withdrawal', 'transfer_in', 'transfer_out', 'adjustment');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS wallet_transaction (
+	id BIGSERIAL PRIMARY KEY,
+	wallet_id INT NOT NULL,
+	type transaction_type NOT NULL,
+	amount DECIMAL(18, 8) NOT NULL,
+	balance_after DECIMAL(18, 8) NOT NULL,
+	currency VARCHAR(10) NOT NULL,
+	counterparty_wallet_id INT,
+	fx_rate NUMERIC(20, 10),
+	fx_spread NUMERIC(10, 6),
+	fx_quote_id VARCHAR(32),
+	reason TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS wallet_transaction_wallet_id_idx ON wallet_transaction (wallet_id, id);
+
+CREATE OR REPLACE FUNCTION wallet_transaction_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'wallet_transaction is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallet_transaction_append_only
+	BEFORE UPDATE OR DELETE ON wallet_transaction
+	FOR EACH ROW EXECUTE FUNCTION wallet_transaction_immutable();
+
+INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, currency)
+SELECT id, 'create', balance, balance, currency FROM user_wallet;
@@ -0,0 +1,9 @@
+-- Idempotency-Key records: the hash of the first request seen with a key and,
+-- once it completed, the response to replay for retries of that request.
+CREATE TABLE IF NOT EXISTS idempotency_key (
+	key VARCHAR(255) PRIMARY KEY,
+	request_hash CHAR(64) NOT NULL,
+	response_status INT,
+	response_body BYTEA,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
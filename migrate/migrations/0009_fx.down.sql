@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fx_quote;
+DROP TABLE IF EXISTS fx_rate;
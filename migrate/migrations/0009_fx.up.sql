@@ -0,0 +1,31 @@
+-- FX rates per direction: rate is the price of one unit of from_currency in
+-- to_currency and spread the fraction kept on top of it. A rate feed keeps
+-- these rows current.
+CREATE TABLE IF NOT EXISTS fx_rate (
+	from_currency VARCHAR(10) NOT NULL,
+	to_currency VARCHAR(10) NOT NULL,
+	rate NUMERIC(20, 10) NOT NULL,
+	spread NUMERIC(10, 6) NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (from_currency, to_currency)
+);
+
+INSERT INTO fx_rate (from_currency, to_currency, rate, spread) VALUES
+('USD', 'THB', 36.5000, 0.005),
+('THB', 'USD', 0.0274, 0.005),
+('EUR', 'THB', 39.2000, 0.005),
+('THB', 'EUR', 0.0255, 0.005);
+
+-- FX quotes fix a rate for one transfer until expires_at and are spent by it.
+CREATE TABLE IF NOT EXISTS fx_quote (
+	id VARCHAR(32) PRIMARY KEY,
+	from_currency VARCHAR(10) NOT NULL,
+	to_currency VARCHAR(10) NOT NULL,
+	amount DECIMAL(18, 8) NOT NULL,
+	converted_amount DECIMAL(18, 8) NOT NULL,
+	rate NUMERIC(20, 10) NOT NULL,
+	spread NUMERIC(10, 6) NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,30 @@
+-- Role-based access: role_permission lists what each role may do and
+-- user_role who holds it. Every caller implicitly has the owner role, which
+-- only applies to wallets of their own user id.
+CREATE TABLE IF NOT EXISTS role_permission (
+	role VARCHAR(32) NOT NULL,
+	permission VARCHAR(64) NOT NULL,
+	PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_role (
+	user_id INT NOT NULL,
+	role VARCHAR(32) NOT NULL,
+	PRIMARY KEY (user_id, role)
+);
+
+INSERT INTO role_permission (role, permission) VALUES
+('owner', 'wallet:read'),
+('owner', 'wallet:write'),
+('owner', 'wallet:transact'),
+('owner', 'wallet:delete'),
+('readonly', 'wallet:read'),
+('operator', 'wallet:read'),
+('operator', 'wallet:adjust'),
+('operator', 'wallet:freeze'),
+('admin', 'wallet:read'),
+('admin', 'wallet:write'),
+('admin', 'wallet:transact'),
+('admin', 'wallet:adjust'),
+('admin', 'wallet:delete'),
+('admin', 'wallet:freeze');
@@ -0,0 +1,3 @@
+DELETE FROM role_permission WHERE permission = 'apikey:manage';
+
+DROP TABLE IF EXISTS api_key;
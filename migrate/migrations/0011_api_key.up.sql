@@ -0,0 +1,17 @@
+-- API keys for machine clients. Only the SHA-256 of the key is stored; the
+-- prefix is its public part, used to look the key up. scopes holds the
+-- permissions of the key separated by spaces, granted on every wallet.
+CREATE TABLE IF NOT EXISTS api_key (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	prefix VARCHAR(16) NOT NULL UNIQUE,
+	secret_hash CHAR(64) NOT NULL,
+	scopes TEXT NOT NULL,
+	expires_at TIMESTAMPTZ,
+	last_used_at TIMESTAMPTZ,
+	revoked_at TIMESTAMPTZ,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO role_permission (role, permission) VALUES
+('admin', 'apikey:manage');
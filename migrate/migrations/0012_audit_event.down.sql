@@ -0,0 +1,4 @@
+DELETE FROM role_permission WHERE permission = 'audit:read';
+
+DROP TABLE IF EXISTS audit_event;
+DROP FUNCTION IF EXISTS audit_event_immutable();
@@ -0,0 +1,31 @@
+-- Audit trail of wallet changes, written in the same transaction as the
+-- change. before and after are JSON snapshots of the wallet row; before is
+-- NULL on create.
+CREATE TABLE IF NOT EXISTS audit_event (
+	id BIGSERIAL PRIMARY KEY,
+	actor VARCHAR(255) NOT NULL,
+	action VARCHAR(64) NOT NULL,
+	wallet_id INT NOT NULL,
+	request_id VARCHAR(255),
+	client_ip VARCHAR(64),
+	before JSONB,
+	after JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_event_wallet_id_idx ON audit_event (wallet_id, id);
+CREATE INDEX IF NOT EXISTS audit_event_actor_idx ON audit_event (actor, id);
+
+CREATE OR REPLACE FUNCTION audit_event_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_append_only
+	BEFORE UPDATE OR DELETE ON audit_event
+	FOR EACH ROW EXECUTE FUNCTION audit_event_immutable();
+
+INSERT INTO role_permission (role, permission) VALUES
+('admin', 'audit:read'),
+('readonly', 'audit:read');
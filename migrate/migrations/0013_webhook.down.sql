@@ -0,0 +1,6 @@
+DELETE FROM role_permission WHERE permission = 'webhook:manage';
+
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TYPE IF EXISTS delivery_status;
+DROP TABLE IF EXISTS webhook_subscription;
+DROP TABLE IF EXISTS outbox_event;
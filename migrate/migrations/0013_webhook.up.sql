@@ -0,0 +1,45 @@
+-- Transactional outbox: every wallet change also writes an event here, in
+-- the same transaction. Deliveries fan the event out to the subscriptions
+-- that were active when it was written; the dispatcher works through them.
+CREATE TABLE IF NOT EXISTS outbox_event (
+	id BIGSERIAL PRIMARY KEY,
+	event_type VARCHAR(64) NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- event_types holds the subscribed types separated by spaces; empty means
+-- every type. secret signs the deliveries and has to be kept readable.
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+	id SERIAL PRIMARY KEY,
+	url TEXT NOT NULL,
+	secret VARCHAR(128) NOT NULL,
+	event_types TEXT NOT NULL DEFAULT '',
+	active BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DO $$ BEGIN
+	CREATE TYPE delivery_status AS ENUM ('pending', 'delivered', 'dead');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+	id BIGSERIAL PRIMARY KEY,
+	event_id BIGINT NOT NULL REFERENCES outbox_event (id),
+	subscription_id INT NOT NULL REFERENCES webhook_subscription (id),
+	status delivery_status NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_error TEXT,
+	delivered_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (event_id, subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_delivery_subscription_idx ON webhook_delivery (subscription_id, id);
+
+INSERT INTO role_permission (role, permission) VALUES
+('admin', 'webhook:manage');
@@ -0,0 +1,7 @@
+-- Two callers may hold the same key, which the old primary key cannot; the
+-- rows only guard retries and are dropped.
+DELETE FROM idempotency_key;
+
+ALTER TABLE idempotency_key DROP CONSTRAINT IF EXISTS idempotency_key_pkey;
+ALTER TABLE idempotency_key DROP COLUMN IF EXISTS principal;
+ALTER TABLE idempotency_key ADD PRIMARY KEY (key);
@@ -0,0 +1,7 @@
+-- Idempotency keys are chosen by clients, so each caller gets their own:
+-- principal is the token subject or "apikey:<id>". Rows from before this
+-- have no caller and only live until they expire.
+ALTER TABLE idempotency_key ADD COLUMN IF NOT EXISTS principal VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_key DROP CONSTRAINT IF EXISTS idempotency_key_pkey;
+ALTER TABLE idempotency_key ADD PRIMARY KEY (principal, key);
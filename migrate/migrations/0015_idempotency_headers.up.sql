@@ -0,0 +1,3 @@
+-- Replays carry the ETag, Location and Content-Type of the first response
+-- too, as a JSON object of header name to values.
+ALTER TABLE idempotency_key ADD COLUMN IF NOT EXISTS response_headers JSONB;
@@ -0,0 +1,56 @@
+package money
+
+import "math/big"
+
+// Currency is an ISO 4217 code, or a ticker for crypto currencies, with the
+// number of decimal places of its minor unit.
+type Currency struct {
+	Code     string
+	Exponent int
+	Crypto   bool
+}
+
+// Crypto exponents are capped at Scale even when the chain itself is finer
+// (ETH has 18 decimals); amounts below that cannot be stored.
+var currencies = map[string]Currency{
+	"THB":  {Code: "THB", Exponent: 2},
+	"USD":  {Code: "USD", Exponent: 2},
+	"EUR":  {Code: "EUR", Exponent: 2},
+	"GBP":  {Code: "GBP", Exponent: 2},
+	"SGD":  {Code: "SGD", Exponent: 2},
+	"JPY":  {Code: "JPY", Exponent: 0},
+	"KRW":  {Code: "KRW", Exponent: 0},
+	"BHD":  {Code: "BHD", Exponent: 3},
+	"KWD":  {Code: "KWD", Exponent: 3},
+	"BTC":  {Code: "BTC", Exponent: 8, Crypto: true},
+	"ETH":  {Code: "ETH", Exponent: 8, Crypto: true},
+	"USDT": {Code: "USDT", Exponent: 6, Crypto: true},
+}
+
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// Fits reports whether m can be expressed in whole minor units of c.
+func (c Currency) Fits(m Money) bool {
+	return m.DecimalPlaces() <= c.Exponent
+}
+
+// Convert multiplies m by rate and expresses the result in c, truncated
+// toward zero to c's minor unit so a conversion never pays out more than
+// the rate allows.
+func (m Money) Convert(rate *big.Rat, c Currency) (Money, error) {
+	r := new(big.Rat).SetInt64(m.amount)
+	r.Mul(r, rate)
+
+	minor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Scale-c.Exponent)), nil)
+	q := new(big.Int).Quo(r.Num(), r.Denom())
+	q.Quo(q, minor)
+	q.Mul(q, minor)
+
+	if !q.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{amount: q.Int64(), currency: c.Code}, nil
+}
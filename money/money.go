@@ -0,0 +1,238 @@
+// Package money provides an exact fixed-point amount for balances, so that
+// 0.1+0.2 style float errors cannot appear in a wallet.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places Money keeps. Eight places hold the
+// minor unit of every fiat currency as well as satoshi-sized crypto amounts,
+// and match the DECIMAL(18, 8) balance columns.
+const Scale = 8
+
+const unit = 100_000_000
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOutOfRange       = errors.New("amount out of range")
+	ErrPrecision        = fmt.Errorf("amount has more than %d decimal places", Scale)
+	ErrInvalid          = errors.New("invalid amount")
+)
+
+// Money is an amount counted in units of 10^-Scale together with its
+// currency. The zero value is zero with no currency.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// FromInt returns a whole amount with no currency.
+func FromInt(whole int64) Money {
+	return Money{amount: whole * unit}
+}
+
+// FromUnits returns the amount counted in units of 10^-Scale.
+func FromUnits(units int64, currency string) Money {
+	return Money{amount: units, currency: currency}
+}
+
+// Parse reads a decimal such as "-12.50" or "1e3". It never rounds: an
+// amount finer than Scale decimal places is an error.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/_") {
+		return Money{}, ErrInvalid
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, ErrInvalid
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if !r.IsInt() {
+		return Money{}, ErrPrecision
+	}
+
+	n := r.Num()
+	if !n.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+
+	return Money{amount: n.Int64()}, nil
+}
+
+// MustParse is Parse for constants and tests; it panics on error.
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: parse %q: %v", s, err))
+	}
+	return m
+}
+
+func (m Money) Units() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) WithCurrency(currency string) Money {
+	m.currency = currency
+	return m
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.amount + o.amount
+	if (o.amount > 0 && sum < m.amount) || (o.amount < 0 && sum > m.amount) {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.amount == math.MinInt64 {
+		return Money{}, ErrOutOfRange
+	}
+	return m.Add(o.Neg())
+}
+
+func (m Money) Neg() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Cmp compares the amounts only, returning -1, 0 or +1.
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.amount < o.amount:
+		return -1
+	case m.amount > o.amount:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) Sign() int {
+	return m.Cmp(Money{})
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// DecimalPlaces is the number of significant digits after the point,
+// so 1.50 has one place.
+func (m Money) DecimalPlaces() int {
+	frac := m.amount % unit
+	if frac == 0 {
+		return 0
+	}
+	places := Scale
+	for frac%10 == 0 {
+		frac /= 10
+		places--
+	}
+	return places
+}
+
+// String formats the amount with the decimal places of its currency's
+// minor unit, e.g. "100.00" THB, "1000" JPY or "0.00100000" BTC. Without a
+// known currency it keeps at least two places. Significant digits are never
+// dropped.
+func (m Money) String() string {
+	abs := uint64(m.amount)
+	sign := ""
+	if m.amount < 0 {
+		abs = uint64(-(m.amount + 1)) + 1
+		sign = "-"
+	}
+
+	places := 2
+	if c, ok := LookupCurrency(m.currency); ok {
+		places = c.Exponent
+	}
+
+	frac := fmt.Sprintf("%08d", abs%unit)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < places {
+		frac += "0"
+	}
+
+	whole := sign + strconv.FormatUint(abs/unit, 10)
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// MarshalJSON writes the amount as a JSON number built from the exact
+// decimal text, never going through float64.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a JSON string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("money: %q: %w", s, err)
+	}
+
+	m.amount = parsed.amount
+	return nil
+}
+
+// Scan reads a NUMERIC column. Drivers return it as text, which is parsed
+// exactly.
+func (m *Money) Scan(src interface{}) error {
+	var parsed Money
+	var err error
+
+	switch v := src.(type) {
+	case []byte:
+		parsed, err = Parse(string(v))
+	case string:
+		parsed, err = Parse(v)
+	case int64:
+		if v > math.MaxInt64/unit || v < math.MinInt64/unit {
+			return ErrOutOfRange
+		}
+		parsed = FromInt(v)
+	case float64:
+		parsed, err = Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.amount = parsed.amount
+	return nil
+}
+
+// Value writes the amount as decimal text for a NUMERIC parameter.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,151 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr error
+	}{
+		{name: "whole number", input: "100", want: 100 * unit},
+		{name: "two decimals", input: "100.25", want: 10025000000},
+		{name: "eight decimals", input: "0.00000001", want: 1},
+		{name: "negative", input: "-12.5", want: -1250000000},
+		{name: "exponent", input: "1e3", want: 1000 * unit},
+		{name: "nine decimals", input: "0.000000001", wantErr: ErrPrecision},
+		{name: "out of range", input: "100000000000000", wantErr: ErrOutOfRange},
+		{name: "not a number", input: "abc", wantErr: ErrInvalid},
+		{name: "fraction", input: "1/2", wantErr: ErrInvalid},
+		{name: "empty", input: "", wantErr: ErrInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got.Units())
+		})
+	}
+}
+
+func TestAddIsExact(t *testing.T) {
+	sum, err := MustParse("0.1").Add(MustParse("0.2"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sum.Cmp(MustParse("0.3")))
+	assert.Equal(t, "0.30", sum.String())
+}
+
+func TestAddCurrencyMismatch(t *testing.T) {
+	_, err := MustParse("1").WithCurrency("THB").Add(MustParse("1").WithCurrency("USD"))
+
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestSub(t *testing.T) {
+	diff, err := MustParse("10").Sub(MustParse("10.01"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.01", diff.String())
+	assert.Equal(t, -1, diff.Sign())
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "0.00", Money{}.String())
+	assert.Equal(t, "100.50", MustParse("100.5").String())
+	assert.Equal(t, "0.12345678", MustParse("0.12345678").String())
+	assert.Equal(t, "-92233720368.54775808", Money{amount: -9223372036854775808}.String())
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	assert.Equal(t, 0, MustParse("100.00").DecimalPlaces())
+	assert.Equal(t, 1, MustParse("100.50").DecimalPlaces())
+	assert.Equal(t, 8, MustParse("0.00000001").DecimalPlaces())
+}
+
+func TestJSON(t *testing.T) {
+	var got struct {
+		Number Money `json:"number"`
+		Text   Money `json:"text"`
+	}
+
+	err := json.Unmarshal([]byte(`{"number": 0.30000000, "text": "1234567890.12345678"}`), &got)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("0.3"), got.Number)
+	assert.Equal(t, MustParse("1234567890.12345678"), got.Text)
+
+	out, err := json.Marshal(got)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"number": 0.30, "text": 1234567890.12345678}`, string(out))
+}
+
+func TestJSONRejectsExcessPrecision(t *testing.T) {
+	var m Money
+
+	err := json.Unmarshal([]byte(`0.123456789`), &m)
+
+	assert.ErrorIs(t, err, ErrPrecision)
+}
+
+func TestScanAndValue(t *testing.T) {
+	var m Money
+
+	assert.NoError(t, m.Scan([]byte("1000.10000000")))
+	assert.Equal(t, MustParse("1000.1"), m)
+
+	v, err := m.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1000.10", v)
+
+	assert.Error(t, m.Scan(nil))
+}
+
+func TestStringUsesCurrencyExponent(t *testing.T) {
+	assert.Equal(t, "1000", MustParse("1000").WithCurrency("JPY").String())
+	assert.Equal(t, "1.250", MustParse("1.25").WithCurrency("BHD").String())
+	assert.Equal(t, "0.00100000", MustParse("0.001").WithCurrency("BTC").String())
+	assert.Equal(t, "100.00", MustParse("100").WithCurrency("THB").String())
+	assert.Equal(t, "1000.5", MustParse("1000.5").WithCurrency("JPY").String(), "never drops significant digits")
+}
+
+func TestCurrencyFits(t *testing.T) {
+	jpy, _ := LookupCurrency("JPY")
+	bhd, _ := LookupCurrency("BHD")
+	btc, _ := LookupCurrency("BTC")
+
+	assert.True(t, jpy.Fits(MustParse("100")))
+	assert.False(t, jpy.Fits(MustParse("100.5")))
+	assert.True(t, bhd.Fits(MustParse("1.125")))
+	assert.False(t, bhd.Fits(MustParse("1.1255")))
+	assert.True(t, btc.Fits(MustParse("0.00000001")))
+
+	_, ok := LookupCurrency("XXX")
+	assert.False(t, ok)
+}
+
+func TestConvertTruncatesToMinorUnit(t *testing.T) {
+	thb, _ := LookupCurrency("THB")
+	jpy, _ := LookupCurrency("JPY")
+	rate, _ := new(big.Rat).SetString("35.123456")
+
+	got, err := MustParse("10.00").WithCurrency("USD").Convert(rate, thb)
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("351.23").WithCurrency("THB"), got)
+
+	got, err = MustParse("-10.00").Convert(rate, jpy)
+	assert.NoError(t, err)
+	assert.Equal(t, MustParse("-351").WithCurrency("JPY"), got, "truncates toward zero")
+}
@@ -0,0 +1,82 @@
+-- Schema for the MySQL wallet store (WALLET_STORE=mysql). It holds the same
+-- wallets, ledger and FX tables as the Postgres migrations; everything else
+-- stays in Postgres. Times are kept in UTC.
+
+CREATE TABLE IF NOT EXISTS user_wallet (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	user_name VARCHAR(255) NOT NULL,
+	wallet_name VARCHAR(255) NOT NULL,
+	wallet_type ENUM('Savings', 'Credit Card', 'Crypto Wallet') NOT NULL,
+	balance DECIMAL(18, 8) NOT NULL,
+	currency VARCHAR(10) NOT NULL,
+	status ENUM('active', 'frozen', 'closed') NOT NULL DEFAULT 'active',
+	version INT NOT NULL DEFAULT 1,
+	created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX user_wallet_created_at_idx (created_at, id),
+	INDEX user_wallet_balance_idx (balance, id),
+	INDEX user_wallet_wallet_name_idx (wallet_name, id),
+	INDEX user_wallet_user_id_idx (user_id)
+);
+
+INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance, currency) VALUES
+(1, 'John Doe', 'John Savings', 'Savings', 1000.00, 'THB'),
+(1, 'John Doe', 'John Credit Card', 'Credit Card', 500.00, 'THB'),
+(1, 'John Doe', 'John Crypto Wallet', 'Crypto Wallet', 100.00, 'BTC'),
+(2, 'Jane Doe', 'Jane Savings', 'Savings', 2000.00, 'USD'),
+(2, 'Jane Doe', 'Jane Credit Card', 'Credit Card', 1000.00, 'USD'),
+(2, 'Jane Doe', 'Jane Crypto Wallet', 'Crypto Wallet', 200.00, 'BTC');
+
+-- Append-only ledger, see migrate/migrations/0002_wallet_transaction.up.sql
+CREATE TABLE IF NOT EXISTS wallet_transaction (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	wallet_id INT NOT NULL,
+	type ENUM('create', 'deposit', 'withdrawal', 'transfer_in', 'transfer_out', 'adjustment') NOT NULL,
+	amount DECIMAL(18, 8) NOT NULL,
+	balance_after DECIMAL(18, 8) NOT NULL,
+	currency VARCHAR(10) NOT NULL,
+	counterparty_wallet_id INT,
+	fx_rate DECIMAL(20, 10),
+	fx_spread DECIMAL(10, 6),
+	fx_quote_id VARCHAR(32),
+	reason TEXT,
+	created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	INDEX wallet_transaction_wallet_id_idx (wallet_id, id)
+);
+
+CREATE TRIGGER wallet_transaction_no_update BEFORE UPDATE ON wallet_transaction
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'wallet_transaction is append-only';
+
+CREATE TRIGGER wallet_transaction_no_delete BEFORE DELETE ON wallet_transaction
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'wallet_transaction is append-only';
+
+INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, currency)
+SELECT id, 'create', balance, balance, currency FROM user_wallet;
+
+CREATE TABLE IF NOT EXISTS fx_rate (
+	from_currency VARCHAR(10) NOT NULL,
+	to_currency VARCHAR(10) NOT NULL,
+	rate DECIMAL(20, 10) NOT NULL,
+	spread DECIMAL(10, 6) NOT NULL DEFAULT 0,
+	updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+	PRIMARY KEY (from_currency, to_currency)
+);
+
+INSERT INTO fx_rate (from_currency, to_currency, rate, spread) VALUES
+('USD', 'THB', 36.5000, 0.005),
+('THB', 'USD', 0.0274, 0.005),
+('EUR', 'THB', 39.2000, 0.005),
+('THB', 'EUR', 0.0255, 0.005);
+
+CREATE TABLE IF NOT EXISTS fx_quote (
+	id VARCHAR(32) PRIMARY KEY,
+	from_currency VARCHAR(10) NOT NULL,
+	to_currency VARCHAR(10) NOT NULL,
+	amount DECIMAL(18, 8) NOT NULL,
+	converted_amount DECIMAL(18, 8) NOT NULL,
+	rate DECIMAL(20, 10) NOT NULL,
+	spread DECIMAL(10, 6) NOT NULL,
+	expires_at DATETIME(6) NOT NULL,
+	used_at DATETIME(6),
+	created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
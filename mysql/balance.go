@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+func (m *MySQL) Deposit(ctx context.Context, walletId int, amount money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(ctx, walletId, postgres.TransactionDeposit, amount, "", func(tx *sql.Tx, w postgres.Wallet) (postgres.Wallet, error) {
+		return credit(ctx, tx, w, amount)
+	}, postgres.WalletActive)
+}
+
+// Withdraw debits amount as long as the balance stays at or above minBalance.
+func (m *MySQL) Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(ctx, walletId, postgres.TransactionWithdrawal, amount.Neg(), "", func(tx *sql.Tx, w postgres.Wallet) (postgres.Wallet, error) {
+		return debit(ctx, tx, w, amount, minBalance)
+	}, postgres.WalletActive)
+}
+
+// Adjust books a signed correction; it has no floor and still applies to
+// frozen wallets.
+func (m *MySQL) Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+	return m.changeBalance(ctx, walletId, postgres.TransactionAdjustment, amount, reason, func(tx *sql.Tx, w postgres.Wallet) (postgres.Wallet, error) {
+		return credit(ctx, tx, w, amount)
+	}, postgres.WalletActive, postgres.WalletFrozen)
+}
+
+// changeBalance locks a wallet in one of statuses, applies change to it and
+// books the signed amount in the ledger.
+func (m *MySQL) changeBalance(ctx context.Context, walletId int, transactionType string, amount money.Money, reason string, change func(*sql.Tx, postgres.Wallet) (postgres.Wallet, error), statuses ...string) (*postgres.BalanceChange, error) {
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStatus(w, statuses...); err != nil {
+		return nil, err
+	}
+
+	w, err = change(tx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	t := postgres.Transaction{
+		WalletID:     w.ID,
+		Type:         transactionType,
+		Amount:       amount,
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+		Reason:       reason,
+	}
+	if err := insertTransaction(ctx, tx, &t); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &postgres.BalanceChange{Wallet: w, Transaction: t}, nil
+}
+
+// debit compares against minBalance in SQL so the check and the update
+// happen in one statement on the locked row.
+func debit(ctx context.Context, tx *sql.Tx, w postgres.Wallet, amount money.Money, minBalance money.Money) (postgres.Wallet, error) {
+	res, err := tx.ExecContext(ctx, "UPDATE user_wallet SET balance = balance - "+amountParam+", version = version + 1 WHERE id = ? AND balance - "+amountParam+" >= "+amountParam,
+		amount, w.ID, amount, minBalance)
+	if err != nil {
+		return w, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return w, err
+	}
+	if n == 0 {
+		return w, postgres.ErrInsufficientFunds
+	}
+	return reload(ctx, tx, w)
+}
+
+func credit(ctx context.Context, tx *sql.Tx, w postgres.Wallet, amount money.Money) (postgres.Wallet, error) {
+	if _, err := tx.ExecContext(ctx, "UPDATE user_wallet SET balance = balance + "+amountParam+", version = version + 1 WHERE id = ?", amount, w.ID); err != nil {
+		return w, err
+	}
+	return reload(ctx, tx, w)
+}
+
+// reload reads back the balance and version MySQL cannot return from an UPDATE.
+func reload(ctx context.Context, tx *sql.Tx, w postgres.Wallet) (postgres.Wallet, error) {
+	err := tx.QueryRowContext(ctx, "SELECT balance, version FROM user_wallet WHERE id = ?", w.ID).Scan(&w.Balance, &w.Version)
+	return w, err
+}
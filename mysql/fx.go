@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// Rate reads the fx_rate table, like the Postgres store.
+func (m *MySQL) Rate(ctx context.Context, from string, to string) (*postgres.FXRate, error) {
+	var r postgres.FXRate
+	err := m.Db.QueryRowContext(ctx, `SELECT from_currency, to_currency, rate, spread, updated_at
+		FROM fx_rate
+		WHERE from_currency = ? AND to_currency = ?`, from, to).
+		Scan(&r.From, &r.To, &r.Rate, &r.Spread, &r.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, postgres.ErrRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (m *MySQL) CreateFXQuote(ctx context.Context, q *postgres.FXQuote) (*postgres.FXQuote, error) {
+	_, err := m.Db.ExecContext(ctx, `INSERT INTO fx_quote (id, from_currency, to_currency, amount, converted_amount, rate, spread, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		q.ID, q.FromCurrency, q.ToCurrency, q.Amount, q.ConvertedAmount, q.Rate, q.Spread, q.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	err = m.Db.QueryRowContext(ctx, "SELECT created_at FROM fx_quote WHERE id = ?", q.ID).Scan(&q.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (m *MySQL) FindFXQuote(ctx context.Context, id string) (*postgres.FXQuote, error) {
+	var q postgres.FXQuote
+	var usedAt sql.NullTime
+	err := m.Db.QueryRowContext(ctx, `SELECT id, from_currency, to_currency, amount, converted_amount, rate, spread, expires_at, used_at, created_at
+		FROM fx_quote
+		WHERE id = ?`, id).
+		Scan(&q.ID, &q.FromCurrency, &q.ToCurrency, &q.Amount, &q.ConvertedAmount,
+			&q.Rate, &q.Spread, &q.ExpiresAt, &usedAt, &q.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fx quote %s: %w", id, postgres.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		q.UsedAt = &usedAt.Time
+	}
+	q.Amount = q.Amount.WithCurrency(q.FromCurrency)
+	q.ConvertedAmount = q.ConvertedAmount.WithCurrency(q.ToCurrency)
+	return &q, nil
+}
+
+// useFXQuote marks the quote spent inside the transfer's transaction, so a
+// quote can pay out at most once and never after it expires.
+func useFXQuote(ctx context.Context, tx *sql.Tx, id string) error {
+	res, err := tx.ExecContext(ctx, `UPDATE fx_quote SET used_at = NOW(6)
+		WHERE id = ? AND used_at IS NULL AND expires_at > NOW(6)`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return postgres.ErrQuoteExpired
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package mysql implements postgres.Storer on MySQL 8 with the schema in
+// mysql.sql. Wallets, the ledger and FX quotes behave as in the Postgres
+// store. The AuditMeta of a change is not recorded and no outbox event is
+// written, so the service refuses this store while the audit log or
+// webhooks are on.
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
+	driver "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+type MySQL struct {
+	Db *sql.DB
+}
+
+// New connects to the database in dsn, e.g.
+// "wallet:password@tcp(localhost:3306)/wallet". Times are read and written
+// in UTC whatever the DSN says, so they compare with the server's NOW().
+func New(dsn string) (*MySQL, error) {
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["time_zone"] = "'+00:00'"
+
+	db, err := tracing.OpenDB("mysql", cfg.FormatDSN(), semconv.DBSystemMySQL)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &MySQL{Db: db}, nil
+}
+
+// amountParam is a placeholder for a money.Money. MySQL compares a decimal
+// column with a string as floating point, so amounts are cast back to the
+// column type before any arithmetic or comparison.
+const amountParam = "CAST(? AS DECIMAL(18, 8))"
+
+// walletColumns lists the user_wallet columns in the order scanWallet reads them.
+const walletColumns = "id, user_id, user_name, wallet_name, wallet_type, balance, currency, status, version, created_at"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWallet reads one walletColumns row and tags the balance with the
+// wallet's currency.
+func scanWallet(row rowScanner) (postgres.Wallet, error) {
+	var w postgres.Wallet
+	err := row.Scan(&w.ID,
+		&w.UserID, &w.UserName,
+		&w.WalletName, &w.WalletType,
+		&w.Balance, &w.Currency, &w.Status, &w.Version, &w.CreatedAt,
+	)
+	w.Balance = w.Balance.WithCurrency(w.Currency)
+	return w, err
+}
+
+func scanWallets(rows *sql.Rows) ([]postgres.Wallet, error) {
+	defer rows.Close()
+
+	var wallets []postgres.Wallet
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, rows.Err()
+}
@@ -0,0 +1,34 @@
+package mysql_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/mysql"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/storetest"
+)
+
+// TestConformance needs a MySQL database loaded with mysql.sql, named by
+// MYSQL_TEST_DSN. Its tables are emptied before every test.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN not set")
+	}
+
+	m, err := mysql.New(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Db.Close()
+
+	storetest.Run(t, func(t *testing.T) postgres.Storer {
+		for _, table := range []string{"user_wallet", "wallet_transaction", "fx_quote"} {
+			if _, err := m.Db.Exec("TRUNCATE TABLE " + table); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return m
+	})
+}
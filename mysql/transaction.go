@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+func insertTransaction(ctx context.Context, tx *sql.Tx, t *postgres.Transaction) error {
+	counterparty := sql.NullInt64{Int64: int64(t.CounterpartyWalletID), Valid: t.CounterpartyWalletID != 0}
+	fxRate := sql.NullString{String: t.FXRate, Valid: t.FXRate != ""}
+	fxSpread := sql.NullString{String: t.FXSpread, Valid: t.FXSpread != ""}
+	fxQuoteID := sql.NullString{String: t.FXQuoteID, Valid: t.FXQuoteID != ""}
+	reason := sql.NullString{String: t.Reason, Valid: t.Reason != ""}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, currency, counterparty_wallet_id, fx_rate, fx_spread, fx_quote_id, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.WalletID, t.Type, t.Amount, t.BalanceAfter, t.Currency, counterparty, fxRate, fxSpread, fxQuoteID, reason)
+	if err != nil {
+		return err
+	}
+
+	t.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return tx.QueryRowContext(ctx, "SELECT created_at FROM wallet_transaction WHERE id = ?", t.ID).Scan(&t.CreatedAt)
+}
+
+func (m *MySQL) FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) ([]postgres.Transaction, error) {
+	rows, err := m.Db.QueryContext(ctx, `SELECT id, wallet_id, type, amount, balance_after, currency, counterparty_wallet_id, fx_rate, fx_spread, fx_quote_id, reason, created_at
+		FROM wallet_transaction
+		WHERE wallet_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, walletId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []postgres.Transaction
+	for rows.Next() {
+		var t postgres.Transaction
+		var counterparty sql.NullInt64
+		var fxRate, fxSpread, fxQuoteID, reason sql.NullString
+		err := rows.Scan(&t.ID, &t.WalletID, &t.Type,
+			&t.Amount, &t.BalanceAfter, &t.Currency,
+			&counterparty, &fxRate, &fxSpread, &fxQuoteID, &reason, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		t.Amount = t.Amount.WithCurrency(t.Currency)
+		t.BalanceAfter = t.BalanceAfter.WithCurrency(t.Currency)
+		t.CounterpartyWalletID = int(counterparty.Int64)
+		t.FXRate = fxRate.String
+		t.FXSpread = fxSpread.String
+		t.FXQuoteID = fxQuoteID.String
+		t.Reason = reason.String
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// SumTransactionsByWalletId returns the balance derived from the ledger.
+func (m *MySQL) SumTransactionsByWalletId(ctx context.Context, walletId int) (money.Money, error) {
+	var sum money.Money
+	err := m.Db.QueryRowContext(ctx, "SELECT COALESCE(SUM(amount), 0) FROM wallet_transaction WHERE wallet_id = ?", walletId).Scan(&sum)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return sum, nil
+}
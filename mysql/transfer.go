@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// Transfer moves amount between two wallets in one transaction, locking both
+// rows in id order like the Postgres store.
+func (m *MySQL) Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *postgres.FXQuote, meta postgres.AuditMeta) (*postgres.Transfer, error) {
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id IN (?, ?) ORDER BY id FOR UPDATE", fromWalletId, toWalletId)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int]postgres.Wallet, 2)
+	for _, w := range wallets {
+		locked[w.ID] = w
+	}
+
+	from, ok := locked[fromWalletId]
+	if !ok {
+		return nil, fmt.Errorf("wallet %d: %w", fromWalletId, postgres.ErrNotFound)
+	}
+	to, ok := locked[toWalletId]
+	if !ok {
+		return nil, fmt.Errorf("wallet %d: %w", toWalletId, postgres.ErrNotFound)
+	}
+
+	if err := requireStatus(from, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+	if err := requireStatus(to, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+
+	credited := amount
+	var fxRate, fxSpread, fxQuoteID string
+	if quote == nil {
+		if from.Currency != to.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+	} else {
+		if from.Currency != quote.FromCurrency || to.Currency != quote.ToCurrency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		if err := useFXQuote(ctx, tx, quote.ID); err != nil {
+			return nil, err
+		}
+		credited = quote.ConvertedAmount
+		fxRate, fxSpread, fxQuoteID = quote.Rate, quote.Spread, quote.ID
+	}
+
+	from, err = debit(ctx, tx, from, amount, minBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err = credit(ctx, tx, to, credited)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertTransaction(ctx, tx, &postgres.Transaction{
+		WalletID:             from.ID,
+		Type:                 postgres.TransactionTransferOut,
+		Amount:               amount.Neg(),
+		BalanceAfter:         from.Balance,
+		Currency:             from.Currency,
+		CounterpartyWalletID: to.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertTransaction(ctx, tx, &postgres.Transaction{
+		WalletID:             to.ID,
+		Type:                 postgres.TransactionTransferIn,
+		Amount:               credited,
+		BalanceAfter:         to.Balance,
+		Currency:             to.Currency,
+		CounterpartyWalletID: from.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &postgres.Transfer{FromWallet: from, ToWallet: to, Amount: amount, CreditedAmount: credited, Quote: quote}, nil
+}
@@ -0,0 +1,305 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+func (m *MySQL) FindAll(ctx context.Context) ([]postgres.Wallet, error) {
+	rows, err := m.Db.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE status <> 'closed'")
+	if err != nil {
+		return nil, err
+	}
+	return scanWallets(rows)
+}
+
+func (m *MySQL) FindByWalletType(ctx context.Context, walletType string) ([]postgres.Wallet, error) {
+	rows, err := m.Db.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE wallet_type = ? AND status <> 'closed'", walletType)
+	if err != nil {
+		return nil, err
+	}
+	return scanWallets(rows)
+}
+
+func (m *MySQL) FindByUserId(ctx context.Context, userId int) ([]postgres.Wallet, error) {
+	rows, err := m.Db.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE user_id = ? AND status <> 'closed'", userId)
+	if err != nil {
+		return nil, err
+	}
+	return scanWallets(rows)
+}
+
+func (m *MySQL) FindByWalletId(ctx context.Context, walletID int) (*postgres.Wallet, error) {
+	w, err := scanWallet(m.Db.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = ?", walletID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("wallet %d: %w", walletID, postgres.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (m *MySQL) Create(ctx context.Context, w *postgres.Wallet, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance, currency) VALUES (?, ?, ?, ?, ?, ?)",
+		w.UserID, w.UserName, w.WalletName, w.WalletType, w.Balance, w.Currency)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	w.ID, w.Status, w.Version, w.CreatedAt = created.ID, created.Status, created.Version, created.CreatedAt
+
+	err = insertTransaction(ctx, tx, &postgres.Transaction{
+		WalletID:     w.ID,
+		Type:         postgres.TransactionCreate,
+		Amount:       w.Balance,
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// CountByCriteria counts wallets, closed ones included, whose columns equal
+// every non-zero field of criteria.
+func (m *MySQL) CountByCriteria(ctx context.Context, criteria postgres.Wallet) (int, error) {
+	var conditions []string
+	var args []interface{}
+
+	v := reflect.ValueOf(criteria)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			continue
+		}
+		param := "?"
+		if _, ok := v.Field(i).Interface().(money.Money); ok {
+			param = amountParam
+		}
+		conditions = append(conditions, v.Type().Field(i).Tag.Get("postgres")+" = "+param)
+		args = append(args, v.Field(i).Interface())
+	}
+
+	query := "SELECT count(id) FROM user_wallet"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int
+	err := m.Db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// DeleteByUserId closes every open wallet of userId, as long as all of them
+// are active and empty.
+func (m *MySQL) DeleteByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE user_id = ? AND status <> 'closed' ORDER BY id FOR UPDATE", id)
+	if err != nil {
+		return 0, err
+	}
+	open, err := scanWallets(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, w := range open {
+		if err := requireStatus(w, postgres.WalletActive); err != nil {
+			return 0, err
+		}
+		if !w.Balance.IsZero() {
+			return 0, fmt.Errorf("wallet %d: %w", w.ID, postgres.ErrBalanceNotZero)
+		}
+	}
+
+	for _, w := range open {
+		if _, err := setStatus(ctx, tx, w, postgres.WalletClosed); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(open)), nil
+}
+
+func (m *MySQL) DeleteByWalletId(ctx context.Context, walletId int, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStatus(w, postgres.WalletActive); err != nil {
+		return nil, err
+	}
+	if !w.Balance.IsZero() {
+		return nil, fmt.Errorf("wallet %d: %w", walletId, postgres.ErrBalanceNotZero)
+	}
+
+	w, err = setStatus(ctx, tx, w, postgres.WalletClosed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// UpdateByWalletId sets the non-zero fields of wallet other than its
+// balance, while the row is still at version and active. It reports 0 rows
+// for a wallet that does not exist.
+func (m *MySQL) UpdateByWalletId(ctx context.Context, walletId int, version int, wallet postgres.Wallet, meta postgres.AuditMeta) (int64, error) {
+	var updates []string
+	var args []interface{}
+
+	if wallet.UserID != 0 {
+		updates = append(updates, "user_id = ?")
+		args = append(args, wallet.UserID)
+	}
+	if wallet.UserName != "" {
+		updates = append(updates, "user_name = ?")
+		args = append(args, wallet.UserName)
+	}
+	if wallet.WalletName != "" {
+		updates = append(updates, "wallet_name = ?")
+		args = append(args, wallet.WalletName)
+	}
+	if wallet.WalletType != "" {
+		updates = append(updates, "wallet_type = ?")
+		args = append(args, wallet.WalletType)
+	}
+	updates = append(updates, "version = version + 1")
+	args = append(args, walletId)
+
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	before, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = ? FOR UPDATE", walletId))
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if before.Version != version {
+		return 0, postgres.ErrVersionConflict
+	}
+	if err := requireStatus(before, postgres.WalletActive); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE user_wallet SET "+strings.Join(updates, ", ")+" WHERE id = ?", args...); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// MySQL counts changed rows only; the version bump means this one changed
+	return 1, nil
+}
+
+func (m *MySQL) SetStatus(ctx context.Context, walletId int, version int, status string, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+	tx, err := m.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if w.Version != version {
+		return nil, postgres.ErrVersionConflict
+	}
+
+	w, err = setStatus(ctx, tx, w, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func setStatus(ctx context.Context, tx *sql.Tx, w postgres.Wallet, status string) (postgres.Wallet, error) {
+	if _, err := tx.ExecContext(ctx, "UPDATE user_wallet SET status = ?, version = version + 1 WHERE id = ?", status, w.ID); err != nil {
+		return w, err
+	}
+	w.Status = status
+	w.Version++
+	return w, nil
+}
+
+// requireStatus fails unless w is in one of the given statuses.
+func requireStatus(w postgres.Wallet, statuses ...string) error {
+	for _, s := range statuses {
+		if w.Status == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("wallet %d is %s: %w", w.ID, w.Status, postgres.ErrWalletInactive)
+}
+
+func lockWallet(ctx context.Context, tx *sql.Tx, walletId int) (postgres.Wallet, error) {
+	w, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = ? FOR UPDATE", walletId))
+	if err == sql.ErrNoRows {
+		return w, fmt.Errorf("wallet %d: %w", walletId, postgres.ErrNotFound)
+	}
+	return w, err
+}
@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+var walletSortColumns = map[string]string{
+	postgres.SortCreatedAt:  "created_at",
+	postgres.SortBalance:    "balance",
+	postgres.SortWalletName: "wallet_name",
+}
+
+// ListWallets returns one page of wallets with the same keyset query as the
+// Postgres store: ordered by the sort column and id, resuming strictly after
+// filter.After.
+func (m *MySQL) ListWallets(ctx context.Context, filter postgres.WalletFilter) ([]postgres.Wallet, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, values ...interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, values...)
+	}
+
+	if filter.WalletType != "" {
+		where("wallet_type = ?", filter.WalletType)
+	}
+	if filter.Status != "" {
+		where("status = ?", filter.Status)
+	} else {
+		where("status <> ?", postgres.WalletClosed)
+	}
+	if filter.UserID != 0 {
+		where("user_id = ?", filter.UserID)
+	}
+	if filter.MinBalance != nil {
+		where("balance >= "+amountParam, *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		where("balance <= "+amountParam, *filter.MaxBalance)
+	}
+	if filter.CreatedFrom != nil {
+		where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where("created_at < ?", *filter.CreatedTo)
+	}
+
+	column, ok := walletSortColumns[filter.Sort]
+	if !ok {
+		column = walletSortColumns[postgres.SortCreatedAt]
+	}
+
+	direction, compare := "ASC", ">"
+	if filter.Descending {
+		direction, compare = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		var value interface{}
+		param := "?"
+		switch filter.Sort {
+		case postgres.SortBalance:
+			value, param = filter.After.Balance, amountParam
+		case postgres.SortWalletName:
+			value = filter.After.WalletName
+		default:
+			value = filter.After.CreatedAt
+		}
+		where("("+column+", id) "+compare+" ("+param+", ?)", value, filter.After.ID)
+	}
+
+	query := "SELECT " + walletColumns + " FROM user_wallet WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + column + " " + direction + ", id " + direction + " LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := m.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanWallets(rows)
+}
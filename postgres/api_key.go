@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey authenticates a machine client. The key itself is never stored,
+// only SecretHash.
+type APIKey struct {
+	ID         int        `postgres:"id"`
+	Name       string     `postgres:"name"`
+	Prefix     string     `postgres:"prefix"`
+	SecretHash string     `postgres:"secret_hash"`
+	Scopes     []string   `postgres:"scopes"`
+	ExpiresAt  *time.Time `postgres:"expires_at"`
+	LastUsedAt *time.Time `postgres:"last_used_at"`
+	RevokedAt  *time.Time `postgres:"revoked_at"`
+	CreatedAt  time.Time  `postgres:"created_at"`
+}
+
+type APIKeyStorer interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+
+	FindAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+
+	// RevokeAPIKey stops a key from authenticating. Revoking a key twice
+	// keeps the first revocation time.
+	RevokeAPIKey(ctx context.Context, id int) (*APIKey, error)
+
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
+
+const apiKeyColumns = "id, name, prefix, secret_hash, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (APIKey, error) {
+	var k APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.SecretHash, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &k.CreatedAt)
+	if err != nil {
+		return k, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	return k, nil
+}
+
+func (p *Postgres) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	row := p.Db.QueryRowContext(ctx, `INSERT INTO api_key (name, prefix, secret_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING `+apiKeyColumns,
+		key.Name, key.Prefix, key.SecretHash, strings.Join(key.Scopes, " "), key.ExpiresAt)
+
+	k, err := scanAPIKey(row)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (p *Postgres) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := p.Db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (p *Postgres) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	k, err := scanAPIKey(p.Db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key WHERE prefix = $1", prefix))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api key %s: %w", prefix, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (p *Postgres) RevokeAPIKey(ctx context.Context, id int) (*APIKey, error) {
+	k, err := scanAPIKey(p.Db.QueryRowContext(ctx, `UPDATE api_key SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = $1 RETURNING `+apiKeyColumns, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api key %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (p *Postgres) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	_, err := p.Db.ExecContext(ctx, "UPDATE api_key SET last_used_at = $1 WHERE id = $2", usedAt, id)
+	return err
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audit actions, one per kind of wallet change.
+const (
+	AuditWalletCreate      = "wallet.create"
+	AuditWalletUpdate      = "wallet.update"
+	AuditWalletDelete      = "wallet.delete"
+	AuditWalletDeposit     = "wallet.deposit"
+	AuditWalletWithdraw    = "wallet.withdraw"
+	AuditWalletAdjust      = "wallet.adjust"
+	AuditWalletTransferOut = "wallet.transfer_out"
+	AuditWalletTransferIn  = "wallet.transfer_in"
+	AuditWalletFreeze      = "wallet.freeze"
+	AuditWalletUnfreeze    = "wallet.unfreeze"
+	AuditWalletClose       = "wallet.close"
+)
+
+// AuditMeta says who asked for a change and where the request came from.
+// Every mutating Storer method takes one and records it with the change.
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+	ClientIP  string
+}
+
+type AuditEvent struct {
+	ID        int64           `postgres:"id"`
+	Actor     string          `postgres:"actor"`
+	Action    string          `postgres:"action"`
+	WalletID  int             `postgres:"wallet_id"`
+	RequestID string          `postgres:"request_id"`
+	ClientIP  string          `postgres:"client_ip"`
+	Before    json.RawMessage `postgres:"before"`
+	After     json.RawMessage `postgres:"after"`
+	CreatedAt time.Time       `postgres:"created_at"`
+}
+
+// AuditFilter selects audit events newest first. Zero fields do not filter;
+// BeforeID continues a listing below the last id seen.
+type AuditFilter struct {
+	WalletID int
+	Actor    string
+	Action   string
+	From     *time.Time
+	To       *time.Time
+	BeforeID int64
+	Limit    int
+}
+
+type AuditStorer interface {
+	ListAuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
+}
+
+// walletSnapshot is the JSON form of a wallet kept in before and after.
+type walletSnapshot struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	WalletName string    `json:"wallet_name"`
+	WalletType string    `json:"wallet_type"`
+	Balance    string    `json:"balance"`
+	Currency   string    `json:"currency"`
+	Status     string    `json:"status"`
+	Version    int       `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func snapshot(w *Wallet) ([]byte, error) {
+	if w == nil {
+		return nil, nil
+	}
+	return json.Marshal(walletSnapshot{
+		ID:         w.ID,
+		UserID:     w.UserID,
+		UserName:   w.UserName,
+		WalletName: w.WalletName,
+		WalletType: w.WalletType,
+		Balance:    w.Balance.String(),
+		Currency:   w.Currency,
+		Status:     w.Status,
+		Version:    w.Version,
+		CreatedAt:  w.CreatedAt,
+	})
+}
+
+// insertAuditEvent records a change to walletId inside the caller's
+// transaction, so the event exists exactly when the change does.
+func insertAuditEvent(ctx context.Context, tx *sql.Tx, meta AuditMeta, action string, walletId int, before *Wallet, after *Wallet) error {
+	b, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	a, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	requestID := sql.NullString{String: meta.RequestID, Valid: meta.RequestID != ""}
+	clientIP := sql.NullString{String: meta.ClientIP, Valid: meta.ClientIP != ""}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_event (actor, action, wallet_id, request_id, client_ip, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		meta.Actor, action, walletId, requestID, clientIP, nullJSON(b), nullJSON(a))
+	return err
+}
+
+func nullJSON(b []byte) interface{} {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+func (p *Postgres) ListAuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.WalletID != 0 {
+		add("wallet_id = $%d", filter.WalletID)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if filter.BeforeID != 0 {
+		add("id < $%d", filter.BeforeID)
+	}
+
+	query := "SELECT id, actor, action, wallet_id, request_id, client_ip, before, after, created_at FROM audit_event"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []AuditEvent
+	for rows.Next() {
+		var e AuditEvent
+		var requestID, clientIP sql.NullString
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.WalletID, &requestID, &clientIP, &before, &after, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.RequestID = requestID.String
+		e.ClientIP = clientIP.String
+		if before != nil {
+			e.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			e.After = json.RawMessage(after)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+)
+
+type BalanceChange struct {
+	Wallet      Wallet
+	Transaction Transaction
+}
+
+func (p *Postgres) Deposit(ctx context.Context, walletId int, amount money.Money, meta AuditMeta) (*BalanceChange, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	before := w
+	if err := requireStatus(w, WalletActive); err != nil {
+		return nil, err
+	}
+
+	w.Balance, w.Version, err = credit(ctx, tx, walletId, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitBalanceChange(ctx, tx, meta, before, w, TransactionDeposit, amount, "")
+}
+
+// Withdraw debits amount as long as the balance stays at or above minBalance,
+// which lets the caller decide how far a wallet may go negative.
+func (p *Postgres) Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta AuditMeta) (*BalanceChange, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	before := w
+	if err := requireStatus(w, WalletActive); err != nil {
+		return nil, err
+	}
+
+	w.Balance, w.Version, err = debit(ctx, tx, walletId, amount, minBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitBalanceChange(ctx, tx, meta, before, w, TransactionWithdrawal, amount.Neg(), "")
+}
+
+// Adjust books a signed correction with the reason it was made. Unlike a
+// withdrawal it has no floor, since it exists to fix balances that are wrong.
+func (p *Postgres) Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta AuditMeta) (*BalanceChange, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	w, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	before := w
+	// Corrections still apply to frozen wallets, not to closed ones
+	if err := requireStatus(w, WalletActive, WalletFrozen); err != nil {
+		return nil, err
+	}
+
+	w.Balance, w.Version, err = credit(ctx, tx, walletId, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitBalanceChange(ctx, tx, meta, before, w, TransactionAdjustment, amount, reason)
+}
+
+// balanceAuditActions names the audit action of each balance change.
+var balanceAuditActions = map[string]string{
+	TransactionDeposit:    AuditWalletDeposit,
+	TransactionWithdrawal: AuditWalletWithdraw,
+	TransactionAdjustment: AuditWalletAdjust,
+}
+
+func commitBalanceChange(ctx context.Context, tx *sql.Tx, meta AuditMeta, before Wallet, w Wallet, transactionType string, amount money.Money, reason string) (*BalanceChange, error) {
+	t := Transaction{
+		WalletID:     w.ID,
+		Type:         transactionType,
+		Amount:       amount,
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+		Reason:       reason,
+	}
+
+	err := insertTransaction(ctx, tx, &t)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertAuditEvent(ctx, tx, meta, balanceAuditActions[transactionType], w.ID, &before, &w)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertWalletEvent(ctx, tx, EventWalletUpdated, &w)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &BalanceChange{Wallet: w, Transaction: t}, nil
+}
+
+func lockWallet(ctx context.Context, tx *sql.Tx, walletId int) (Wallet, error) {
+	w, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = $1 FOR UPDATE", walletId))
+	if err == sql.ErrNoRows {
+		return w, fmt.Errorf("wallet %d: %w", walletId, ErrNotFound)
+	}
+	return w, err
+}
+
+// debit compares against minBalance in SQL so the check and the update
+// happen in one statement on the locked row.
+func debit(ctx context.Context, tx *sql.Tx, walletId int, amount money.Money, minBalance money.Money) (money.Money, int, error) {
+	var balance money.Money
+	var version int
+	err := tx.QueryRowContext(ctx, "UPDATE user_wallet SET balance = balance - $1, version = version + 1 WHERE id = $2 AND balance - $1 >= $3 RETURNING balance, version",
+		amount, walletId, minBalance).Scan(&balance, &version)
+	if err == sql.ErrNoRows {
+		return money.Money{}, 0, ErrInsufficientFunds
+	}
+	return balance.WithCurrency(amount.Currency()), version, err
+}
+
+func credit(ctx context.Context, tx *sql.Tx, walletId int, amount money.Money) (money.Money, int, error) {
+	var balance money.Money
+	var version int
+	err := tx.QueryRowContext(ctx, "UPDATE user_wallet SET balance = balance + $1, version = version + 1 WHERE id = $2 RETURNING balance, version",
+		amount, walletId).Scan(&balance, &version)
+	return balance.WithCurrency(amount.Currency()), version, err
+}
@@ -0,0 +1,14 @@
+package postgres
+
+import "errors"
+
+// ErrNotFound is wrapped by every lookup that finds no row, with the
+// missing resource in the message; test for it with errors.Is.
+var ErrNotFound = errors.New("not found")
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrVersionConflict   = errors.New("wallet was modified since it was read")
+	ErrWalletInactive    = errors.New("wallet does not accept this operation")
+	ErrBalanceNotZero    = errors.New("wallet balance must be zero to close")
+)
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+)
+
+var (
+	ErrRateNotFound = errors.New("no fx rate for currency pair")
+	ErrQuoteExpired = errors.New("fx quote expired or already used")
+)
+
+// FXRate is the mid-market price of one unit of From in To, and the spread
+// kept on top of it as a fraction (0.005 is half a percent). Both are exact
+// decimal strings as stored in NUMERIC columns.
+type FXRate struct {
+	From      string    `postgres:"from_currency"`
+	To        string    `postgres:"to_currency"`
+	Rate      string    `postgres:"rate"`
+	Spread    string    `postgres:"spread"`
+	UpdatedAt time.Time `postgres:"updated_at"`
+}
+
+// FXQuote fixes a rate for a single transfer of Amount until ExpiresAt.
+type FXQuote struct {
+	ID              string      `postgres:"id"`
+	FromCurrency    string      `postgres:"from_currency"`
+	ToCurrency      string      `postgres:"to_currency"`
+	Amount          money.Money `postgres:"amount"`
+	ConvertedAmount money.Money `postgres:"converted_amount"`
+	Rate            string      `postgres:"rate"`
+	Spread          string      `postgres:"spread"`
+	ExpiresAt       time.Time   `postgres:"expires_at"`
+	UsedAt          *time.Time  `postgres:"used_at"`
+	CreatedAt       time.Time   `postgres:"created_at"`
+}
+
+// Rate reads the fx_rate table, which makes Postgres a rate provider backed
+// by whatever feed keeps that table current.
+func (p *Postgres) Rate(ctx context.Context, from string, to string) (*FXRate, error) {
+	var r FXRate
+	err := p.Db.QueryRowContext(ctx, `SELECT from_currency, to_currency, rate, spread, updated_at
+		FROM fx_rate
+		WHERE from_currency = $1 AND to_currency = $2`, from, to).
+		Scan(&r.From, &r.To, &r.Rate, &r.Spread, &r.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (p *Postgres) CreateFXQuote(ctx context.Context, q *FXQuote) (*FXQuote, error) {
+	err := p.Db.QueryRowContext(ctx, `INSERT INTO fx_quote (id, from_currency, to_currency, amount, converted_amount, rate, spread, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at`,
+		q.ID, q.FromCurrency, q.ToCurrency, q.Amount, q.ConvertedAmount, q.Rate, q.Spread, q.ExpiresAt).
+		Scan(&q.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (p *Postgres) FindFXQuote(ctx context.Context, id string) (*FXQuote, error) {
+	var q FXQuote
+	var usedAt sql.NullTime
+	err := p.Db.QueryRowContext(ctx, `SELECT id, from_currency, to_currency, amount, converted_amount, rate, spread, expires_at, used_at, created_at
+		FROM fx_quote
+		WHERE id = $1`, id).
+		Scan(&q.ID, &q.FromCurrency, &q.ToCurrency, &q.Amount, &q.ConvertedAmount,
+			&q.Rate, &q.Spread, &q.ExpiresAt, &usedAt, &q.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fx quote %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		q.UsedAt = &usedAt.Time
+	}
+	q.Amount = q.Amount.WithCurrency(q.FromCurrency)
+	q.ConvertedAmount = q.ConvertedAmount.WithCurrency(q.ToCurrency)
+	return &q, nil
+}
+
+// useFXQuote marks the quote spent inside the transfer's transaction, so a
+// quote can pay out at most once and never after it expires.
+func useFXQuote(ctx context.Context, tx *sql.Tx, id string) error {
+	res, err := tx.ExecContext(ctx, `UPDATE fx_quote SET used_at = now()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > now()`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrQuoteExpired
+	}
+	return nil
+}
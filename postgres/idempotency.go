@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is the outcome of the first request a caller made with
+// a key. ResponseStatus stays 0 while that request is still being processed.
+// ResponseHeaders holds the headers replayed with the body.
+type IdempotencyRecord struct {
+	Principal       string      `postgres:"principal"`
+	Key             string      `postgres:"key"`
+	RequestHash     string      `postgres:"request_hash"`
+	ResponseStatus  int         `postgres:"response_status"`
+	ResponseHeaders http.Header `postgres:"response_headers"`
+	ResponseBody    []byte      `postgres:"response_body"`
+	CreatedAt       time.Time   `postgres:"created_at"`
+}
+
+type IdempotencyStorer interface {
+	// ReserveIdempotencyKey claims key for a new request of principal. When
+	// principal already holds the key in an unexpired record it returns that
+	// record and false. Other callers' keys never match.
+	ReserveIdempotencyKey(ctx context.Context, principal string, key string, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+
+	CompleteIdempotencyKey(ctx context.Context, principal string, key string, status int, header http.Header, body []byte) error
+
+	ReleaseIdempotencyKey(ctx context.Context, principal string, key string) error
+}
+
+func (p *Postgres) ReserveIdempotencyKey(ctx context.Context, principal string, key string, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	// An expired record is taken over in place, a live one is left alone and
+	// the insert returns no row.
+	row := p.Db.QueryRowContext(ctx, `INSERT INTO idempotency_key (principal, key, request_hash) VALUES ($1, $2, $3)
+		ON CONFLICT (principal, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, response_status = NULL, response_headers = NULL, response_body = NULL, created_at = CURRENT_TIMESTAMP
+			WHERE idempotency_key.created_at < CURRENT_TIMESTAMP - $4 * INTERVAL '1 second'
+		RETURNING created_at`, principal, key, requestHash, ttl.Seconds())
+
+	var createdAt time.Time
+	err := row.Scan(&createdAt)
+	if err == nil {
+		return &IdempotencyRecord{Principal: principal, Key: key, RequestHash: requestHash, CreatedAt: createdAt}, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	var record IdempotencyRecord
+	var status sql.NullInt64
+	var header []byte
+	err = p.Db.QueryRowContext(ctx, "SELECT principal, key, request_hash, response_status, response_headers, response_body, created_at FROM idempotency_key WHERE principal = $1 AND key = $2", principal, key).
+		Scan(&record.Principal, &record.Key, &record.RequestHash, &status, &header, &record.ResponseBody, &record.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+	record.ResponseStatus = int(status.Int64)
+	if header != nil {
+		if err := json.Unmarshal(header, &record.ResponseHeaders); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &record, false, nil
+}
+
+func (p *Postgres) CompleteIdempotencyKey(ctx context.Context, principal string, key string, status int, header http.Header, body []byte) error {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = p.Db.ExecContext(ctx, "UPDATE idempotency_key SET response_status = $1, response_headers = $2, response_body = $3 WHERE principal = $4 AND key = $5", status, headerJSON, body, principal, key)
+	return err
+}
+
+func (p *Postgres) ReleaseIdempotencyKey(ctx context.Context, principal string, key string) error {
+	_, err := p.Db.ExecContext(ctx, "DELETE FROM idempotency_key WHERE principal = $1 AND key = $2", principal, key)
+	return err
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Event types written to the outbox.
+const (
+	EventWalletCreated     = "wallet.created"
+	EventWalletUpdated     = "wallet.updated"
+	EventWalletDeleted     = "wallet.deleted"
+	EventTransferCompleted = "transfer.completed"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []string{EventWalletCreated, EventWalletUpdated, EventWalletDeleted, EventTransferCompleted}
+
+// OutboxEvent is a change waiting to be sent to webhook subscribers. Payload
+// is the JSON delivered as the event's data.
+type OutboxEvent struct {
+	ID        int64           `postgres:"id"`
+	EventType string          `postgres:"event_type"`
+	Payload   json.RawMessage `postgres:"payload"`
+	CreatedAt time.Time       `postgres:"created_at"`
+}
+
+// transferPayload is the data of a transfer.completed event.
+type transferPayload struct {
+	FromWalletID     int    `json:"from_wallet_id"`
+	ToWalletID       int    `json:"to_wallet_id"`
+	Amount           string `json:"amount"`
+	Currency         string `json:"currency"`
+	CreditedAmount   string `json:"credited_amount"`
+	CreditedCurrency string `json:"credited_currency"`
+	FXQuoteID        string `json:"fx_quote_id,omitempty"`
+}
+
+// insertWalletEvent queues an event carrying the wallet as it is after the
+// change, inside the caller's transaction.
+func insertWalletEvent(ctx context.Context, tx *sql.Tx, eventType string, w *Wallet) error {
+	payload, err := snapshot(w)
+	if err != nil {
+		return err
+	}
+	return insertOutboxEvent(ctx, tx, eventType, payload)
+}
+
+func insertTransferEvent(ctx context.Context, tx *sql.Tx, t *Transfer) error {
+	p := transferPayload{
+		FromWalletID:     t.FromWallet.ID,
+		ToWalletID:       t.ToWallet.ID,
+		Amount:           t.Amount.String(),
+		Currency:         t.FromWallet.Currency,
+		CreditedAmount:   t.CreditedAmount.String(),
+		CreditedCurrency: t.ToWallet.Currency,
+	}
+	if t.Quote != nil {
+		p.FXQuoteID = t.Quote.ID
+	}
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return insertOutboxEvent(ctx, tx, EventTransferCompleted, payload)
+}
+
+// insertOutboxEvent stores the event and one pending delivery for every
+// active subscription that wants it. Both are part of the caller's
+// transaction, so an event exists exactly when its change was committed and
+// the dispatcher only has to look at webhook_delivery.
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, eventType string, payload []byte) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, "INSERT INTO outbox_event (event_type, payload) VALUES ($1, $2) RETURNING id",
+		eventType, string(payload)).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO webhook_delivery (event_id, subscription_id)
+		SELECT $1, id FROM webhook_subscription
+		WHERE active AND (event_types = '' OR $2 = ANY(string_to_array(event_types, ' ')))`,
+		id, eventType)
+	return err
+}
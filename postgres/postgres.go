@@ -1,59 +1,77 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
-type DbConfiguration struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DbName   string
-	SslMode  string
-}
+// Backoff between attempts to reach a database that is not up yet.
+const (
+	firstRetry  = 250 * time.Millisecond
+	maxRetry    = 5 * time.Second
+	pingTimeout = 5 * time.Second
+)
 
-func New() (*Postgres, error) {
-	databaseSource := generateDatabaseUrl()
-	log.Println(databaseSource)
-	db, err := sql.Open("postgres", databaseSource)
+// New connects to the database in cfg and sizes the connection pool. A
+// database that does not answer yet is retried for cfg.ConnectTimeout. The
+// connection string holds the password, so only its parts without it are
+// logged.
+func New(cfg config.DB, logger *slog.Logger) (*Postgres, error) {
+	databaseSource := generateDatabaseUrl(cfg)
+	db, err := tracing.OpenDB("postgres", databaseSource, semconv.DBSystemPostgreSQL)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
-	err = db.Ping()
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	err = ping(db, cfg.ConnectTimeout, logger)
 	if err != nil {
-		log.Fatal(err)
+		db.Close()
+		return nil, fmt.Errorf("connect to postgres at %s:%d: %w", cfg.Host, cfg.Port, err)
 	}
 
-	return &Postgres{Db: db}, nil
+	logger.Info("connected to postgres", "host", cfg.Host, "port", cfg.Port, "database", cfg.Name, "user", cfg.User)
+	return &Postgres{Db: db, Logger: logger}, nil
 }
 
-func initDbConfiguration() *DbConfiguration {
-	return &DbConfiguration{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DbName:   os.Getenv("POSTGRES_DB_NAME"),
-		SslMode:  os.Getenv("POSTGRES_SSL_MODE"),
+// ping waits for db to answer, retrying with exponential backoff until
+// budget runs out. It returns the last error.
+func ping(db *sql.DB, budget time.Duration, logger *slog.Logger) error {
+	deadline := time.Now().Add(budget)
+	wait := firstRetry
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return err
+		}
+		logger.Warn("postgres not ready, retrying", "attempt", attempt, "retry_in", wait.String(), "err", err)
+		time.Sleep(wait)
+		wait = min(wait*2, maxRetry)
 	}
 }
 
-func generateDatabaseUrl()string{
-	dbConfig := initDbConfiguration()
-	return fmt.Sprintf("host=%s port=%s user=%s "+
+func generateDatabaseUrl(dbConfig config.DB)string{
+	return fmt.Sprintf("host=%s port=%d user=%s "+
 		"password=%s dbname=%s sslmode=%s",
 		dbConfig.Host,
 		dbConfig.Port, 
 		dbConfig.User, 
 		dbConfig.Password, 
-		dbConfig.DbName,dbConfig.SslMode,
+		dbConfig.Name,dbConfig.SSLMode,
 	)
 
 }
\ No newline at end of file
@@ -1,47 +1,68 @@
 package postgres
 
 import (
-	"os"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGenerateDatabaseUrl(t *testing.T) {
-	// Set up environment variables for testing
-	os.Setenv("POSTGRES_HOST", "localhost")
-	os.Setenv("POSTGRES_PORT", "5432")
-	os.Setenv("POSTGRES_USER", "testuser")
-	os.Setenv("POSTGRES_PASSWORD", "testpassword")
-	os.Setenv("POSTGRES_DB_NAME", "testdb")
-	os.Setenv("POSTGRES_SSL_MODE", "disable")
+	dbConfig := config.DB{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpassword",
+		Name:     "testdb",
+		SSLMode:  "disable",
+	}
 
 	expectedURL := "host=localhost port=5432 user=testuser password=testpassword dbname=testdb sslmode=disable"
 
 	// Call the function and check if the generated URL matches the expected URL
-	actualURL := generateDatabaseUrl()
+	actualURL := generateDatabaseUrl(dbConfig)
 	assert.Equal(t, expectedURL, actualURL)
 }
 
-func TestInitDbConfiguration(t *testing.T) {
-	// Set up environment variables for testing
-	os.Setenv("POSTGRES_HOST", "localhost")
-	os.Setenv("POSTGRES_PORT", "5432")
-	os.Setenv("POSTGRES_USER", "testuser")
-	os.Setenv("POSTGRES_PASSWORD", "testpassword")
-	os.Setenv("POSTGRES_DB_NAME", "testdb")
-	os.Setenv("POSTGRES_SSL_MODE", "disable")
-
-	// Call the function and check if the returned configuration matches the expected values
-	expectedConfig := &DbConfiguration{
-		Host:     "localhost",
-		Port:     "5432",
-		User:     "testuser",
-		Password: "testpassword",
-		DbName:   "testdb",
-		SslMode:  "disable",
+func TestPing(t *testing.T) {
+	// a port nothing listens on, so every attempt is refused at once
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
 	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=wallet dbname=wallet sslmode=disable", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	t.Run("given no budget should try once", func(t *testing.T) {
+		var logs bytes.Buffer
+
+		err := ping(db, 0, slog.New(slog.NewTextHandler(&logs, nil)))
+
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("given a budget should retry with backoff until it runs out", func(t *testing.T) {
+		var logs bytes.Buffer
+		start := time.Now()
+
+		err := ping(db, 600*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
 
-	actualConfig := initDbConfiguration()
-	assert.Equal(t, expectedConfig, actualConfig)
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Equal(t, 1, strings.Count(logs.String(), "postgres not ready"), "a retry after 250ms, none after 500ms more")
+		assert.GreaterOrEqual(t, time.Since(start), firstRetry)
+	})
 }
@@ -0,0 +1,47 @@
+package postgres
+
+import "context"
+
+// RoleStorer reads the role_permission and user_role tables.
+type RoleStorer interface {
+	// RolePermissions maps every role to the permissions it grants.
+	RolePermissions(ctx context.Context) (map[string][]string, error)
+
+	FindRolesByUserId(ctx context.Context, userId int) ([]string, error)
+}
+
+func (p *Postgres) RolePermissions(ctx context.Context) (map[string][]string, error) {
+	rows, err := p.Db.QueryContext(ctx, "SELECT role, permission FROM role_permission ORDER BY role, permission")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := map[string][]string{}
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+		grants[role] = append(grants[role], permission)
+	}
+	return grants, rows.Err()
+}
+
+func (p *Postgres) FindRolesByUserId(ctx context.Context, userId int) ([]string, error) {
+	rows, err := p.Db.QueryContext(ctx, "SELECT role FROM user_role WHERE user_id = $1 ORDER BY role", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func IsWalletStatus(status string) bool {
+	_, ok := statusAuditActions[status]
+	return ok
+}
+
+// statusAuditActions names the audit action of moving a wallet to a status.
+var statusAuditActions = map[string]string{
+	WalletActive: AuditWalletUnfreeze,
+	WalletFrozen: AuditWalletFreeze,
+	WalletClosed: AuditWalletClose,
+}
+
+// SetStatus moves a wallet to status as long as it is still at version.
+// Which moves are allowed is up to the caller; the version check makes sure
+// the wallet it decided on is the one that gets changed.
+func (p *Postgres) SetStatus(ctx context.Context, walletId int, version int, status string, meta AuditMeta) (*Wallet, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if before.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	after, err := setStatus(ctx, tx, before, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertAuditEvent(ctx, tx, meta, statusAuditActions[status], walletId, &before, &after); err != nil {
+		return nil, err
+	}
+
+	// Subscribers see closing as deletion, the way the API presents it
+	eventType := EventWalletUpdated
+	if status == WalletClosed {
+		eventType = EventWalletDeleted
+	}
+	if err := insertWalletEvent(ctx, tx, eventType, &after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// DeleteByWalletId closes one active wallet with a zero balance. Like
+// DeleteByUserId it keeps the row.
+func (p *Postgres) DeleteByWalletId(ctx context.Context, walletId int, meta AuditMeta) (*Wallet, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStatus(before, WalletActive); err != nil {
+		return nil, err
+	}
+	if !before.Balance.IsZero() {
+		return nil, fmt.Errorf("wallet %d: %w", walletId, ErrBalanceNotZero)
+	}
+
+	after, err := setStatus(ctx, tx, before, WalletClosed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertAuditEvent(ctx, tx, meta, AuditWalletDelete, walletId, &before, &after); err != nil {
+		return nil, err
+	}
+	if err := insertWalletEvent(ctx, tx, EventWalletDeleted, &after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+func setStatus(ctx context.Context, tx *sql.Tx, w Wallet, status string) (Wallet, error) {
+	err := tx.QueryRowContext(ctx, "UPDATE user_wallet SET status = $1, version = version + 1 WHERE id = $2 RETURNING status, version",
+		status, w.ID).Scan(&w.Status, &w.Version)
+	return w, err
+}
+
+// requireStatus fails unless w is in one of the given statuses.
+func requireStatus(w Wallet, statuses ...string) error {
+	for _, s := range statuses {
+		if w.Status == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("wallet %d is %s: %w", w.ID, w.Status, ErrWalletInactive)
+}
@@ -0,0 +1,69 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/migrate"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/storetest"
+)
+
+var _ storetest.EventStore = eventStore{}
+
+// TestConformance needs a database named by the connection string in
+// POSTGRES_TEST_DSN. It is migrated first and its wallet tables are emptied
+// before every test.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, func(t *testing.T) postgres.Storer {
+		if _, err := db.Exec("TRUNCATE user_wallet, wallet_transaction, fx_quote, audit_event, webhook_delivery, outbox_event RESTART IDENTITY"); err != nil {
+			t.Fatal(err)
+		}
+		return eventStore{&postgres.Postgres{Db: db}}
+	})
+}
+
+// eventStore lets the suite read the outbox, which the service only reads
+// through webhook deliveries.
+type eventStore struct {
+	*postgres.Postgres
+}
+
+func (s eventStore) OutboxEventTypes(ctx context.Context) ([]string, error) {
+	rows, err := s.Db.QueryContext(ctx, "SELECT event_type FROM outbox_event ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []string{}
+	for rows.Next() {
+		var eventType string
+		if err := rows.Scan(&eventType); err != nil {
+			return nil, err
+		}
+		types = append(types, eventType)
+	}
+	return types, rows.Err()
+}
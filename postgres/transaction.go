@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+)
+
+// Ledger entry types, matching the transaction_type enum.
+const (
+	TransactionCreate      = "create"
+	TransactionDeposit     = "deposit"
+	TransactionWithdrawal  = "withdrawal"
+	TransactionTransferIn  = "transfer_in"
+	TransactionTransferOut = "transfer_out"
+	TransactionAdjustment  = "adjustment"
+)
+
+// Transaction is an immutable ledger entry. Amount is signed: credits are
+// positive and debits negative, so the sum of a wallet's entries is its balance.
+type Transaction struct {
+	ID                   int64       `postgres:"id"`
+	WalletID             int         `postgres:"wallet_id"`
+	Type                 string      `postgres:"type"`
+	Amount               money.Money `postgres:"amount"`
+	BalanceAfter         money.Money `postgres:"balance_after"`
+	Currency             string      `postgres:"currency"`
+	CounterpartyWalletID int         `postgres:"counterparty_wallet_id"`
+	FXRate               string      `postgres:"fx_rate"`
+	FXSpread             string      `postgres:"fx_spread"`
+	FXQuoteID            string      `postgres:"fx_quote_id"`
+	Reason               string      `postgres:"reason"`
+	CreatedAt            time.Time   `postgres:"created_at"`
+}
+
+func insertTransaction(ctx context.Context, tx *sql.Tx, t *Transaction) error {
+	counterparty := sql.NullInt64{Int64: int64(t.CounterpartyWalletID), Valid: t.CounterpartyWalletID != 0}
+	fxRate := sql.NullString{String: t.FXRate, Valid: t.FXRate != ""}
+	fxSpread := sql.NullString{String: t.FXSpread, Valid: t.FXSpread != ""}
+	fxQuoteID := sql.NullString{String: t.FXQuoteID, Valid: t.FXQuoteID != ""}
+	reason := sql.NullString{String: t.Reason, Valid: t.Reason != ""}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, currency, counterparty_wallet_id, fx_rate, fx_spread, fx_quote_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`,
+		t.WalletID, t.Type, t.Amount, t.BalanceAfter, t.Currency, counterparty, fxRate, fxSpread, fxQuoteID, reason)
+
+	return row.Scan(&t.ID, &t.CreatedAt)
+}
+
+func (p *Postgres) FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) ([]Transaction, error) {
+
+	stmt, err := p.Db.PrepareContext(ctx, `SELECT id, wallet_id, type, amount, balance_after, currency, counterparty_wallet_id, fx_rate, fx_spread, fx_quote_id, reason, created_at
+		FROM wallet_transaction
+		WHERE wallet_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`)
+
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, walletId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []Transaction
+	for rows.Next() {
+		var t Transaction
+		var counterparty sql.NullInt64
+		var fxRate, fxSpread, fxQuoteID, reason sql.NullString
+		err := rows.Scan(&t.ID, &t.WalletID, &t.Type,
+			&t.Amount, &t.BalanceAfter, &t.Currency,
+			&counterparty, &fxRate, &fxSpread, &fxQuoteID, &reason, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		t.Amount = t.Amount.WithCurrency(t.Currency)
+		t.BalanceAfter = t.BalanceAfter.WithCurrency(t.Currency)
+		t.CounterpartyWalletID = int(counterparty.Int64)
+		t.FXRate = fxRate.String
+		t.FXSpread = fxSpread.String
+		t.FXQuoteID = fxQuoteID.String
+		t.Reason = reason.String
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// SumTransactionsByWalletId returns the balance derived from the ledger.
+func (p *Postgres) SumTransactionsByWalletId(ctx context.Context, walletId int) (money.Money, error) {
+	row := p.Db.QueryRowContext(ctx, "SELECT COALESCE(SUM(amount), 0) FROM wallet_transaction WHERE wallet_id = $1", walletId)
+
+	var sum money.Money
+	err := row.Scan(&sum)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return sum, nil
+}
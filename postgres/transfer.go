@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+)
+
+type Transfer struct {
+	FromWallet     Wallet
+	ToWallet       Wallet
+	Amount         money.Money
+	CreditedAmount money.Money
+	Quote          *FXQuote
+}
+
+// Transfer moves amount from one wallet to another inside a single transaction.
+// Both rows are locked in id order so two opposite transfers cannot deadlock,
+// and the source may not drop below minBalance. Wallets in different
+// currencies need a quote, which is spent in the same transaction and fixes
+// the amount credited.
+func (p *Postgres) Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *FXQuote, meta AuditMeta) (*Transfer, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+walletColumns+`
+		FROM user_wallet
+		WHERE id IN ($1, $2)
+		ORDER BY id
+		FOR UPDATE`, fromWalletId, toWalletId)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int]Wallet, 2)
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		locked[w.ID] = w
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	from, ok := locked[fromWalletId]
+	if !ok {
+		return nil, fmt.Errorf("wallet %d: %w", fromWalletId, ErrNotFound)
+	}
+	to, ok := locked[toWalletId]
+	if !ok {
+		return nil, fmt.Errorf("wallet %d: %w", toWalletId, ErrNotFound)
+	}
+
+	if err := requireStatus(from, WalletActive); err != nil {
+		return nil, err
+	}
+	if err := requireStatus(to, WalletActive); err != nil {
+		return nil, err
+	}
+
+	fromBefore, toBefore := from, to
+
+	credited := amount
+	var fxRate, fxSpread, fxQuoteID string
+	if quote == nil {
+		if from.Currency != to.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+	} else {
+		if from.Currency != quote.FromCurrency || to.Currency != quote.ToCurrency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		if err := useFXQuote(ctx, tx, quote.ID); err != nil {
+			return nil, err
+		}
+		credited = quote.ConvertedAmount
+		fxRate, fxSpread, fxQuoteID = quote.Rate, quote.Spread, quote.ID
+	}
+
+	from.Balance, from.Version, err = debit(ctx, tx, from.ID, amount, minBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	to.Balance, to.Version, err = credit(ctx, tx, to.ID, credited)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertTransaction(ctx, tx, &Transaction{
+		WalletID:             from.ID,
+		Type:                 TransactionTransferOut,
+		Amount:               amount.Neg(),
+		BalanceAfter:         from.Balance,
+		Currency:             from.Currency,
+		CounterpartyWalletID: to.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertTransaction(ctx, tx, &Transaction{
+		WalletID:             to.ID,
+		Type:                 TransactionTransferIn,
+		Amount:               credited,
+		BalanceAfter:         to.Balance,
+		Currency:             to.Currency,
+		CounterpartyWalletID: from.ID,
+		FXRate:               fxRate,
+		FXSpread:             fxSpread,
+		FXQuoteID:            fxQuoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertAuditEvent(ctx, tx, meta, AuditWalletTransferOut, from.ID, &fromBefore, &from); err != nil {
+		return nil, err
+	}
+	if err := insertAuditEvent(ctx, tx, meta, AuditWalletTransferIn, to.ID, &toBefore, &to); err != nil {
+		return nil, err
+	}
+
+	transfer := &Transfer{FromWallet: from, ToWallet: to, Amount: amount, CreditedAmount: credited, Quote: quote}
+
+	if err := insertTransferEvent(ctx, tx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	p.logger().DebugContext(ctx, "transfer committed", "from_wallet_id", fromWalletId, "to_wallet_id", toWalletId, "amount", amount.String(), "fx_quote_id", fxQuoteID)
+	return transfer, nil
+}
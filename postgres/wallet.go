@@ -1,58 +1,125 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
 	_ "github.com/lib/pq"
 )
 
 type Wallet struct {
-	ID         int       `postgres:"id"`
-	UserID     int       `postgres:"user_id"`
-	UserName   string    `postgres:"user_name"`
-	WalletName string    `postgres:"wallet_name"`
-	WalletType string    `postgres:"wallet_type"`
-	Balance    float64   `postgres:"balance"`
-	CreatedAt  time.Time `postgres:"created_at"`
+	ID         int         `postgres:"id"`
+	UserID     int         `postgres:"user_id"`
+	UserName   string      `postgres:"user_name"`
+	WalletName string      `postgres:"wallet_name"`
+	WalletType string      `postgres:"wallet_type"`
+	Balance    money.Money `postgres:"balance"`
+	Currency   string      `postgres:"currency"`
+	Status     string      `postgres:"status"`
+	Version    int         `postgres:"version"`
+	CreatedAt  time.Time   `postgres:"created_at"`
+}
+
+// Wallet statuses. A frozen wallet keeps its data but takes no deposits,
+// withdrawals or transfers; a closed wallet takes nothing and is hidden from
+// listings.
+const (
+	WalletActive = "active"
+	WalletFrozen = "frozen"
+	WalletClosed = "closed"
+)
+
+// walletColumns lists the user_wallet columns in the order scanWallet reads them.
+const walletColumns = "id, user_id, user_name, wallet_name, wallet_type, balance, currency, status, version, created_at"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWallet reads one walletColumns row and tags the balance with the
+// wallet's currency.
+func scanWallet(row rowScanner) (Wallet, error) {
+	var w Wallet
+	err := row.Scan(&w.ID,
+		&w.UserID, &w.UserName,
+		&w.WalletName, &w.WalletType,
+		&w.Balance, &w.Currency, &w.Status, &w.Version, &w.CreatedAt,
+	)
+	w.Balance = w.Balance.WithCurrency(w.Currency)
+	return w, err
 }
 
 
 type Storer interface {
-	FindAll() ([]Wallet, error)
+	FindAll(ctx context.Context) ([]Wallet, error)
+	
+	ListWallets(ctx context.Context, filter WalletFilter) ([]Wallet, error)
+	
+	FindByWalletType(ctx context.Context, walletType string) ([]Wallet, error)
+	
+	FindByWalletId(ctx context.Context, walletID int) (*Wallet, error)
+	
+	FindByUserId(ctx context.Context, userId int) ([]Wallet, error)
 	
-	FindByWalletType(walletType string) ([]Wallet, error)
+	Create(ctx context.Context, wallet *Wallet, meta AuditMeta) (*Wallet, error)
 	
-	FindByWalletId(walletID int) (*Wallet, error)
+	CountByCriteria(ctx context.Context, criteria Wallet) (int, error)
 	
-	FindByUserId(userId int) ([]Wallet, error)
+	DeleteByUserId(ctx context.Context, userId string, meta AuditMeta) (int64, error)
 	
-	Create(wallet *Wallet) (*Wallet, error)
+	DeleteByWalletId(ctx context.Context, walletId int, meta AuditMeta) (*Wallet, error)
 	
-	CountByCriteria(criteria Wallet) (int, error)
+	UpdateByWalletId(ctx context.Context, walletId int, version int, wallet Wallet, meta AuditMeta) (int64, error)
 	
-	DeleteByUserId(userId string) (int64, error)
+	SetStatus(ctx context.Context, walletId int, version int, status string, meta AuditMeta) (*Wallet, error)
 	
-	UpdateByWalletId(walletId int,wallet Wallet)(int64,error)
+	Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *FXQuote, meta AuditMeta) (*Transfer, error)
+	
+	Deposit(ctx context.Context, walletId int, amount money.Money, meta AuditMeta) (*BalanceChange, error)
+	
+	Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta AuditMeta) (*BalanceChange, error)
+	
+	Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta AuditMeta) (*BalanceChange, error)
+	
+	FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) ([]Transaction, error)
+	
+	SumTransactionsByWalletId(ctx context.Context, walletId int) (money.Money, error)
+	
+	CreateFXQuote(ctx context.Context, quote *FXQuote) (*FXQuote, error)
+	
+	FindFXQuote(ctx context.Context, id string) (*FXQuote, error)
 }
 
 type Postgres struct {
-	Db *sql.DB
+	Db     *sql.DB
+	Logger *slog.Logger
+}
+
+// logger is p.Logger, or the default logger for a Postgres built without
+// New.
+func (p *Postgres) logger() *slog.Logger {
+	if p.Logger == nil {
+		return slog.Default()
+	}
+	return p.Logger
 }
 
-func (p *Postgres) FindByWalletType(walletType string) ([]Wallet, error) {
+func (p *Postgres) FindByWalletType(ctx context.Context, walletType string) ([]Wallet, error) {
 	
-	stmt , err := p.Db.Prepare("SELECT * FROM user_wallet WHERE wallet_type = $1")
+	stmt , err := p.Db.PrepareContext(ctx, "SELECT " + walletColumns + " FROM user_wallet WHERE wallet_type = $1 AND status <> 'closed'")
 	
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 	
-	rows, err := stmt.Query(walletType)
+	rows, err := stmt.QueryContext(ctx, walletType)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +127,7 @@ func (p *Postgres) FindByWalletType(walletType string) ([]Wallet, error) {
 
 	var wallets []Wallet
 	for rows.Next() {
-		var w Wallet
-		err := rows.Scan(&w.ID,
-			&w.UserID, &w.UserName,
-			&w.WalletName, &w.WalletType,
-			&w.Balance, &w.CreatedAt,
-		)
+		w, err := scanWallet(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -74,8 +136,8 @@ func (p *Postgres) FindByWalletType(walletType string) ([]Wallet, error) {
 	return wallets, nil
 }
 
-func (p *Postgres) FindAll() ([]Wallet, error) {
-	rows, err := p.Db.Query("SELECT * FROM user_wallet")
+func (p *Postgres) FindAll(ctx context.Context) ([]Wallet, error) {
+	rows, err := p.Db.QueryContext(ctx, "SELECT " + walletColumns + " FROM user_wallet WHERE status <> 'closed'")
 	if err != nil {
 		return nil, err
 	}
@@ -83,12 +145,7 @@ func (p *Postgres) FindAll() ([]Wallet, error) {
 
 	var wallets []Wallet
 	for rows.Next() {
-		var w Wallet
-		err := rows.Scan(&w.ID,
-			&w.UserID, &w.UserName,
-			&w.WalletName, &w.WalletType,
-			&w.Balance, &w.CreatedAt,
-		)
+		w, err := scanWallet(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -97,16 +154,16 @@ func (p *Postgres) FindAll() ([]Wallet, error) {
 	return wallets, nil
 }
 
-func (p *Postgres) FindByUserId(userId int) ([]Wallet, error) {
+func (p *Postgres) FindByUserId(ctx context.Context, userId int) ([]Wallet, error) {
 	
-	stmt , err := p.Db.Prepare("SELECT * FROM user_wallet WHERE user_id = $1")
+	stmt , err := p.Db.PrepareContext(ctx, "SELECT " + walletColumns + " FROM user_wallet WHERE user_id = $1 AND status <> 'closed'")
 	
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userId)
+	rows, err := stmt.QueryContext(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -114,12 +171,7 @@ func (p *Postgres) FindByUserId(userId int) ([]Wallet, error) {
 
 	var wallets []Wallet
 	for rows.Next() {
-		var w Wallet
-		err := rows.Scan(&w.ID,
-			&w.UserID, &w.UserName,
-			&w.WalletName, &w.WalletType,
-			&w.Balance, &w.CreatedAt,
-		)
+		w, err := scanWallet(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -128,23 +180,54 @@ func (p *Postgres) FindByUserId(userId int) ([]Wallet, error) {
 	return wallets, nil
 }
 
-func (p *Postgres) Create(w *Wallet) (*Wallet, error) {
-	row := p.Db.QueryRow("INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance) values ($1, $2, $3, $4, $5) RETURNING id",
+func (p *Postgres) Create(ctx context.Context, w *Wallet, meta AuditMeta) (*Wallet, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, "INSERT INTO user_wallet (user_id, user_name, wallet_name, wallet_type, balance, currency) values ($1, $2, $3, $4, $5, $6) RETURNING id, status, version, created_at",
 		w.UserID,
 		w.UserName,
 		w.WalletName, w.WalletType,
-		w.Balance)
+		w.Balance, w.Currency)
 		
-	err := row.Scan(&w.ID)
+	err = row.Scan(&w.ID, &w.Status, &w.Version, &w.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	return w, err
+	err = insertTransaction(ctx, tx, &Transaction{
+		WalletID:     w.ID,
+		Type:         TransactionCreate,
+		Amount:       w.Balance,
+		BalanceAfter: w.Balance,
+		Currency:     w.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertAuditEvent(ctx, tx, meta, AuditWalletCreate, w.ID, nil, w)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertWalletEvent(ctx, tx, EventWalletCreated, w)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
 }
 
 
-func (p *Postgres) CountByCriteria(criteria Wallet) (int, error) {
+func (p *Postgres) CountByCriteria(ctx context.Context, criteria Wallet) (int, error) {
 	query := "SELECT count(id) FROM user_wallet WHERE "
 	var args []interface{}
 
@@ -165,7 +248,7 @@ func (p *Postgres) CountByCriteria(criteria Wallet) (int, error) {
 
 	query += sb.String()
 
-	row := p.Db.QueryRow(query, args...)
+	row := p.Db.QueryRowContext(ctx, query, args...)
 
 	var count int
 	err := row.Scan(&count)
@@ -177,46 +260,75 @@ func (p *Postgres) CountByCriteria(criteria Wallet) (int, error) {
 
 }
 
-func (p *Postgres) DeleteByUserId(userId string) (int64, error) {
-
-	sql := "DELETE FROM user_wallet WHERE user_id= $1"
-	
-	stmt , err := p.Db.Prepare(sql)
-	
-	
+// DeleteByUserId closes every open wallet of userId. Rows are kept, so the
+// data stays available to compliance; nothing is closed if any of the
+// wallets still holds money or is frozen.
+func (p *Postgres) DeleteByUserId(ctx context.Context, userId string, meta AuditMeta) (int64, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
-
-	res, err := stmt.Exec(sql,userId)
-	
-	//Exec(sql, userId)
+	defer tx.Rollback()
 
+	rows, err := tx.QueryContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE user_id = $1 AND status <> 'closed' ORDER BY id FOR UPDATE", userId)
 	if err != nil {
 		return 0, err
 	}
 
-	deletedRows, err := res.RowsAffected()
+	var open []Wallet
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		open = append(open, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, w := range open {
+		if err := requireStatus(w, WalletActive); err != nil {
+			return 0, err
+		}
+		if !w.Balance.IsZero() {
+			return 0, fmt.Errorf("wallet %d: %w", w.ID, ErrBalanceNotZero)
+		}
+	}
 
-	if err != nil {
+	for i := range open {
+		after, err := setStatus(ctx, tx, open[i], WalletClosed)
+		if err != nil {
+			return 0, err
+		}
+		if err := insertAuditEvent(ctx, tx, meta, AuditWalletDelete, after.ID, &open[i], &after); err != nil {
+			return 0, err
+		}
+		if err := insertWalletEvent(ctx, tx, EventWalletDeleted, &after); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
 		return 0, err
 	}
 
-	return deletedRows, nil
+	return int64(len(open)), nil
 }
 
 
-func (p *Postgres) FindByWalletId(walletID int) (*Wallet, error) {
+func (p *Postgres) FindByWalletId(ctx context.Context, walletID int) (*Wallet, error) {
     // Prepare the SQL query with a placeholder for the wallet ID
     query := `
-        SELECT id, user_id, user_name, wallet_name, wallet_type, balance, created_at 
+        SELECT ` + walletColumns + `
         FROM user_wallet 
         WHERE id = $1 
         LIMIT 1
     `
 
-	stmt , err :=  p.Db.Prepare(query)
+	stmt , err :=  p.Db.PrepareContext(ctx, query)
 
 	if err !=nil {
 		return nil, err
@@ -224,17 +336,14 @@ func (p *Postgres) FindByWalletId(walletID int) (*Wallet, error) {
 	defer stmt.Close()
 
     // Execute the query using the QueryRow method of the DB object
-    row := stmt.QueryRow(query, walletID)
-
-    // Initialize a Wallet struct
-    var wallet Wallet
+    row := stmt.QueryRowContext(ctx, walletID)
 
     // Scan the values returned by the query into the fields of the wallet struct
-    err = row.Scan(&wallet.ID, &wallet.UserID, &wallet.UserName, &wallet.WalletName, &wallet.WalletType, &wallet.Balance, &wallet.CreatedAt)
+    wallet, err := scanWallet(row)
     if err != nil {
         // If no rows are returned, check for sql.ErrNoRows error
         if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("wallet not found for ID: %d", walletID)
+            return nil, fmt.Errorf("wallet %d: %w", walletID, ErrNotFound)
         }
         // Otherwise, return any other error
         return nil, err
@@ -246,7 +355,11 @@ func (p *Postgres) FindByWalletId(walletID int) (*Wallet, error) {
 
 
 
-func (p *Postgres) UpdateByWalletId(walletId int, wallet Wallet) (int64, error) {
+// UpdateByWalletId only applies when the row is still at version, so two
+// editors working from the same read cannot overwrite each other. Any write
+// bumps the version. The balance is never set here; it only moves with a
+// ledger entry. Only active wallets can be changed.
+func (p *Postgres) UpdateByWalletId(ctx context.Context, walletId int, version int, wallet Wallet, meta AuditMeta) (int64, error) {
     var updates []string
     var args []interface{}
 
@@ -271,23 +384,36 @@ func (p *Postgres) UpdateByWalletId(walletId int, wallet Wallet) (int64, error)
         args = append(args, wallet.WalletType)
     }
 
-    if wallet.Balance >= 0 {
-        updates = append(updates, fmt.Sprintf("balance = $%d", len(args)+1))
-        args = append(args, wallet.Balance)
-    }
+    updates = append(updates, "version = version + 1")
 
     // Construct the query string
-    query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", "user_wallet", 
-                         strings.Join(updates, ", "), len(args)+1)
-    args = append(args, walletId)
+    query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND version = $%d", "user_wallet", 
+                         strings.Join(updates, ", "), len(args)+1, len(args)+2)
+    args = append(args, walletId, version)
 
-	// fmt.Println(query) 
-	// fmt.Println(args)
-	
-	//p.Db.Prepare
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Lock the row so the audit event sees the wallet we are replacing
+	before, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = $1 FOR UPDATE", walletId))
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if before.Version != version {
+		return 0, ErrVersionConflict
+	}
+	if err := requireStatus(before, WalletActive); err != nil {
+		return 0, err
+	}
 
 	// Execute the query
-    res, err := p.Db.Exec(query, args...)
+    res, err := tx.ExecContext(ctx, query, args...)
     
 	if err != nil {
         return 0, err
@@ -299,6 +425,25 @@ func (p *Postgres) UpdateByWalletId(walletId int, wallet Wallet) (int64, error)
         return 0, err
     }
 
+	after, err := scanWallet(tx.QueryRowContext(ctx, "SELECT "+walletColumns+" FROM user_wallet WHERE id = $1", walletId))
+	if err != nil {
+		return 0, err
+	}
+
+	err = insertAuditEvent(ctx, tx, meta, AuditWalletUpdate, walletId, &before, &after)
+	if err != nil {
+		return 0, err
+	}
+
+	err = insertWalletEvent(ctx, tx, EventWalletUpdated, &after)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
     return numRows, nil
 
 }
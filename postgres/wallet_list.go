@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+)
+
+// Sort keys accepted by ListWallets. Each maps to an indexed column that is
+// paired with id, so every row has a unique position to resume after.
+const (
+	SortCreatedAt  = "created_at"
+	SortBalance    = "balance"
+	SortWalletName = "wallet_name"
+)
+
+var walletSortColumns = map[string]string{
+	SortCreatedAt:  "created_at",
+	SortBalance:    "balance",
+	SortWalletName: "wallet_name",
+}
+
+func IsWalletSort(sort string) bool {
+	_, ok := walletSortColumns[sort]
+	return ok
+}
+
+// WalletCursor is the position of the last wallet of a page: its sort key
+// and id.
+type WalletCursor struct {
+	ID         int
+	CreatedAt  time.Time
+	Balance    money.Money
+	WalletName string
+}
+
+// CursorFor returns the position of w in a listing sorted by sort.
+func CursorFor(w Wallet, sort string) WalletCursor {
+	c := WalletCursor{ID: w.ID}
+	switch sort {
+	case SortBalance:
+		c.Balance = w.Balance
+	case SortWalletName:
+		c.WalletName = w.WalletName
+	default:
+		c.CreatedAt = w.CreatedAt
+	}
+	return c
+}
+
+func (c WalletCursor) value(sort string) interface{} {
+	switch sort {
+	case SortBalance:
+		return c.Balance
+	case SortWalletName:
+		return c.WalletName
+	}
+	return c.CreatedAt
+}
+
+// WalletFilter narrows and orders ListWallets. Zero values and nil pointers
+// are not filtered on, except Status: without one closed wallets are left out.
+type WalletFilter struct {
+	WalletType  string
+	Status      string
+	UserID      int
+	MinBalance  *money.Money
+	MaxBalance  *money.Money
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Descending  bool
+	After       *WalletCursor
+	Limit       int
+}
+
+// ListWallets returns one page of wallets using a keyset query: rather than
+// an OFFSET it resumes strictly after filter.After, so the cost of a page
+// does not grow with how deep into the listing it is.
+func (p *Postgres) ListWallets(ctx context.Context, filter WalletFilter) ([]Wallet, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.WalletType != "" {
+		conditions = append(conditions, "wallet_type = "+arg(filter.WalletType))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(filter.Status))
+	} else {
+		conditions = append(conditions, "status <> "+arg(WalletClosed))
+	}
+	if filter.UserID != 0 {
+		conditions = append(conditions, "user_id = "+arg(filter.UserID))
+	}
+	if filter.MinBalance != nil {
+		conditions = append(conditions, "balance >= "+arg(*filter.MinBalance))
+	}
+	if filter.MaxBalance != nil {
+		conditions = append(conditions, "balance <= "+arg(*filter.MaxBalance))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedTo))
+	}
+
+	column, ok := walletSortColumns[filter.Sort]
+	if !ok {
+		column = walletSortColumns[SortCreatedAt]
+	}
+
+	direction, compare := "ASC", ">"
+	if filter.Descending {
+		direction, compare = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)",
+			column, compare, arg(filter.After.value(filter.Sort)), arg(filter.After.ID)))
+	}
+
+	query := "SELECT " + walletColumns + " FROM user_wallet"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", column, direction, direction, arg(filter.Limit))
+
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []Wallet
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, rows.Err()
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook delivery statuses. A delivery is retried while pending and becomes
+// dead once it runs out of attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook is a subscription to outbox events. EventTypes empty means every
+// event. Secret signs the deliveries, so unlike an API key it is kept as is.
+type Webhook struct {
+	ID         int       `postgres:"id"`
+	URL        string    `postgres:"url"`
+	Secret     string    `postgres:"secret"`
+	EventTypes []string  `postgres:"event_types"`
+	Active     bool      `postgres:"active"`
+	CreatedAt  time.Time `postgres:"created_at"`
+}
+
+// WebhookDelivery is one event on its way to one subscription. Claimed
+// deliveries carry the event and the subscription's URL and secret.
+type WebhookDelivery struct {
+	ID             int64      `postgres:"id"`
+	EventID        int64      `postgres:"event_id"`
+	SubscriptionID int        `postgres:"subscription_id"`
+	Status         string     `postgres:"status"`
+	Attempts       int        `postgres:"attempts"`
+	NextAttemptAt  time.Time  `postgres:"next_attempt_at"`
+	LastError      string     `postgres:"last_error"`
+	DeliveredAt    *time.Time `postgres:"delivered_at"`
+	CreatedAt      time.Time  `postgres:"created_at"`
+
+	Event  OutboxEvent
+	URL    string
+	Secret string
+}
+
+type WebhookStorer interface {
+	CreateWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error)
+
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+
+	DeleteWebhook(ctx context.Context, id int) error
+
+	ListDeliveries(ctx context.Context, subscriptionId int, status string, limit int) ([]WebhookDelivery, error)
+
+	// RetryDelivery puts a delivery back in the queue with a fresh set of
+	// attempts, whatever its status.
+	RetryDelivery(ctx context.Context, subscriptionId int, id int64) (*WebhookDelivery, error)
+
+	// ClaimDeliveries hands out up to limit pending deliveries that are due
+	// and hides them from other dispatchers for lease, so a dispatcher that
+	// dies mid-delivery only delays them.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+
+	CompleteDelivery(ctx context.Context, id int64) error
+
+	// FailDelivery records a failed attempt. With a retryAt the delivery is
+	// tried again then; without one it is dead.
+	FailDelivery(ctx context.Context, id int64, lastError string, retryAt *time.Time) error
+}
+
+const webhookColumns = "id, url, secret, event_types, active, created_at"
+
+func scanWebhook(row rowScanner) (Webhook, error) {
+	var w Webhook
+	var eventTypes string
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &eventTypes, &w.Active, &w.CreatedAt)
+	w.EventTypes = strings.Fields(eventTypes)
+	return w, err
+}
+
+const deliveryColumns = "d.id, d.event_id, d.subscription_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, e.event_type, e.payload, e.created_at"
+
+// scanDelivery reads one deliveryColumns row followed by the columns in
+// extra, which are scanned into d.
+func scanDelivery(row rowScanner, extra ...func(d *WebhookDelivery) interface{}) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+	var payload []byte
+	dest := []interface{}{&d.ID, &d.EventID, &d.SubscriptionID, &d.Status, &d.Attempts, &d.NextAttemptAt, &lastError, &deliveredAt, &d.CreatedAt,
+		&d.Event.EventType, &payload, &d.Event.CreatedAt}
+	for _, field := range extra {
+		dest = append(dest, field(&d))
+	}
+	err := row.Scan(dest...)
+	if err != nil {
+		return d, err
+	}
+	d.Event.ID = d.EventID
+	d.Event.Payload = json.RawMessage(payload)
+	d.LastError = lastError.String
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+func (p *Postgres) CreateWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error) {
+	w, err := scanWebhook(p.Db.QueryRowContext(ctx, `INSERT INTO webhook_subscription (url, secret, event_types)
+		VALUES ($1, $2, $3) RETURNING `+webhookColumns,
+		webhook.URL, webhook.Secret, strings.Join(webhook.EventTypes, " ")))
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (p *Postgres) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := p.Db.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhook_subscription WHERE active ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook deactivates a subscription. Its deliveries are kept for
+// inspection but no longer sent.
+func (p *Postgres) DeleteWebhook(ctx context.Context, id int) error {
+	res, err := p.Db.ExecContext(ctx, "UPDATE webhook_subscription SET active = false WHERE id = $1 AND active", id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("webhook %d: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *Postgres) ListDeliveries(ctx context.Context, subscriptionId int, status string, limit int) ([]WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_delivery d JOIN outbox_event e ON e.id = d.event_id WHERE d.subscription_id = $1"
+	args := []interface{}{subscriptionId}
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" AND d.status = $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY d.id DESC LIMIT $%d", len(args))
+
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (p *Postgres) RetryDelivery(ctx context.Context, subscriptionId int, id int64) (*WebhookDelivery, error) {
+	d, err := scanDelivery(p.Db.QueryRowContext(ctx, `WITH d AS (
+			UPDATE webhook_delivery SET status = 'pending', attempts = 0, next_attempt_at = now()
+			WHERE id = $1 AND subscription_id = $2 RETURNING *
+		)
+		SELECT `+deliveryColumns+` FROM d JOIN outbox_event e ON e.id = d.event_id`, id, subscriptionId))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook delivery %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (p *Postgres) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	rows, err := p.Db.QueryContext(ctx, `WITH d AS (
+			UPDATE webhook_delivery SET next_attempt_at = now() + $2 * interval '1 millisecond'
+			WHERE id IN (
+				SELECT id FROM webhook_delivery
+				WHERE status = 'pending' AND next_attempt_at <= now()
+				ORDER BY next_attempt_at, id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+`, s.url, s.secret
+		FROM d JOIN outbox_event e ON e.id = d.event_id JOIN webhook_subscription s ON s.id = d.subscription_id
+		ORDER BY d.id`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	url := func(d *WebhookDelivery) interface{} { return &d.URL }
+	secret := func(d *WebhookDelivery) interface{} { return &d.Secret }
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows, url, secret)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (p *Postgres) CompleteDelivery(ctx context.Context, id int64) error {
+	_, err := p.Db.ExecContext(ctx, `UPDATE webhook_delivery
+		SET status = 'delivered', attempts = attempts + 1, delivered_at = now(), last_error = NULL
+		WHERE id = $1`, id)
+	return err
+}
+
+func (p *Postgres) FailDelivery(ctx context.Context, id int64, lastError string, retryAt *time.Time) error {
+	if retryAt == nil {
+		_, err := p.Db.ExecContext(ctx, `UPDATE webhook_delivery SET status = 'dead', attempts = attempts + 1, last_error = $1 WHERE id = $2`,
+			lastError, id)
+		return err
+	}
+	_, err := p.Db.ExecContext(ctx, `UPDATE webhook_delivery SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`,
+		lastError, *retryAt, id)
+	return err
+}
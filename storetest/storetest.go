@@ -0,0 +1,539 @@
+// Package storetest is the conformance suite for postgres.Storer. Every
+// storage backend runs it from its own tests, so they all behave like the
+// Postgres store the services were written against.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EventStore is a Storer that also records every change in the audit log
+// and the webhook outbox, in the change's transaction. OutboxEventTypes
+// lists the outbox oldest first. Stores that are not an EventStore only run
+// with the audit log and webhooks off, which config.Validate enforces.
+type EventStore interface {
+	postgres.Storer
+	postgres.AuditStorer
+	OutboxEventTypes(ctx context.Context) ([]string, error)
+}
+
+// Run tests the Storer returned by open. open is called once per test and
+// must return a store with no wallets, ledger entries, quotes or events in
+// it.
+func Run(t *testing.T, open func(t *testing.T) postgres.Storer) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s postgres.Storer)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"FindExcludesClosed", testFindExcludesClosed},
+		{"CountByCriteria", testCountByCriteria},
+		{"UpdateByWalletId", testUpdateByWalletId},
+		{"BalanceChanges", testBalanceChanges},
+		{"SetStatus", testSetStatus},
+		{"DeleteByWalletId", testDeleteByWalletId},
+		{"DeleteByUserId", testDeleteByUserId},
+		{"Transfer", testTransfer},
+		{"TransferWithQuote", testTransferWithQuote},
+		{"ListWallets", testListWallets},
+		{"FindTransactions", testFindTransactions},
+		{"AuditAndOutbox", testAuditAndOutbox},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, open(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+var meta = postgres.AuditMeta{Actor: "1", RequestID: "storetest", ClientIP: "127.0.0.1"}
+
+func thb(s string) money.Money {
+	return money.MustParse(s).WithCurrency("THB")
+}
+
+func create(t *testing.T, s postgres.Storer, userId int, name string, walletType string, balance money.Money) postgres.Wallet {
+	t.Helper()
+	w, err := s.Create(ctx, &postgres.Wallet{
+		UserID:     userId,
+		UserName:   "User " + name,
+		WalletName: name,
+		WalletType: walletType,
+		Balance:    balance,
+		Currency:   balance.Currency(),
+	}, meta)
+	require.NoError(t, err)
+	return *w
+}
+
+func find(t *testing.T, s postgres.Storer, walletId int) postgres.Wallet {
+	t.Helper()
+	w, err := s.FindByWalletId(ctx, walletId)
+	require.NoError(t, err)
+	return *w
+}
+
+// assertBalance checks the stored balance and that the ledger adds up to it.
+func assertBalance(t *testing.T, s postgres.Storer, walletId int, want string) {
+	t.Helper()
+	w := find(t, s, walletId)
+	assertAmount(t, want, w.Balance)
+
+	sum, err := s.SumTransactionsByWalletId(ctx, walletId)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sum.Cmp(w.Balance), "ledger of wallet %d sums to %s", walletId, sum)
+}
+
+func assertAmount(t *testing.T, want string, got money.Money) {
+	t.Helper()
+	assert.True(t, money.MustParse(want).Cmp(got) == 0, "want %s, got %s", want, got)
+}
+
+func ids(wallets []postgres.Wallet) []int {
+	ids := []int{}
+	for _, w := range wallets {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
+
+func testCreateAndFind(t *testing.T, s postgres.Storer) {
+	created := create(t, s, 1, "savings", "Savings", thb("100.50"))
+
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, postgres.WalletActive, created.Status)
+	assert.Equal(t, 1, created.Version)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	got := find(t, s, created.ID)
+	assert.Equal(t, created.UserID, got.UserID)
+	assert.Equal(t, "User savings", got.UserName)
+	assert.Equal(t, "savings", got.WalletName)
+	assert.Equal(t, "Savings", got.WalletType)
+	assert.Equal(t, "THB", got.Currency)
+	assert.Equal(t, "THB", got.Balance.Currency())
+	assertBalance(t, s, created.ID, "100.5")
+
+	transactions, err := s.FindTransactionsByWalletId(ctx, created.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, postgres.TransactionCreate, transactions[0].Type)
+
+	_, err = s.FindByWalletId(ctx, created.ID+1000)
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+}
+
+func testFindExcludesClosed(t *testing.T, s postgres.Storer) {
+	open := create(t, s, 1, "open", "Savings", thb("10"))
+	closed := create(t, s, 1, "closed", "Savings", thb("0"))
+	other := create(t, s, 2, "other", "Credit Card", thb("10"))
+	_, err := s.DeleteByWalletId(ctx, closed.ID, meta)
+	require.NoError(t, err)
+
+	all, err := s.FindAll(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{open.ID, other.ID}, ids(all))
+
+	byType, err := s.FindByWalletType(ctx, "Savings")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{open.ID}, ids(byType))
+
+	byUser, err := s.FindByUserId(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{open.ID}, ids(byUser))
+
+	// A closed wallet can still be read by id
+	assert.Equal(t, postgres.WalletClosed, find(t, s, closed.ID).Status)
+}
+
+func testCountByCriteria(t *testing.T, s postgres.Storer) {
+	create(t, s, 1, "savings", "Savings", thb("10"))
+	create(t, s, 1, "card", "Credit Card", thb("10"))
+	create(t, s, 2, "savings", "Savings", thb("10"))
+
+	count, err := s.CountByCriteria(ctx, postgres.Wallet{UserID: 1, WalletType: "Savings"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = s.CountByCriteria(ctx, postgres.Wallet{WalletName: "savings"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = s.CountByCriteria(ctx, postgres.Wallet{UserID: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func testUpdateByWalletId(t *testing.T, s postgres.Storer) {
+	w := create(t, s, 1, "savings", "Savings", thb("100"))
+
+	// Zero fields and the balance are left alone
+	n, err := s.UpdateByWalletId(ctx, w.ID, w.Version, postgres.Wallet{WalletName: "renamed", Balance: thb("75.25")}, meta)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	got := find(t, s, w.ID)
+	assert.Equal(t, "renamed", got.WalletName)
+	assert.Equal(t, "User savings", got.UserName)
+	assert.Equal(t, "Savings", got.WalletType)
+	assert.Equal(t, w.Version+1, got.Version)
+	assertBalance(t, s, w.ID, "100")
+
+	transactions, err := s.FindTransactionsByWalletId(ctx, w.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, postgres.TransactionCreate, transactions[0].Type)
+
+	_, err = s.UpdateByWalletId(ctx, w.ID, w.Version, postgres.Wallet{WalletName: "stale"}, meta)
+	assert.True(t, errors.Is(err, postgres.ErrVersionConflict), "got %v", err)
+	assert.Equal(t, "renamed", find(t, s, w.ID).WalletName)
+
+	n, err = s.UpdateByWalletId(ctx, w.ID+1000, 1, postgres.Wallet{WalletName: "missing"}, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// Frozen wallets keep their owner and details
+	current := find(t, s, w.ID)
+	_, err = s.SetStatus(ctx, w.ID, current.Version, postgres.WalletFrozen, meta)
+	require.NoError(t, err)
+	current = find(t, s, w.ID)
+	_, err = s.UpdateByWalletId(ctx, w.ID, current.Version, postgres.Wallet{UserID: 2, WalletName: "frozen"}, meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+	got = find(t, s, w.ID)
+	assert.Equal(t, 1, got.UserID)
+	assert.Equal(t, "renamed", got.WalletName)
+	assert.Equal(t, current.Version, got.Version)
+}
+
+func testBalanceChanges(t *testing.T, s postgres.Storer) {
+	w := create(t, s, 1, "savings", "Savings", thb("100"))
+
+	change, err := s.Deposit(ctx, w.ID, thb("50.5"), meta)
+	require.NoError(t, err)
+	assertAmount(t, "150.5", change.Wallet.Balance)
+	assert.Equal(t, w.Version+1, change.Wallet.Version)
+	assert.Equal(t, postgres.TransactionDeposit, change.Transaction.Type)
+	assertAmount(t, "150.5", change.Transaction.BalanceAfter)
+
+	change, err = s.Withdraw(ctx, w.ID, thb("160"), thb("-20"), meta)
+	require.NoError(t, err)
+	assertAmount(t, "-9.5", change.Wallet.Balance)
+	assertAmount(t, "-160", change.Transaction.Amount)
+
+	_, err = s.Withdraw(ctx, w.ID, thb("20"), thb("-20"), meta)
+	assert.True(t, errors.Is(err, postgres.ErrInsufficientFunds), "got %v", err)
+	assertBalance(t, s, w.ID, "-9.5")
+
+	change, err = s.Adjust(ctx, w.ID, thb("9.5"), "correction", meta)
+	require.NoError(t, err)
+	assert.Equal(t, "correction", change.Transaction.Reason)
+	assertBalance(t, s, w.ID, "0")
+
+	_, err = s.Deposit(ctx, w.ID+1000, thb("1"), meta)
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+
+	// Frozen wallets only take adjustments
+	current := find(t, s, w.ID)
+	_, err = s.SetStatus(ctx, w.ID, current.Version, postgres.WalletFrozen, meta)
+	require.NoError(t, err)
+
+	_, err = s.Deposit(ctx, w.ID, thb("1"), meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+	_, err = s.Withdraw(ctx, w.ID, thb("1"), thb("0"), meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+	_, err = s.Adjust(ctx, w.ID, thb("1"), "correction", meta)
+	assert.NoError(t, err)
+	assertBalance(t, s, w.ID, "1")
+}
+
+func testSetStatus(t *testing.T, s postgres.Storer) {
+	w := create(t, s, 1, "savings", "Savings", thb("10"))
+
+	frozen, err := s.SetStatus(ctx, w.ID, w.Version, postgres.WalletFrozen, meta)
+	require.NoError(t, err)
+	assert.Equal(t, postgres.WalletFrozen, frozen.Status)
+	assert.Equal(t, w.Version+1, frozen.Version)
+	assertAmount(t, "10", frozen.Balance)
+
+	_, err = s.SetStatus(ctx, w.ID, w.Version, postgres.WalletActive, meta)
+	assert.True(t, errors.Is(err, postgres.ErrVersionConflict), "got %v", err)
+	assert.Equal(t, postgres.WalletFrozen, find(t, s, w.ID).Status)
+
+	_, err = s.SetStatus(ctx, w.ID+1000, 1, postgres.WalletFrozen, meta)
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+}
+
+func testDeleteByWalletId(t *testing.T, s postgres.Storer) {
+	funded := create(t, s, 1, "funded", "Savings", thb("10"))
+	empty := create(t, s, 1, "empty", "Savings", thb("0"))
+
+	_, err := s.DeleteByWalletId(ctx, funded.ID, meta)
+	assert.True(t, errors.Is(err, postgres.ErrBalanceNotZero), "got %v", err)
+
+	deleted, err := s.DeleteByWalletId(ctx, empty.ID, meta)
+	require.NoError(t, err)
+	assert.Equal(t, postgres.WalletClosed, deleted.Status)
+	assert.Equal(t, empty.Version+1, deleted.Version)
+
+	_, err = s.DeleteByWalletId(ctx, empty.ID, meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+
+	_, err = s.DeleteByWalletId(ctx, empty.ID+1000, meta)
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+}
+
+func testDeleteByUserId(t *testing.T, s postgres.Storer) {
+	first := create(t, s, 1, "first", "Savings", thb("0"))
+	second := create(t, s, 1, "second", "Credit Card", thb("5"))
+	other := create(t, s, 2, "other", "Savings", thb("0"))
+
+	// Nothing is closed while one wallet still holds money
+	_, err := s.DeleteByUserId(ctx, "1", meta)
+	assert.True(t, errors.Is(err, postgres.ErrBalanceNotZero), "got %v", err)
+	assert.Equal(t, postgres.WalletActive, find(t, s, first.ID).Status)
+
+	_, err = s.Withdraw(ctx, second.ID, thb("5"), thb("0"), meta)
+	require.NoError(t, err)
+
+	n, err := s.DeleteByUserId(ctx, "1", meta)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, postgres.WalletClosed, find(t, s, first.ID).Status)
+	assert.Equal(t, postgres.WalletClosed, find(t, s, second.ID).Status)
+	assert.Equal(t, postgres.WalletActive, find(t, s, other.ID).Status)
+
+	n, err = s.DeleteByUserId(ctx, "1", meta)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// A frozen wallet blocks the delete too
+	_, err = s.SetStatus(ctx, other.ID, other.Version, postgres.WalletFrozen, meta)
+	require.NoError(t, err)
+	_, err = s.DeleteByUserId(ctx, "2", meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+}
+
+func testTransfer(t *testing.T, s postgres.Storer) {
+	from := create(t, s, 1, "from", "Savings", thb("100"))
+	to := create(t, s, 2, "to", "Savings", thb("5"))
+	usd := create(t, s, 2, "usd", "Savings", money.MustParse("5").WithCurrency("USD"))
+
+	transfer, err := s.Transfer(ctx, from.ID, to.ID, thb("30"), thb("0"), nil, meta)
+	require.NoError(t, err)
+	assertAmount(t, "70", transfer.FromWallet.Balance)
+	assertAmount(t, "35", transfer.ToWallet.Balance)
+	assertAmount(t, "30", transfer.CreditedAmount)
+	assertBalance(t, s, from.ID, "70")
+	assertBalance(t, s, to.ID, "35")
+
+	out, err := s.FindTransactionsByWalletId(ctx, from.ID, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, postgres.TransactionTransferOut, out[0].Type)
+	assert.Equal(t, to.ID, out[0].CounterpartyWalletID)
+
+	// A failed transfer changes neither wallet
+	_, err = s.Transfer(ctx, from.ID, to.ID, thb("71"), thb("0"), nil, meta)
+	assert.True(t, errors.Is(err, postgres.ErrInsufficientFunds), "got %v", err)
+	assertBalance(t, s, from.ID, "70")
+	assertBalance(t, s, to.ID, "35")
+
+	_, err = s.Transfer(ctx, from.ID, usd.ID, thb("1"), thb("0"), nil, meta)
+	assert.True(t, errors.Is(err, money.ErrCurrencyMismatch), "got %v", err)
+
+	_, err = s.Transfer(ctx, from.ID, to.ID+1000, thb("1"), thb("0"), nil, meta)
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+
+	current := find(t, s, to.ID)
+	_, err = s.SetStatus(ctx, to.ID, current.Version, postgres.WalletFrozen, meta)
+	require.NoError(t, err)
+	_, err = s.Transfer(ctx, from.ID, to.ID, thb("1"), thb("0"), nil, meta)
+	assert.True(t, errors.Is(err, postgres.ErrWalletInactive), "got %v", err)
+	assertBalance(t, s, from.ID, "70")
+}
+
+func testTransferWithQuote(t *testing.T, s postgres.Storer) {
+	from := create(t, s, 1, "thb", "Savings", thb("1000"))
+	to := create(t, s, 2, "usd", "Savings", money.MustParse("0").WithCurrency("USD"))
+
+	quote, err := s.CreateFXQuote(ctx, &postgres.FXQuote{
+		ID:              "q1",
+		FromCurrency:    "THB",
+		ToCurrency:      "USD",
+		Amount:          thb("365"),
+		ConvertedAmount: money.MustParse("9.95").WithCurrency("USD"),
+		Rate:            "0.0274",
+		Spread:          "0.005",
+		ExpiresAt:       time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	found, err := s.FindFXQuote(ctx, "q1")
+	require.NoError(t, err)
+	assertAmount(t, "9.95", found.ConvertedAmount)
+	assert.Equal(t, "USD", found.ConvertedAmount.Currency())
+	assert.Nil(t, found.UsedAt)
+
+	transfer, err := s.Transfer(ctx, from.ID, to.ID, thb("365"), thb("0"), quote, meta)
+	require.NoError(t, err)
+	assertAmount(t, "9.95", transfer.CreditedAmount)
+	assertBalance(t, s, from.ID, "635")
+	assertBalance(t, s, to.ID, "9.95")
+
+	in, err := s.FindTransactionsByWalletId(ctx, to.ID, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, in, 1)
+	assert.Equal(t, "q1", in[0].FXQuoteID)
+
+	found, err = s.FindFXQuote(ctx, "q1")
+	require.NoError(t, err)
+	assert.NotNil(t, found.UsedAt)
+
+	// A quote pays out once
+	_, err = s.Transfer(ctx, from.ID, to.ID, thb("365"), thb("0"), quote, meta)
+	assert.True(t, errors.Is(err, postgres.ErrQuoteExpired), "got %v", err)
+
+	expired, err := s.CreateFXQuote(ctx, &postgres.FXQuote{
+		ID:              "q2",
+		FromCurrency:    "THB",
+		ToCurrency:      "USD",
+		Amount:          thb("365"),
+		ConvertedAmount: money.MustParse("9.95").WithCurrency("USD"),
+		Rate:            "0.0274",
+		Spread:          "0.005",
+		ExpiresAt:       time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = s.Transfer(ctx, from.ID, to.ID, thb("365"), thb("0"), expired, meta)
+	assert.True(t, errors.Is(err, postgres.ErrQuoteExpired), "got %v", err)
+	assertBalance(t, s, from.ID, "635")
+
+	_, err = s.FindFXQuote(ctx, "missing")
+	assert.True(t, errors.Is(err, postgres.ErrNotFound), "got %v", err)
+}
+
+func testListWallets(t *testing.T, s postgres.Storer) {
+	a := create(t, s, 1, "alpha", "Savings", thb("30"))
+	b := create(t, s, 1, "bravo", "Savings", thb("10"))
+	c := create(t, s, 2, "charlie", "Credit Card", thb("20"))
+	d := create(t, s, 2, "delta", "Savings", thb("10"))
+	e := create(t, s, 3, "echo", "Savings", thb("0"))
+	_, err := s.DeleteByWalletId(ctx, e.ID, meta)
+	require.NoError(t, err)
+
+	list := func(filter postgres.WalletFilter) []int {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+		wallets, err := s.ListWallets(ctx, filter)
+		require.NoError(t, err)
+		return ids(wallets)
+	}
+
+	assert.Equal(t, []int{a.ID, b.ID, c.ID, d.ID}, list(postgres.WalletFilter{Sort: postgres.SortWalletName}))
+	assert.Equal(t, []int{d.ID, c.ID, b.ID, a.ID}, list(postgres.WalletFilter{Sort: postgres.SortWalletName, Descending: true}))
+	assert.Equal(t, []int{e.ID}, list(postgres.WalletFilter{Status: postgres.WalletClosed}))
+	assert.Equal(t, []int{b.ID, d.ID}, list(postgres.WalletFilter{WalletType: "Savings", Sort: postgres.SortBalance, MaxBalance: ptr(thb("10"))}))
+	assert.Equal(t, []int{a.ID, c.ID}, list(postgres.WalletFilter{Sort: postgres.SortBalance, Descending: true, MinBalance: ptr(thb("20"))}))
+	assert.Equal(t, []int{c.ID, d.ID}, list(postgres.WalletFilter{UserID: 2, Sort: postgres.SortWalletName}))
+
+	// Paging by balance resumes after ties in id order
+	var pages [][]int
+	filter := postgres.WalletFilter{Sort: postgres.SortBalance, Limit: 2}
+	for {
+		wallets, err := s.ListWallets(ctx, filter)
+		require.NoError(t, err)
+		if len(wallets) == 0 {
+			break
+		}
+		pages = append(pages, ids(wallets))
+		cursor := postgres.CursorFor(wallets[len(wallets)-1], filter.Sort)
+		filter.After = &cursor
+	}
+	assert.Equal(t, [][]int{{b.ID, d.ID}, {c.ID, a.ID}}, pages)
+
+	// created_from is inclusive and created_to exclusive
+	created := find(t, s, a.ID).CreatedAt
+	assert.Contains(t, list(postgres.WalletFilter{CreatedFrom: &created}), a.ID)
+	assert.NotContains(t, list(postgres.WalletFilter{CreatedTo: &created}), a.ID)
+}
+
+func testFindTransactions(t *testing.T, s postgres.Storer) {
+	w := create(t, s, 1, "savings", "Savings", thb("0"))
+	other := create(t, s, 2, "other", "Savings", thb("0"))
+	for _, amount := range []string{"1", "2", "3"} {
+		_, err := s.Deposit(ctx, w.ID, thb(amount), meta)
+		require.NoError(t, err)
+		_, err = s.Deposit(ctx, other.ID, thb(amount), meta)
+		require.NoError(t, err)
+	}
+
+	transactions, err := s.FindTransactionsByWalletId(ctx, w.ID, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assertAmount(t, "2", transactions[0].Amount)
+	assertAmount(t, "1", transactions[1].Amount)
+	for _, tr := range transactions {
+		assert.Equal(t, w.ID, tr.WalletID)
+		assert.Equal(t, "THB", tr.Currency)
+	}
+
+	transactions, err = s.FindTransactionsByWalletId(ctx, w.ID, 10, 4)
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func testAuditAndOutbox(t *testing.T, s postgres.Storer) {
+	events, ok := s.(EventStore)
+	if !ok {
+		t.Skip("store records no audit or outbox events")
+	}
+
+	from := create(t, s, 1, "from", "Savings", thb("100"))
+	to := create(t, s, 2, "to", "Savings", thb("0"))
+	_, err := s.Deposit(ctx, from.ID, thb("10"), meta)
+	require.NoError(t, err)
+	_, err = s.Transfer(ctx, from.ID, to.ID, thb("30"), thb("0"), nil, meta)
+	require.NoError(t, err)
+
+	// A refused change records nothing
+	_, err = s.Withdraw(ctx, to.ID, thb("31"), thb("0"), meta)
+	require.Error(t, err)
+
+	actions := func(walletId int) []string {
+		t.Helper()
+		list, err := events.ListAuditEvents(ctx, postgres.AuditFilter{WalletID: walletId, Limit: 10})
+		require.NoError(t, err)
+		actions := []string{}
+		for _, e := range list {
+			actions = append(actions, e.Action)
+			assert.Equal(t, meta.Actor, e.Actor)
+			assert.Equal(t, meta.RequestID, e.RequestID)
+			assert.Equal(t, meta.ClientIP, e.ClientIP)
+			assert.NotEmpty(t, e.After)
+		}
+		return actions
+	}
+	assert.Equal(t, []string{postgres.AuditWalletTransferOut, postgres.AuditWalletDeposit, postgres.AuditWalletCreate}, actions(from.ID))
+	assert.Equal(t, []string{postgres.AuditWalletTransferIn, postgres.AuditWalletCreate}, actions(to.ID))
+
+	types, err := events.OutboxEventTypes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{postgres.EventWalletCreated, postgres.EventWalletCreated, postgres.EventWalletUpdated, postgres.EventTransferCompleted}, types)
+}
+
+func ptr(m money.Money) *money.Money {
+	return &m
+}
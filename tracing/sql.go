@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+var (
+	// stringLiteral is a quoted SQL string, with '' as an escaped quote.
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+	// numberLiteral is a number that is not part of a name or a $n
+	// placeholder.
+	numberLiteral = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?\b`)
+)
+
+// SanitizeQuery replaces the literals in query with ?, so a statement
+// written with values inline still logs none of them, and puts it on one
+// line. Placeholder arguments are never recorded.
+func SanitizeQuery(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numberLiteral.ReplaceAllString(query, "${1}?")
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// OpenDB opens a database like sql.Open, with a span for every statement
+// that records its sanitized text. system names the database, such as
+// semconv.DBSystemPostgreSQL.
+func OpenDB(driverName string, dataSourceName string, system attribute.KeyValue) (*sql.DB, error) {
+	return otelsql.Open(driverName, dataSourceName,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableQuery:         true,
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+		otelsql.WithSpanNameFormatter(spanName),
+		otelsql.WithAttributesGetter(func(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) []attribute.KeyValue {
+			if query == "" {
+				return nil
+			}
+			return []attribute.KeyValue{semconv.DBStatement(SanitizeQuery(query))}
+		}),
+	)
+}
+
+// spanName names a statement's span after its first keyword, such as
+// "sql SELECT", and other calls after the driver method.
+func spanName(ctx context.Context, method otelsql.Method, query string) string {
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return "sql " + strings.ToUpper(fields[0])
+	}
+	return string(method)
+}
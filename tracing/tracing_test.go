@@ -0,0 +1,121 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/config"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+)
+
+// record makes the global tracer provider keep every span for the test.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	if _, err := tracing.Setup(context.Background(), config.Tracing{}); err != nil {
+		t.Fatal(err)
+	}
+	return recorder
+}
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"placeholders are kept", "SELECT id FROM user_wallet\n\t\tWHERE id = $1 AND version = $2", "SELECT id FROM user_wallet WHERE id = $1 AND version = $2"},
+		{"strings are replaced", "UPDATE user_wallet SET status = 'frozen' WHERE wallet_name = 'Bob''s'", "UPDATE user_wallet SET status = ? WHERE wallet_name = ?"},
+		{"numbers are replaced", "SELECT * FROM fx_rate WHERE rate > 36.5 LIMIT 10", "SELECT * FROM fx_rate WHERE rate > ? LIMIT ?"},
+		{"names with digits are kept", "SELECT pg_advisory_lock(7235145001) FROM t1", "SELECT pg_advisory_lock(?) FROM t1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tracing.SanitizeQuery(tt.query))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := record(t)
+
+	e := echo.New()
+	e.Use(tracing.Middleware())
+	e.Use(apperrs.CustomErrorMiddleware)
+	e.GET("/wallets/:id", func(c echo.Context) error {
+		_, span := tracing.Start(c.Request().Context(), "child")
+		span.End()
+		return apperrs.NewInternalServerError("Get wallet failed")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	t.Run("should continue the caller's trace", func(t *testing.T) {
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 2) {
+			child, server := spans[0], spans[1]
+			assert.Equal(t, "GET /wallets/:id", server.Name())
+			assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+			assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+			assert.Contains(t, server.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+			assert.Equal(t, codes.Error, server.Status().Code)
+		}
+	})
+
+	t.Run("should return the trace in the response", func(t *testing.T) {
+		assert.Contains(t, rec.Header().Get("traceparent"), traceID)
+
+		var body apperrs.CustomError
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, traceID, body.TraceID)
+	})
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"given no error should leave the status unset", nil, codes.Unset},
+		{"given a client error should leave the status unset", apperrs.NewBadRequestError("Amount must be greater than 0"), codes.Unset},
+		{"given a server error should fail the span", apperrs.NewInternalServerError("Transfer failed"), codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record(t)
+
+			_, span := tracing.Start(context.Background(), "call")
+			err := tt.err
+			tracing.End(span, &err)
+
+			spans := recorder.Ended()
+			if assert.Len(t, spans, 1) {
+				assert.Equal(t, tt.want, spans[0].Status().Code)
+				assert.Equal(t, tt.err != nil, len(spans[0].Events()) == 1, "the error should be recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/labstack/echo/v4"
+)
+
+// principal returns the caller that auth.Middleware put in the context.
+func principal(c echo.Context) (*auth.Principal, error) {
+	p, ok := auth.FromContext(c)
+	if !ok {
+		return nil, apperrs.NewUnauthorizedError("missing credentials")
+	}
+	return p, nil
+}
+
+// authorizeUser checks permission on the wallets of userId.
+func authorizeUser(c echo.Context, permission string, userId int) error {
+	p, err := principal(c)
+	if err != nil {
+		return err
+	}
+	if !p.Allowed(permission, userId) {
+		return auth.Forbidden(permission)
+	}
+	return nil
+}
+
+// authorizeOwner checks permission on walletId, owned by userId. A caller
+// that may not even read the wallet gets the 404 of a missing one, so
+// wallet ids cannot be probed.
+func authorizeOwner(c echo.Context, permission string, walletId int, userId int) error {
+	p, err := principal(c)
+	if err != nil {
+		return err
+	}
+	if p.Allowed(permission, userId) {
+		return nil
+	}
+	if !p.Allowed(auth.PermWalletRead, userId) {
+		return apperrs.NewNotFoundError(fmt.Sprintf("wallet %d: %s", walletId, postgres.ErrNotFound))
+	}
+	return auth.Forbidden(permission)
+}
+
+// authorizeWallet checks permission on walletId, looking up its owner
+// unless a role already grants the permission on every wallet.
+func (h *Handler) authorizeWallet(c echo.Context, permission string, walletId int) error {
+	p, err := principal(c)
+	if err != nil {
+		return err
+	}
+	if p.Granted(permission) {
+		return nil
+	}
+
+	w, err := h.service.GetWalletById(c.Request().Context(), walletId)
+	if err != nil {
+		return err
+	}
+	return authorizeOwner(c, permission, walletId, w.UserID)
+}
+
+// ClientIPExtractor finds the client IP recorded in the audit log. With no
+// trusted proxies it is the address of the connection, so a caller cannot
+// name its own IP in X-Forwarded-For or X-Real-IP; otherwise X-Forwarded-For
+// is followed back through the trusted ranges only.
+func ClientIPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, r := range trustedProxies {
+		options = append(options, echo.TrustIPRange(r))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// auditMeta describes who made the request, for the audit log.
+func auditMeta(c echo.Context) postgres.AuditMeta {
+	meta := postgres.AuditMeta{ClientIP: c.RealIP()}
+	if p, ok := auth.FromContext(c); ok {
+		meta.Actor = p.Subject
+	}
+	meta.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	if meta.RequestID == "" {
+		meta.RequestID = c.Request().Header.Get(echo.HeaderXRequestID)
+	}
+	return meta
+}
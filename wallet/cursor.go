@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorToken is what an opaque next_cursor decodes to. The sort it was
+// issued for is kept so it cannot be replayed against a different order.
+type cursorToken struct {
+	Sort  string `json:"s"`
+	ID    int    `json:"id"`
+	Value string `json:"v"`
+}
+
+func encodeCursor(sort string, c postgres.WalletCursor) string {
+	token := cursorToken{Sort: sort, ID: c.ID}
+	switch sortKey(sort) {
+	case postgres.SortBalance:
+		token.Value = c.Balance.String()
+	case postgres.SortWalletName:
+		token.Value = c.WalletName
+	default:
+		token.Value = c.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	b, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(sort string, cursor string) (*postgres.WalletCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(b, &token); err != nil || token.Sort != sort {
+		return nil, errInvalidCursor
+	}
+
+	c := postgres.WalletCursor{ID: token.ID}
+	switch sortKey(sort) {
+	case postgres.SortBalance:
+		c.Balance, err = money.Parse(token.Value)
+	case postgres.SortWalletName:
+		c.WalletName = token.Value
+	default:
+		c.CreatedAt, err = time.Parse(time.RFC3339Nano, token.Value)
+	}
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// sortKey strips the "-" that marks a descending sort.
+func sortKey(sort string) string {
+	if len(sort) > 0 && sort[0] == '-' {
+		return sort[1:]
+	}
+	return sort
+}
@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// fxQuoteTTL is how long a quoted rate can be used for a transfer.
+const fxQuoteTTL = 60 * time.Second
+
+// FXRateProvider supplies the rate and spread for converting one currency
+// into another. *postgres.Postgres implements it from the fx_rate table.
+type FXRateProvider interface {
+	Rate(ctx context.Context, from string, to string) (*postgres.FXRate, error)
+}
+
+// effectiveRate is the rate a customer gets: the mid-market rate less the
+// spread, rate * (1 - spread).
+func effectiveRate(r *postgres.FXRate) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid fx rate %q for %s/%s", r.Rate, r.From, r.To)
+	}
+
+	spread, ok := new(big.Rat).SetString(r.Spread)
+	if !ok || spread.Sign() < 0 || spread.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, fmt.Errorf("invalid fx spread %q for %s/%s", r.Spread, r.From, r.To)
+	}
+
+	return rate.Mul(rate, new(big.Rat).Sub(big.NewRat(1, 1), spread)), nil
+}
+
+func newQuoteID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// checkFXQuote makes sure a quote was issued for this transfer and can
+// still be used. The store checks expiry and use again under lock.
+func checkFXQuote(quote *postgres.FXQuote, from *postgres.Wallet, to *postgres.Wallet, amount money.Money) error {
+	var err error
+	switch {
+	case quote.FromCurrency != from.Currency || quote.ToCurrency != to.Currency:
+		err = fmt.Errorf("fx quote is for %s to %s, not %s to %s", quote.FromCurrency, quote.ToCurrency, from.Currency, to.Currency)
+	case quote.Amount.Cmp(amount) != 0:
+		err = fmt.Errorf("fx quote is for an amount of %s", quote.Amount)
+	case quote.UsedAt != nil || !time.Now().Before(quote.ExpiresAt):
+		err = postgres.ErrQuoteExpired
+	}
+	if err != nil {
+		return apperrs.NewUnprocessableEntity(err.Error())
+	}
+	return nil
+}
+
+func toFXQuoteResponse(q *postgres.FXQuote) *FXQuote {
+	return &FXQuote{
+		ID:              q.ID,
+		FromCurrency:    q.FromCurrency,
+		ToCurrency:      q.ToCurrency,
+		Amount:          q.Amount,
+		ConvertedAmount: q.ConvertedAmount,
+		Rate:            q.Rate,
+		Spread:          q.Spread,
+		ExpiresAt:       q.ExpiresAt,
+	}
+}
@@ -1,20 +1,26 @@
 package wallet
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 	"github.com/labstack/echo/v4"
 )
 
 type Handler struct {
 	service Service
+	logger  *slog.Logger
 }
 
-func NewHandler(service Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service Service, logger *slog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
 }
 
 type Err struct {
@@ -24,36 +30,128 @@ type Err struct {
 // WalletHandler
 //
 //	@Summary		Get all wallets
-//	@Description	Get all wallets
+//	@Description	Get a page of wallets; pass next_cursor back as cursor to get the following page
 //	@Tags			wallet
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	Wallet
+//	@Param			wallet_type		query	string	false	"wallet type"
+//	@Param			status			query	string	false	"active, frozen or closed (default: all but closed)"
+//	@Param			user_id			query	int		false	"owner user id"
+//	@Param			min_balance		query	number	false	"lowest balance, inclusive"
+//	@Param			max_balance		query	number	false	"highest balance, inclusive"
+//	@Param			created_from	query	string	false	"RFC 3339 time, inclusive"
+//	@Param			created_to		query	string	false	"RFC 3339 time, exclusive"
+//	@Param			sort			query	string	false	"created_at, balance or wallet_name; prefix with - for descending (default created_at)"
+//	@Param			limit			query	int		false	"page size (default 20, max 100)"
+//	@Param			cursor			query	string	false	"next_cursor of the previous page"
+//	@Success		200	{object}	WalletPage
 //	@Router			/api/v1/wallets [get]
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Failure		500	{object}	Err
+//	@Failure		403	{object}	Err
+//	@Failure		401	{object}	Err
+//	@Failure		400	{object}	Err
 func (h *Handler) WalletHandler(c echo.Context) error {
 
-	walletType := c.QueryParam("wallet_type")
+	query := &WalletQuery{
+		WalletType: c.QueryParam("wallet_type"),
+		Status:     c.QueryParam("status"),
+		Sort:       c.QueryParam("sort"),
+		Cursor:     c.QueryParam("cursor"),
+	}
+	if query.Sort == "" {
+		query.Sort = "created_at"
+	}
 
-	var wallets []Wallet
 	var err error
 
-	if walletType != "" {
-		wallets, err = h.service.GetWalletsByWalletType(walletType)
-	} else {
-		wallets, err = h.service.GetAllWallets()
+	if query.UserID, err = queryParamInt(c, "user_id", 0); err != nil {
+		return apperrs.NewBadRequestError("invalid user_id")
+	}
+	if query.Limit, err = queryParamInt(c, "limit", defaultPageLimit); err != nil {
+		return apperrs.NewBadRequestError("invalid limit")
+	}
+	if query.MinBalance, err = queryParamMoney(c, "min_balance"); err != nil {
+		return apperrs.NewBadRequestError("invalid min_balance")
+	}
+	if query.MaxBalance, err = queryParamMoney(c, "max_balance"); err != nil {
+		return apperrs.NewBadRequestError("invalid max_balance")
+	}
+	if query.CreatedFrom, err = queryParamTime(c, "created_from"); err != nil {
+		return apperrs.NewBadRequestError("invalid created_from")
+	}
+	if query.CreatedTo, err = queryParamTime(c, "created_to"); err != nil {
+		return apperrs.NewBadRequestError("invalid created_to")
 	}
 
+	p, err := principal(c)
 	if err != nil {
 		return err
 	}
+	if !p.Granted(auth.PermWalletRead) {
+		if query.UserID != 0 && query.UserID != p.UserID {
+			return auth.Forbidden(auth.PermWalletRead)
+		}
+		query.UserID = p.UserID
+	}
 
-	return c.JSON(http.StatusOK, wallets)
+	page, err := h.service.ListWallets(c.Request().Context(), query)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
 
 }
 
 
 
+// GetWallet
+//	@Summary		Get wallet
+//	@Description	Get a wallet by id; the ETag is its version, for use in If-Match
+//	@Tags			wallet
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	Wallet
+//	@Header			200	{string}	ETag	"version of the wallet"
+//	@Router			/api/v1/wallets/{id} [get]
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path	string	true	"wallet id"
+//	@Failure		500	{object}	apperrs.CustomError
+//	@Failure		403	{object}	apperrs.CustomError
+//	@Failure		401	{object}	apperrs.CustomError
+//	@Failure		404	{object}	apperrs.CustomError
+//	@Failure		400	{object}	apperrs.CustomError
+func (h *Handler) GetWalletHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	if _, err := principal(c); err != nil {
+		return err
+	}
+
+	wallet, err := h.service.GetWalletById(c.Request().Context(), walletId)
+
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeOwner(c, auth.PermWalletRead, walletId, wallet.UserID); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", etag(wallet.Version))
+	return c.JSON(http.StatusOK, wallet)
+}
+
+
 // UserHandler
 //	@Summary		Get user wallets
 //	@Description	Get user wallets
@@ -62,19 +160,27 @@ func (h *Handler) WalletHandler(c echo.Context) error {
 //	@Produce		json
 //	@Success		200	{object}	Wallet
 //	@Router			/api/v1/users/{id}/wallets [get]
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Param			id	path	string	true	"user id"
 //	@Failure		500	{object}	apperrs.CustomError
+//	@Failure		403	{object}	apperrs.CustomError
+//	@Failure		401	{object}	apperrs.CustomError
 //	@Failure		400	{object}	apperrs.CustomError
 func (h *Handler) WalletByUserIdHandler(c echo.Context) error {
 	userId , err := strconv.Atoi(c.Param("id"))
 
-	log.Printf("userId %d",userId)
+	h.logger.DebugContext(c.Request().Context(), "list wallets of user", "user_id", userId)
 
 	if err != nil {
         return apperrs.NewBadRequestError("invalid user ID")
     }
 
-	wallets, err := h.service.GetWalletsByUserId(userId)
+	if err := authorizeUser(c, auth.PermWalletRead, userId); err != nil {
+		return err
+	}
+
+	wallets, err := h.service.GetWalletsByUserId(c.Request().Context(), userId)
 
 	if err != nil {
 		return err
@@ -95,7 +201,12 @@ func (h *Handler) WalletByUserIdHandler(c echo.Context) error {
 // @Param WalletCreateRequest body WalletRequest true "WalletRequest"
 // @Success 201 {object} Wallet
 // @Router /api/v1/wallets/ [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
 // @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
 // @Failure 400 {object} apperrs.CustomError
 func (h *Handler) CreateWalletHandler(c echo.Context) error {
 
@@ -104,7 +215,11 @@ func (h *Handler) CreateWalletHandler(c echo.Context) error {
 		return err
 	}
 
-	wallet, err := h.service.CreateWallet(req)
+	if err := authorizeUser(c, auth.PermWalletWrite, req.UserID); err != nil {
+		return err
+	}
+
+	wallet, err := h.service.CreateWallet(c.Request().Context(), req, auditMeta(c))
 
 	if err != nil {
 		return err
@@ -114,16 +229,62 @@ func (h *Handler) CreateWalletHandler(c echo.Context) error {
 }
 
 
+// DeleteWalletById
+// @Summary Delete wallet
+// @Description Close one wallet; it is kept but hidden. Only an active wallet with a zero balance can be deleted
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Router	/api/v1/wallets/{id} [delete]
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param	id	path	string	true	"wallet id"
+// @Success 200 {object} Wallet
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) DeleteWalletByIdHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletDelete, walletId); err != nil {
+		return err
+	}
+
+	wallet, err := h.service.DeleteWalletById(c.Request().Context(), walletId, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, wallet)
+}
+
+
 // DeleteWallet
 // @Summary Delete user wallets
-// @Description Delete user wallets by user id
+// @Description Close every open wallet of a user; the wallets are kept but hidden. Fails while any of them is frozen or holds money
 // @Tags wallet
 // @Accept json
 // @Produce json
 // @Router	/api/v1/users/{id}/wallets [delete]
+// @Security	BearerAuth
+// @Security	APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
 // @Param	id	path	string	true	"user id"
 // @Success 200 {object} Wallet
 // @Failure 500 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
 // @Failure 400 {object} apperrs.CustomError
 func (h *Handler) DeleteWalletHandler(c echo.Context) error {
 
@@ -133,7 +294,16 @@ func (h *Handler) DeleteWalletHandler(c echo.Context) error {
 		return apperrs.NewBadRequestError("user id required")
 	}
 
-	_, err := h.service.DeleteWalletByUserId(userId)
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid user ID")
+	}
+
+	if err := authorizeUser(c, auth.PermWalletDelete, id); err != nil {
+		return err
+	}
+
+	_, err = h.service.DeleteWalletByUserId(c.Request().Context(), userId, auditMeta(c))
 
 	if err != nil {
 		return err
@@ -154,10 +324,21 @@ func (h *Handler) DeleteWalletHandler(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Router /api/v1/wallets/{id} [put]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param If-Match header string true "ETag of the wallet as last read, or * for any version"
 // @Param	id	path	string	true	"wallet id"
-// @Param WalletCreateRequest body WalletRequest true "WalletRequest"
+// @Param WalletUpdateRequest body WalletUpdateRequest true "WalletUpdateRequest"
 // @Success 200 {object} Wallet
+// @Header 200 {string} ETag "version of the updated wallet"
 // @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 428 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 412 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
 // @Failure 400 {object} apperrs.CustomError
 func (h *Handler) UpdateWalletHandler(c echo.Context) error {
 
@@ -167,18 +348,487 @@ func (h *Handler) UpdateWalletHandler(c echo.Context) error {
         return apperrs.NewBadRequestError("invalid wallet ID")
     }
 
+	ifMatch := c.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		return apperrs.NewPreconditionRequiredError("If-Match header with the wallet ETag is required")
+	}
 
-	req := new(WalletRequest)
+	version, ok := parseETag(ifMatch)
+	if !ok {
+		return apperrs.NewPreconditionFailedError("If-Match does not match the wallet ETag")
+	}
+
+	req := new(WalletUpdateRequest)
 	if err := c.Bind(req); err != nil {
 		return err
 	}
 
-	walletResponse, err := h.service.UpdateWalletByWalletId(walletId, req)
+	existing, err := h.service.GetWalletById(c.Request().Context(), walletId)
 
 	if err != nil {
 		return err
 	}
 
+	if err := authorizeOwner(c, auth.PermWalletWrite, walletId, existing.UserID); err != nil {
+		return err
+	}
+
+	// moving a wallet to another owner needs write access to theirs too
+	if req.UserID != 0 && req.UserID != existing.UserID {
+		if err := authorizeUser(c, auth.PermWalletWrite, req.UserID); err != nil {
+			return err
+		}
+	}
+
+	walletResponse, err := h.service.UpdateWalletByWalletId(c.Request().Context(), walletId, version, req, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", etag(walletResponse.Version))
 	return c.JSON(http.StatusOK, walletResponse)
 
 }
+
+
+// Transfer
+// @Summary Transfer between wallets
+// @Description Debit one wallet and credit another atomically
+// @Tags transfer
+// @Accept json
+// @Produce json
+// @Router /api/v1/transfers [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param TransferRequest body TransferRequest true "TransferRequest"
+// @Success 201 {object} Transfer
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) TransferHandler(c echo.Context) error {
+
+	req := new(TransferRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletTransact, req.FromWalletID); err != nil {
+		return err
+	}
+
+	transfer, err := h.service.Transfer(c.Request().Context(), req, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, transfer)
+}
+
+
+// WalletTransactions
+// @Summary Get wallet transactions
+// @Description Get the ledger entries of a wallet, newest first
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/transactions [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	id	path	string	true	"wallet id"
+// @Param	limit	query	int	false	"page size (default 20, max 100)"
+// @Param	offset	query	int	false	"number of entries to skip"
+// @Success 200 {object} TransactionPage
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) WalletTransactionsHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	limit, err := queryParamInt(c, "limit", defaultPageLimit)
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid limit")
+	}
+
+	offset, err := queryParamInt(c, "offset", 0)
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid offset")
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletRead, walletId); err != nil {
+		return err
+	}
+
+	page, err := h.service.GetTransactionsByWalletId(c.Request().Context(), walletId, limit, offset)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+
+// ReconcileWallet
+// @Summary Reconcile wallet balance
+// @Description Compare the wallet balance against the sum of its ledger entries
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/reconciliation [get]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	id	path	string	true	"wallet id"
+// @Success 200 {object} Reconciliation
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) ReconcileWalletHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletRead, walletId); err != nil {
+		return err
+	}
+
+	reconciliation, err := h.service.ReconcileWallet(c.Request().Context(), walletId)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, reconciliation)
+}
+
+
+// Deposit
+// @Summary Deposit into wallet
+// @Description Credit a wallet by a relative amount
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/deposits [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param	id	path	string	true	"wallet id"
+// @Param BalanceChangeRequest body BalanceChangeRequest true "BalanceChangeRequest"
+// @Success 201 {object} BalanceChange
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) DepositHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	req := new(BalanceChangeRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletTransact, walletId); err != nil {
+		return err
+	}
+
+	change, err := h.service.Deposit(c.Request().Context(), walletId, req, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, change)
+}
+
+
+// Withdrawal
+// @Summary Withdraw from wallet
+// @Description Debit a wallet by a relative amount, within the limits of its wallet type
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/withdrawals [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param	id	path	string	true	"wallet id"
+// @Param BalanceChangeRequest body BalanceChangeRequest true "BalanceChangeRequest"
+// @Success 201 {object} BalanceChange
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) WithdrawalHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	req := new(BalanceChangeRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletTransact, walletId); err != nil {
+		return err
+	}
+
+	change, err := h.service.Withdraw(c.Request().Context(), walletId, req, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, change)
+}
+
+
+// Adjustment
+// @Summary Adjust wallet balance
+// @Description Correct a balance by a signed amount with a reason; needs the wallet:adjust permission
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/adjustments [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param Idempotency-Key header string false "key to make retries of this request safe"
+// @Param	id	path	string	true	"wallet id"
+// @Param AdjustmentRequest body AdjustmentRequest true "AdjustmentRequest"
+// @Success 201 {object} BalanceChange
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) AdjustmentHandler(c echo.Context) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	req := new(AdjustmentRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := h.authorizeWallet(c, auth.PermWalletAdjust, walletId); err != nil {
+		return err
+	}
+
+	change, err := h.service.Adjust(c.Request().Context(), walletId, req, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, change)
+}
+
+
+// FreezeWallet
+// @Summary Freeze wallet
+// @Description Stop deposits, withdrawals and transfers on an active wallet without touching its data; needs the wallet:freeze permission
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/freeze [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	id	path	string	true	"wallet id"
+// @Success 200 {object} Wallet
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 409 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) FreezeWalletHandler(c echo.Context) error {
+	return h.changeStatus(c, auth.PermWalletFreeze, postgres.WalletFrozen)
+}
+
+// UnfreezeWallet
+// @Summary Unfreeze wallet
+// @Description Make a frozen wallet active again; needs the wallet:freeze permission
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/unfreeze [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	id	path	string	true	"wallet id"
+// @Success 200 {object} Wallet
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 409 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) UnfreezeWalletHandler(c echo.Context) error {
+	return h.changeStatus(c, auth.PermWalletFreeze, postgres.WalletActive)
+}
+
+// CloseWallet
+// @Summary Close wallet
+// @Description Close an active wallet for good; its balance must be zero. Closed wallets are hidden from listings
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Router /api/v1/wallets/{id}/close [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param	id	path	string	true	"wallet id"
+// @Success 200 {object} Wallet
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 409 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) CloseWalletHandler(c echo.Context) error {
+	return h.changeStatus(c, auth.PermWalletDelete, postgres.WalletClosed)
+}
+
+func (h *Handler) changeStatus(c echo.Context, permission string, status string) error {
+
+	walletId, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid wallet ID")
+	}
+
+	if err := h.authorizeWallet(c, permission, walletId); err != nil {
+		return err
+	}
+
+	wallet, err := h.service.ChangeWalletStatus(c.Request().Context(), walletId, status, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, wallet)
+}
+
+
+func queryParamInt(c echo.Context, name string, defaultValue int) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func queryParamMoney(c echo.Context, name string) (*money.Money, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	m, err := money.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func queryParamTime(c echo.Context, name string) (*time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// etag formats a wallet version as a strong entity tag.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseETag reads the version back from an If-Match value. A weak tag is
+// accepted since the version identifies the whole wallet either way, and *
+// matches any version.
+func parseETag(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return AnyVersion, true
+	}
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+
+// FXQuote
+// @Summary Quote a currency conversion
+// @Description Fix the rate for converting an amount; pass the quote id to a transfer before it expires
+// @Tags fx
+// @Accept json
+// @Produce json
+// @Router /api/v1/fx/quotes [post]
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Param FXQuoteRequest body FXQuoteRequest true "FXQuoteRequest"
+// @Success 201 {object} FXQuote
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 422 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) FXQuoteHandler(c echo.Context) error {
+
+	req := new(FXQuoteRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	quote, err := h.service.CreateFXQuote(c.Request().Context(), req)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, quote)
+}
@@ -3,63 +3,178 @@ package wallet
 import (
 	"bytes"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/auth"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestWalletHandler(t *testing.T) {
 	mockService := new(MockService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, testLogger)
 
 	mockWallets := []Wallet{
-		{ID: 1, UserID: 1, UserName: "User1", WalletName: "Wallet1", WalletType: "Type1", Balance: 100.0},
-		{ID: 2, UserID: 2, UserName: "User2", WalletName: "Wallet2", WalletType: "Type2", Balance: 200.0},
+		{ID: 1, UserID: 1, UserName: "User1", WalletName: "Wallet1", WalletType: "Type1", Balance: money.MustParse("100.0")},
+		{ID: 2, UserID: 2, UserName: "User2", WalletName: "Wallet2", WalletType: "Type2", Balance: money.MustParse("200.0")},
 	}
 
-	mockService.On("GetAllWallets").Return(mockWallets, nil)
+	mockService.On("ListWallets", mock.Anything, &WalletQuery{Sort: "created_at", Limit: 20}).Return(&WalletPage{Data: mockWallets, NextCursor: "abc"}, nil)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	asAdmin(c)
 
 	if assert.NoError(t, handler.WalletHandler(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 
-		var responseWallets []Wallet
-		err := json.Unmarshal(rec.Body.Bytes(), &responseWallets)
+		var responsePage WalletPage
+		err := json.Unmarshal(rec.Body.Bytes(), &responsePage)
 		assert.NoError(t, err)
 
-		assert.Equal(t, mockWallets, responseWallets)
+		assert.Equal(t, mockWallets, responsePage.Data)
+		assert.Equal(t, "abc", responsePage.NextCursor)
 	}
 
 	mockService.AssertExpectations(t)
 }
 
+func TestWalletHandlerQuery(t *testing.T) {
+	t.Run("given filters should pass them to service", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		minBalance := money.MustParse("10")
+		maxBalance := money.MustParse("500.5")
+		createdFrom := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		expected := &WalletQuery{
+			WalletType:  "Savings",
+			UserID:      7,
+			MinBalance:  &minBalance,
+			MaxBalance:  &maxBalance,
+			CreatedFrom: &createdFrom,
+			Sort:        "-balance",
+			Limit:       5,
+			Cursor:      "abc",
+		}
+		mockService.On("ListWallets", mock.Anything, expected).Return(&WalletPage{Data: []Wallet{}}, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets?wallet_type=Savings&user_id=7&min_balance=10&max_balance=500.5&created_from=2024-03-01T00:00:00Z&sort=-balance&limit=5&cursor=abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+
+		if assert.NoError(t, handler.WalletHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"data":[]}`, rec.Body.String())
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given malformed filter should return 400", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets?created_to=yesterday", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+
+		err := handler.WalletHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockService.AssertNotCalled(t, "ListWallets")
+	})
+}
+
+func TestGetWalletHandler(t *testing.T) {
+	t.Run("given existing wallet should return it with ETag", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		mockWallet := Wallet{ID: 7, UserID: 1, WalletType: "Savings", Balance: money.MustParse("100.00"), Currency: "THB", Version: 5}
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&mockWallet, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/7", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		if assert.NoError(t, handler.GetWalletHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+
+			var responseWallet Wallet
+			err := json.Unmarshal(rec.Body.Bytes(), &responseWallet)
+			assert.NoError(t, err)
+			assert.Equal(t, mockWallet.ID, responseWallet.ID)
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given unknown wallet should return 404", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		mockService.On("GetWalletById", mock.Anything, 7).Return((*Wallet)(nil), apperrs.NewNotFoundError("wallet 7: not found"))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/7", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		err := handler.GetWalletHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
 func TestWalletByUserIdHandler(t *testing.T) {
 	mockService := new(MockService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, testLogger)
 
 	userID := "123"
 	mockUserID, _ := strconv.Atoi(userID)
 
 	mockWallets := []Wallet{
-		{ID: 1, UserID: mockUserID, UserName: "User1", WalletName: "Wallet1", WalletType: "Type1", Balance: 100.0},
-		{ID: 2, UserID: mockUserID, UserName: "User2", WalletName: "Wallet2", WalletType: "Type2", Balance: 200.0},
+		{ID: 1, UserID: mockUserID, UserName: "User1", WalletName: "Wallet1", WalletType: "Type1", Balance: money.MustParse("100.0")},
+		{ID: 2, UserID: mockUserID, UserName: "User2", WalletName: "Wallet2", WalletType: "Type2", Balance: money.MustParse("200.0")},
 	}
 
-	mockService.On("GetWalletsByUserId", mockUserID).Return(mockWallets, nil)
+	mockService.On("GetWalletsByUserId", mock.Anything, mockUserID).Return(mockWallets, nil)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userID+"/wallets", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	asAdmin(c)
 	c.SetPath("/api/v1/users/:id/wallets")
 	c.SetParamNames("id")
 	c.SetParamValues(userID)
@@ -80,16 +195,17 @@ func TestWalletByUserIdHandler(t *testing.T) {
 
 func TestDeleteWalletHandler(t *testing.T) {
     mockService := new(MockService)
-    handler := NewHandler(mockService)
+    handler := NewHandler(mockService, testLogger)
 
     userID := "123"
 
-    mockService.On("DeleteWalletByUserId", userID).Return(int64(1), nil)
+    mockService.On("DeleteWalletByUserId", mock.Anything, userID, mock.Anything).Return(int64(1), nil)
 
     e := echo.New()
     req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+userID+"/wallets", nil)
     rec := httptest.NewRecorder()
     c := e.NewContext(req, rec)
+    asAdmin(c)
     c.SetPath("/api/v1/users/:id/wallets")
     c.SetParamNames("id")
     c.SetParamValues(userID)
@@ -110,19 +226,63 @@ func TestDeleteWalletHandler(t *testing.T) {
     mockService.AssertExpectations(t)
 }
 
+func TestDeleteWalletByIdHandler(t *testing.T) {
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/wallets/7", nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetPath("/api/v1/wallets/:id")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		return c, rec
+	}
+
+	t.Run("given owner should delete own wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1}, nil)
+		mockService.On("DeleteWalletById", mock.Anything, 7, mock.Anything).Return(&Wallet{ID: 7, UserID: 1, Status: "closed"}, nil)
+
+		c, rec := newContext()
+		asUser(c, 1)
+
+		if assert.NoError(t, handler.DeleteWalletByIdHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"closed"`)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given wallet of another user should return 404", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 2}, nil)
+
+		c, _ := newContext()
+		asUser(c, 1)
+
+		err := handler.DeleteWalletByIdHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+		mockService.AssertNotCalled(t, "DeleteWalletById")
+	})
+}
+
 func TestCreateWalletHandler(t *testing.T) {
 	
 	t.Run("given walletRequest to create wallet should return 201 and wallet struct", func(t *testing.T) {
 	
 		mockService := new(MockService)
-		handler := NewHandler(mockService)
+		handler := NewHandler(mockService, testLogger)
 
 		reqBody := WalletRequest{
 			UserID:     1,
 			UserName:   "User1",
 			WalletName: "Wallet1",
 			WalletType: "Type1",
-			Balance:    100.0,
+			Balance:    money.MustParse("100.0"),
 		}
 
 		mockWallet := Wallet{
@@ -134,7 +294,7 @@ func TestCreateWalletHandler(t *testing.T) {
 			Balance:    reqBody.Balance,
 		}
 
-		mockService.On("CreateWallet", &reqBody).Return(&mockWallet, nil)
+		mockService.On("CreateWallet", mock.Anything, &reqBody, mock.Anything).Return(&mockWallet, nil)
 
 		e := echo.New()
 		reqBodyBytes, _ := json.Marshal(reqBody)
@@ -142,6 +302,7 @@ func TestCreateWalletHandler(t *testing.T) {
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		asAdmin(c)
 
 		if assert.NoError(t, handler.CreateWalletHandler(c)) {
 			assert.Equal(t, http.StatusCreated, rec.Code)
@@ -161,7 +322,7 @@ func TestCreateWalletHandler(t *testing.T) {
 	t.Run("given dup walletRequest to create wallet should return 500 and message duplicated", func(t *testing.T) {
         // Set up your handler, mock service, and any necessary middleware
         mockService := new(MockService)
-        handler := NewHandler(mockService)
+        handler := NewHandler(mockService, testLogger)
         handlerWithMiddleware := apperrs.CustomErrorMiddleware(handler.CreateWalletHandler)
 
         // Create a sample wallet request
@@ -170,12 +331,12 @@ func TestCreateWalletHandler(t *testing.T) {
             UserName:   "User1",
             WalletName: "Wallet1",
             WalletType: "Type1",
-            Balance:    100.0,
+            Balance:    money.MustParse("100.0"),
         }
 
         // Configure the mock service to return nil and an error indicating duplication
         errorMessage := "Duplicated wallets"
-        mockService.On("CreateWallet", &reqBody).Return(&Wallet{}, apperrs.NewInternalServerError(errorMessage))
+        mockService.On("CreateWallet", mock.Anything, &reqBody, mock.Anything).Return(&Wallet{}, apperrs.NewInternalServerError(errorMessage))
 
         // Prepare the HTTP request
 		e := echo.New()
@@ -184,6 +345,7 @@ func TestCreateWalletHandler(t *testing.T) {
         req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
         rec := httptest.NewRecorder()
         c := e.NewContext(req, rec)
+        asAdmin(c)
 
 
         // Invoke the handler function and assert the response
@@ -203,15 +365,16 @@ func TestCreateWalletHandler(t *testing.T) {
 
 func TestUpdateWalletHandler(t *testing.T) {
 	mockService := new(MockService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, testLogger)
 
 	walletID := "123"
-	reqBody := WalletRequest{
+	balance := money.MustParse("100.0")
+	reqBody := WalletUpdateRequest{
 		UserID:     1,
 		UserName:   "User1",
 		WalletName: "Wallet1",
 		WalletType: "Type1",
-		Balance:    100.0,
+		Balance:    &balance,
 	}
 
 	mockWallet := Wallet{
@@ -220,23 +383,28 @@ func TestUpdateWalletHandler(t *testing.T) {
 		UserName:   reqBody.UserName,
 		WalletName: reqBody.WalletName,
 		WalletType: reqBody.WalletType,
-		Balance:    reqBody.Balance,
+		Balance:    balance,
+		Version:    4,
 	}
 
-	mockService.On("UpdateWalletByWalletId", 123, &reqBody).Return(&mockWallet, nil)
+	mockService.On("GetWalletById", mock.Anything, 123).Return(&Wallet{ID: 123, UserID: 1, Balance: money.MustParse("100.0"), Version: 3}, nil)
+	mockService.On("UpdateWalletByWalletId", mock.Anything, 123, 3, &reqBody, mock.Anything).Return(&mockWallet, nil)
 
 	e := echo.New()
 	reqBodyBytes, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/wallets/"+walletID, bytes.NewReader(reqBodyBytes))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"3"`)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	asAdmin(c)
 	c.SetPath("/api/v1/wallets/:id")
 	c.SetParamNames("id")
 	c.SetParamValues(walletID)
 
 	if assert.NoError(t, handler.UpdateWalletHandler(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
 
 		var responseWallet Wallet
 		err := json.Unmarshal(rec.Body.Bytes(), &responseWallet)
@@ -247,3 +415,688 @@ func TestUpdateWalletHandler(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+
+func TestUpdateWalletHandlerPreconditions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ifMatch  string
+		wantCode int
+	}{
+		{name: "given no If-Match should return 428", ifMatch: "", wantCode: http.StatusPreconditionRequired},
+		{name: "given unparsable If-Match should return 412", ifMatch: "abc", wantCode: http.StatusPreconditionFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			handler := NewHandler(mockService, testLogger)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/wallets/1", bytes.NewReader([]byte(`{}`)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			asAdmin(c)
+			c.SetPath("/api/v1/wallets/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			err := handler.UpdateWalletHandler(c)
+
+			httpErr, ok := err.(*echo.HTTPError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantCode, httpErr.Code)
+			mockService.AssertNotCalled(t, "UpdateWalletByWalletId")
+		})
+	}
+}
+
+func TestUpdateWalletHandlerAnyVersion(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, testLogger)
+	reqBody := WalletUpdateRequest{WalletName: "renamed"}
+	mockService.On("GetWalletById", mock.Anything, 1).Return(&Wallet{ID: 1, UserID: 1, Balance: money.MustParse("100.0"), Version: 3}, nil)
+	mockService.On("UpdateWalletByWalletId", mock.Anything, 1, AnyVersion, &reqBody, mock.Anything).Return(&Wallet{ID: 1, Version: 4}, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wallets/1", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	asAdmin(c)
+	c.SetPath("/api/v1/wallets/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if assert.NoError(t, handler.UpdateWalletHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestParseETag(t *testing.T) {
+	version, ok := parseETag(`"7"`)
+	assert.True(t, ok)
+	assert.Equal(t, 7, version)
+
+	version, ok = parseETag(`W/"7"`)
+	assert.True(t, ok)
+	assert.Equal(t, 7, version)
+
+	version, ok = parseETag(" * ")
+	assert.True(t, ok)
+	assert.Equal(t, AnyVersion, version)
+
+	_, ok = parseETag("7")
+	assert.False(t, ok)
+
+	assert.Equal(t, `"7"`, etag(7))
+}
+
+func TestTransferHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, testLogger)
+
+	reqBody := TransferRequest{
+		FromWalletID: 1,
+		ToWalletID:   2,
+		Amount:       money.MustParse("50.0"),
+	}
+
+	mockTransfer := Transfer{
+		FromWallet: Wallet{ID: 1, UserID: 1, WalletType: "Savings", Balance: money.MustParse("950.0")},
+		ToWallet:   Wallet{ID: 2, UserID: 2, WalletType: "Savings", Balance: money.MustParse("1050.0")},
+		Amount:     reqBody.Amount,
+	}
+
+	mockService.On("Transfer", mock.Anything, &reqBody, mock.Anything).Return(&mockTransfer, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transfers", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	asAdmin(c)
+
+	if assert.NoError(t, handler.TransferHandler(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var responseTransfer Transfer
+		err := json.Unmarshal(rec.Body.Bytes(), &responseTransfer)
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockTransfer, responseTransfer)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+
+func TestFXQuoteHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, testLogger)
+
+	reqBody := FXQuoteRequest{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("50.00")}
+
+	mockQuote := FXQuote{
+		ID:              "9f86d081884c7d659a2feaa0c55ad015",
+		FromCurrency:    "USD",
+		ToCurrency:      "THB",
+		Amount:          reqBody.Amount,
+		ConvertedAmount: money.MustParse("1815.87"),
+		Rate:            "36.5",
+		Spread:          "0.005",
+	}
+
+	mockService.On("CreateFXQuote", mock.Anything, &reqBody).Return(&mockQuote, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/fx/quotes", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	asAdmin(c)
+
+	if assert.NoError(t, handler.FXQuoteHandler(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var responseQuote FXQuote
+		err := json.Unmarshal(rec.Body.Bytes(), &responseQuote)
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockQuote, responseQuote)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWalletTransactionsHandler(t *testing.T) {
+	t.Run("given paging query should pass limit and offset to service", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		mockPage := TransactionPage{
+			Transactions: []Transaction{
+				{ID: 1, WalletID: 7, Type: "create", Amount: money.MustParse("100.0"), BalanceAfter: money.MustParse("100.0")},
+			},
+			Limit:  10,
+			Offset: 5,
+		}
+
+		mockService.On("GetTransactionsByWalletId", mock.Anything, 7, 10, 5).Return(&mockPage, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/7/transactions?limit=10&offset=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id/transactions")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		if assert.NoError(t, handler.WalletTransactionsHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var responsePage TransactionPage
+			err := json.Unmarshal(rec.Body.Bytes(), &responsePage)
+			assert.NoError(t, err)
+
+			assert.Equal(t, mockPage, responsePage)
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given invalid limit should return 400", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/7/transactions?limit=abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id/transactions")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		err := handler.WalletTransactionsHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockService.AssertNotCalled(t, "GetTransactionsByWalletId")
+	})
+}
+
+
+func TestDepositHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService, testLogger)
+
+	reqBody := BalanceChangeRequest{Amount: money.MustParse("50.0")}
+
+	mockChange := BalanceChange{
+		Wallet:      Wallet{ID: 7, UserID: 1, WalletType: "Savings", Balance: money.MustParse("150.0")},
+		Transaction: Transaction{ID: 3, WalletID: 7, Type: "deposit", Amount: money.MustParse("50.0"), BalanceAfter: money.MustParse("150.0")},
+	}
+
+	meta := postgres.AuditMeta{Actor: "99", RequestID: "req-1", ClientIP: "192.0.2.1"}
+	mockService.On("Deposit", mock.Anything, 7, &reqBody, meta).Return(&mockChange, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets/7/deposits", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	asAdmin(c)
+	c.SetPath("/api/v1/wallets/:id/deposits")
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	if assert.NoError(t, handler.DepositHandler(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var responseChange BalanceChange
+		err := json.Unmarshal(rec.Body.Bytes(), &responseChange)
+		assert.NoError(t, err)
+
+		assert.Equal(t, mockChange, responseChange)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDepositHandlerClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	tests := []struct {
+		name           string
+		trustedProxies []*net.IPNet
+		remoteAddr     string
+		wantIP         string
+	}{
+		{"given no trusted proxies should ignore forged headers", nil, "192.0.2.1:1234", "192.0.2.1"},
+		{"given untrusted peer should ignore forged headers", []*net.IPNet{proxies}, "192.0.2.1:1234", "192.0.2.1"},
+		{"given trusted proxy should record the forwarded client", []*net.IPNet{proxies}, "10.0.0.5:1234", "198.51.100.7"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			handler := NewHandler(mockService, testLogger)
+
+			reqBody := BalanceChangeRequest{Amount: money.MustParse("50.0")}
+			meta := postgres.AuditMeta{Actor: "99", ClientIP: tc.wantIP}
+			mockService.On("Deposit", mock.Anything, 7, &reqBody, meta).Return(&BalanceChange{}, nil)
+
+			e := echo.New()
+			e.IPExtractor = ClientIPExtractor(tc.trustedProxies)
+			reqBodyBytes, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets/7/deposits", bytes.NewReader(reqBodyBytes))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXForwardedFor, "198.51.100.7")
+			req.Header.Set(echo.HeaderXRealIP, "203.0.113.9")
+			req.RemoteAddr = tc.remoteAddr
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			asAdmin(c)
+			c.SetParamNames("id")
+			c.SetParamValues("7")
+
+			assert.NoError(t, handler.DepositHandler(c))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWithdrawalHandler(t *testing.T) {
+	t.Run("given valid request should return 201 and balance change", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		reqBody := BalanceChangeRequest{Amount: money.MustParse("50.0")}
+
+		mockChange := BalanceChange{
+			Wallet:      Wallet{ID: 7, UserID: 1, WalletType: "Savings", Balance: money.MustParse("50.0")},
+			Transaction: Transaction{ID: 4, WalletID: 7, Type: "withdrawal", Amount: money.MustParse("-50.0"), BalanceAfter: money.MustParse("50.0")},
+		}
+
+		mockService.On("Withdraw", mock.Anything, 7, &reqBody, mock.Anything).Return(&mockChange, nil)
+
+		e := echo.New()
+		reqBodyBytes, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets/7/withdrawals", bytes.NewReader(reqBodyBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id/withdrawals")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		if assert.NoError(t, handler.WithdrawalHandler(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+
+			var responseChange BalanceChange
+			err := json.Unmarshal(rec.Body.Bytes(), &responseChange)
+			assert.NoError(t, err)
+
+			assert.Equal(t, mockChange, responseChange)
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given insufficient funds should return 422 through middleware", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		handlerWithMiddleware := apperrs.CustomErrorMiddleware(handler.WithdrawalHandler)
+
+		reqBody := BalanceChangeRequest{Amount: money.MustParse("5000.0")}
+
+		mockService.On("Withdraw", mock.Anything, 7, &reqBody, mock.Anything).Return(&BalanceChange{}, apperrs.NewUnprocessableEntity("insufficient funds"))
+
+		e := echo.New()
+		reqBodyBytes, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets/7/withdrawals", bytes.NewReader(reqBodyBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		asAdmin(c)
+		c.SetPath("/api/v1/wallets/:id/withdrawals")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		assert.NoError(t, handlerWithMiddleware(c))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "insufficient funds")
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandlerAuthorization(t *testing.T) {
+	newContext := func(method string, target string, body interface{}) (echo.Context, *httptest.ResponseRecorder) {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, target, bytes.NewReader(b))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return echo.New().NewContext(req, rec), rec
+	}
+
+	assertStatus := func(t *testing.T, code int, err error) {
+		httpErr, ok := err.(*echo.HTTPError)
+		if assert.True(t, ok, "expected an HTTP error, got %v", err) {
+			assert.Equal(t, code, httpErr.Code)
+		}
+	}
+
+	t.Run("given no principal should return 401", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		c, _ := newContext(http.MethodGet, "/api/v1/users/1/wallets", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		assertStatus(t, http.StatusUnauthorized, handler.WalletByUserIdHandler(c))
+		mockService.AssertNotCalled(t, "GetWalletsByUserId")
+	})
+
+	t.Run("given another user's id should return 403", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		c, _ := newContext(http.MethodGet, "/api/v1/users/2/wallets", nil)
+		asUser(c, 1)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+
+		assertStatus(t, http.StatusForbidden, handler.WalletByUserIdHandler(c))
+		mockService.AssertNotCalled(t, "GetWalletsByUserId")
+	})
+
+	t.Run("given own user id should return wallets", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletsByUserId", mock.Anything, 1).Return([]Wallet{{ID: 7, UserID: 1}}, nil)
+
+		c, rec := newContext(http.MethodGet, "/api/v1/users/1/wallets", nil)
+		asUser(c, 1)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		assert.NoError(t, handler.WalletByUserIdHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given non-admin listing wallets should only see their own", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("ListWallets", mock.Anything, &WalletQuery{UserID: 1, Sort: "created_at", Limit: 20}).Return(&WalletPage{Data: []Wallet{}}, nil)
+
+		c, rec := newContext(http.MethodGet, "/api/v1/wallets", nil)
+		asUser(c, 1)
+
+		assert.NoError(t, handler.WalletHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given non-admin filtering by another user should return 403", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		c, _ := newContext(http.MethodGet, "/api/v1/wallets?user_id=2", nil)
+		asUser(c, 1)
+
+		assertStatus(t, http.StatusForbidden, handler.WalletHandler(c))
+		mockService.AssertNotCalled(t, "ListWallets")
+	})
+
+	t.Run("given wallet of another user should return the 404 of a missing wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 2}, nil)
+		mockService.On("GetWalletById", mock.Anything, 8).Return((*Wallet)(nil), apperrs.NewNotFoundError("wallet 8: not found"))
+
+		get := func(id string) error {
+			c, _ := newContext(http.MethodGet, "/api/v1/wallets/"+id, nil)
+			asUser(c, 1)
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+			return handler.GetWalletHandler(c)
+		}
+
+		notOwned, missing := get("7"), get("8")
+
+		assertStatus(t, http.StatusNotFound, notOwned)
+		assertStatus(t, http.StatusNotFound, missing)
+		assert.Equal(t, "wallet 7: not found", notOwned.(*echo.HTTPError).Message)
+	})
+
+	t.Run("given deposit into own wallet should succeed", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		reqBody := BalanceChangeRequest{Amount: money.MustParse("5")}
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1}, nil)
+		mockService.On("Deposit", mock.Anything, 7, &reqBody, mock.Anything).Return(&BalanceChange{}, nil)
+
+		c, rec := newContext(http.MethodPost, "/api/v1/wallets/7/deposits", reqBody)
+		asUser(c, 1)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		assert.NoError(t, handler.DepositHandler(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given transfer from another user's wallet should return 404", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 2}, nil)
+
+		c, _ := newContext(http.MethodPost, "/api/v1/transfers", TransferRequest{FromWalletID: 7, ToWalletID: 8, Amount: money.MustParse("5")})
+		asUser(c, 1)
+
+		assertStatus(t, http.StatusNotFound, handler.TransferHandler(c))
+		mockService.AssertNotCalled(t, "Transfer")
+	})
+
+	t.Run("given wallet created for another user should return 403", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+
+		c, _ := newContext(http.MethodPost, "/api/v1/wallets", WalletRequest{UserID: 2, WalletType: "Savings"})
+		asUser(c, 1)
+
+		assertStatus(t, http.StatusForbidden, handler.CreateWalletHandler(c))
+		mockService.AssertNotCalled(t, "CreateWallet")
+	})
+}
+
+func TestHandlerRoles(t *testing.T) {
+	newContext := func(method string, target string, body interface{}) (echo.Context, *httptest.ResponseRecorder) {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, target, bytes.NewReader(b))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("If-Match", `"3"`)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		return c, rec
+	}
+
+	t.Run("given readonly role should read wallets of any user", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetTransactionsByWalletId", mock.Anything, 7, 20, 0).Return(&TransactionPage{}, nil)
+
+		c, rec := newContext(http.MethodGet, "/api/v1/wallets/7/transactions", nil)
+		asUser(c, 1, "readonly")
+
+		assert.NoError(t, handler.WalletTransactionsHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertNotCalled(t, "GetWalletById")
+	})
+
+	t.Run("given readonly role should not withdraw from wallets of other users", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 2}, nil)
+
+		c, _ := newContext(http.MethodPost, "/api/v1/wallets/7/withdrawals", BalanceChangeRequest{Amount: money.MustParse("5")})
+		asUser(c, 1, "readonly")
+
+		err := handler.WithdrawalHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		assert.Equal(t, "permission wallet:transact required", httpErr.Message)
+		mockService.AssertNotCalled(t, "Withdraw")
+	})
+
+	t.Run("given operator role should adjust any wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		reqBody := AdjustmentRequest{Amount: money.MustParse("-5"), Reason: "fee refund reversed"}
+		mockService.On("Adjust", mock.Anything, 7, &reqBody, mock.Anything).Return(&BalanceChange{}, nil)
+
+		c, rec := newContext(http.MethodPost, "/api/v1/wallets/7/adjustments", reqBody)
+		asUser(c, 1, "operator")
+
+		assert.NoError(t, handler.AdjustmentHandler(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given owner without role should not adjust own wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1}, nil)
+
+		c, _ := newContext(http.MethodPost, "/api/v1/wallets/7/adjustments", AdjustmentRequest{Amount: money.MustParse("5"), Reason: "gift"})
+		asUser(c, 1)
+
+		err := handler.AdjustmentHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		mockService.AssertNotCalled(t, "Adjust")
+	})
+
+	t.Run("given owner renaming wallet should update", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		reqBody := WalletUpdateRequest{WalletName: "Trip"}
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1, Balance: money.MustParse("100"), Version: 3}, nil)
+		mockService.On("UpdateWalletByWalletId", mock.Anything, 7, 3, &reqBody, mock.Anything).Return(&Wallet{ID: 7, UserID: 1, Version: 4}, nil)
+
+		c, rec := newContext(http.MethodPut, "/api/v1/wallets/7", reqBody)
+		asUser(c, 1)
+
+		assert.NoError(t, handler.UpdateWalletHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+// testPolicy mirrors the role grants seeded by the migrations.
+func TestWalletStatusHandlers(t *testing.T) {
+	newContext := func(target string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		return c, rec
+	}
+
+	t.Run("given operator role should freeze any wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("ChangeWalletStatus", mock.Anything, 7, postgres.WalletFrozen, mock.Anything).Return(&Wallet{ID: 7, UserID: 2, Status: postgres.WalletFrozen}, nil)
+
+		c, rec := newContext("/api/v1/wallets/7/freeze")
+		asUser(c, 1, "operator")
+
+		if assert.NoError(t, handler.FreezeWalletHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"frozen"`)
+		}
+		mockService.AssertNotCalled(t, "GetWalletById")
+	})
+
+	t.Run("given owner should not unfreeze own wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1, Status: postgres.WalletFrozen}, nil)
+
+		c, _ := newContext("/api/v1/wallets/7/unfreeze")
+		asUser(c, 1)
+
+		err := handler.UnfreezeWalletHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		mockService.AssertNotCalled(t, "ChangeWalletStatus")
+	})
+
+	t.Run("given owner should close own wallet", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService, testLogger)
+		mockService.On("GetWalletById", mock.Anything, 7).Return(&Wallet{ID: 7, UserID: 1}, nil)
+		mockService.On("ChangeWalletStatus", mock.Anything, 7, postgres.WalletClosed, mock.Anything).Return(&Wallet{ID: 7, UserID: 1, Status: postgres.WalletClosed}, nil)
+
+		c, rec := newContext("/api/v1/wallets/7/close")
+		asUser(c, 1)
+
+		assert.NoError(t, handler.CloseWalletHandler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+var testPolicy = auth.NewPolicy(map[string][]string{
+	auth.RoleOwner: {auth.PermWalletRead, auth.PermWalletWrite, auth.PermWalletTransact, auth.PermWalletDelete},
+	auth.RoleAdmin: {auth.PermWalletRead, auth.PermWalletWrite, auth.PermWalletTransact, auth.PermWalletAdjust, auth.PermWalletDelete, auth.PermWalletFreeze},
+	"operator":     {auth.PermWalletRead, auth.PermWalletAdjust, auth.PermWalletFreeze},
+	"readonly":     {auth.PermWalletRead},
+})
+
+// asAdmin authenticates c the way auth.Middleware and auth.Roles would for
+// an admin.
+func asAdmin(c echo.Context) {
+	asUser(c, 99, auth.RoleAdmin)
+}
+
+// asUser authenticates c as userId holding roles.
+func asUser(c echo.Context, userId int, roles ...string) {
+	p := &auth.Principal{Subject: strconv.Itoa(userId), UserID: userId, Roles: roles}
+	testPolicy.Resolve(p)
+	auth.SetPrincipal(c, p)
+}
@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracedService starts a span for every call to the service it wraps, so
+// the statements a request runs are grouped under the call that ran them.
+type tracedService struct {
+	Service
+}
+
+// NewTracedService returns s with a span for each of its calls.
+func NewTracedService(s Service) Service {
+	return tracedService{Service: s}
+}
+
+func (s tracedService) GetAllWallets(ctx context.Context) (result []Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/GetAllWallets")
+	defer tracing.End(span, &err)
+	return s.Service.GetAllWallets(ctx)
+}
+
+func (s tracedService) ListWallets(ctx context.Context, query *WalletQuery) (result *WalletPage, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/ListWallets")
+	defer tracing.End(span, &err)
+	return s.Service.ListWallets(ctx, query)
+}
+
+func (s tracedService) GetWalletById(ctx context.Context, walletId int) (result *Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/GetWalletById", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.GetWalletById(ctx, walletId)
+}
+
+func (s tracedService) GetWalletsByWalletType(ctx context.Context, walletType string) (result []Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/GetWalletsByWalletType")
+	defer tracing.End(span, &err)
+	return s.Service.GetWalletsByWalletType(ctx, walletType)
+}
+
+func (s tracedService) GetWalletsByUserId(ctx context.Context, userId int) (result []Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/GetWalletsByUserId")
+	defer tracing.End(span, &err)
+	return s.Service.GetWalletsByUserId(ctx, userId)
+}
+
+func (s tracedService) CreateWallet(ctx context.Context, request *WalletRequest, meta postgres.AuditMeta) (result *Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/CreateWallet")
+	defer tracing.End(span, &err)
+	return s.Service.CreateWallet(ctx, request, meta)
+}
+
+func (s tracedService) DeleteWalletByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (result int64, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/DeleteWalletByUserId")
+	defer tracing.End(span, &err)
+	return s.Service.DeleteWalletByUserId(ctx, userId, meta)
+}
+
+func (s tracedService) DeleteWalletById(ctx context.Context, walletId int, meta postgres.AuditMeta) (result *Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/DeleteWalletById", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.DeleteWalletById(ctx, walletId, meta)
+}
+
+func (s tracedService) UpdateWalletByWalletId(ctx context.Context, walletId int, version int, request *WalletUpdateRequest, meta postgres.AuditMeta) (result *Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/UpdateWalletByWalletId", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.UpdateWalletByWalletId(ctx, walletId, version, request, meta)
+}
+
+func (s tracedService) Transfer(ctx context.Context, request *TransferRequest, meta postgres.AuditMeta) (result *Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/Transfer")
+	defer tracing.End(span, &err)
+	return s.Service.Transfer(ctx, request, meta)
+}
+
+func (s tracedService) GetTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (result *TransactionPage, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/GetTransactionsByWalletId", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.GetTransactionsByWalletId(ctx, walletId, limit, offset)
+}
+
+func (s tracedService) ReconcileWallet(ctx context.Context, walletId int) (result *Reconciliation, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/ReconcileWallet", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.ReconcileWallet(ctx, walletId)
+}
+
+func (s tracedService) Deposit(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (result *BalanceChange, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/Deposit", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.Deposit(ctx, walletId, request, meta)
+}
+
+func (s tracedService) Withdraw(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (result *BalanceChange, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/Withdraw", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.Withdraw(ctx, walletId, request, meta)
+}
+
+func (s tracedService) Adjust(ctx context.Context, walletId int, request *AdjustmentRequest, meta postgres.AuditMeta) (result *BalanceChange, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/Adjust", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.Adjust(ctx, walletId, request, meta)
+}
+
+func (s tracedService) ChangeWalletStatus(ctx context.Context, walletId int, status string, meta postgres.AuditMeta) (result *Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/ChangeWalletStatus", attribute.Int("wallet.id", walletId))
+	defer tracing.End(span, &err)
+	return s.Service.ChangeWalletStatus(ctx, walletId, status, meta)
+}
+
+func (s tracedService) CreateFXQuote(ctx context.Context, request *FXQuoteRequest) (result *FXQuote, err error) {
+	ctx, span := tracing.Start(ctx, "wallet.Service/CreateFXQuote")
+	defer tracing.End(span, &err)
+	return s.Service.CreateFXQuote(ctx, request)
+}
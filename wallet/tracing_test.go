@@ -0,0 +1,39 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	mockService := new(MockService)
+	request := &WalletUpdateRequest{WalletName: "Travel"}
+	mockService.On("UpdateWalletByWalletId", mock.Anything, 7, 2, request, postgres.AuditMeta{}).
+		Return((*Wallet)(nil), apperrs.NewInternalServerError("Update wallet failed"))
+
+	_, err := NewTracedService(mockService).UpdateWalletByWalletId(context.Background(), 7, 2, request, postgres.AuditMeta{})
+
+	assert.Error(t, err)
+	mockService.AssertExpectations(t)
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "wallet.Service/UpdateWalletByWalletId", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.Int("wallet.id", 7))
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	}
+}
@@ -1,39 +1,186 @@
 package wallet
 
 import (
+	"context"
 	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 )
 
 type Wallet struct {
-	ID         int       `json:"id" example:"1"`
-	UserID     int       `json:"user_id" example:"1"`
-	UserName   string    `json:"user_name" example:"John Doe"`
-	WalletName string    `json:"wallet_name" example:"John's Wallet"`
-	WalletType string    `json:"wallet_type" example:"Create Card"`
-	Balance    float64   `json:"balance" example:"100.00"`
-	CreatedAt  time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+	ID         int         `json:"id" example:"1"`
+	UserID     int         `json:"user_id" example:"1"`
+	UserName   string      `json:"user_name" example:"John Doe"`
+	WalletName string      `json:"wallet_name" example:"John's Wallet"`
+	WalletType string      `json:"wallet_type" example:"Create Card"`
+	Balance    money.Money `json:"balance" swaggertype:"number" example:"100.00"`
+	Currency   string      `json:"currency" example:"THB"`
+	Status     string      `json:"status" example:"active"`
+	Version    int         `json:"version" example:"1"`
+	CreatedAt  time.Time   `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
 }
 
 type WalletRequest struct {
-	UserID     int     `json:"user_id" example:"1"`
-	UserName   string  `json:"user_name" example:"John Doe"`
-	WalletName string  `json:"wallet_name" example:"John's Wallet"`
-	WalletType string  `json:"wallet_type" example:"Credit Card"`
-	Balance    float64 `json:"balance" example:"100.00"`
+	UserID     int         `json:"user_id" example:"1"`
+	UserName   string      `json:"user_name" example:"John Doe"`
+	WalletName string      `json:"wallet_name" example:"John's Wallet"`
+	WalletType string      `json:"wallet_type" example:"Credit Card"`
+	Balance    money.Money `json:"balance" swaggertype:"number" example:"100.00"`
+	Currency   string      `json:"currency" example:"THB"`
+}
+
+// WalletUpdateRequest replaces the details of a wallet. Its balance only
+// changes through deposits, withdrawals, transfers and adjustments, so
+// Balance may be left out or sent back as read, and any other value is
+// refused.
+type WalletUpdateRequest struct {
+	UserID     int          `json:"user_id" example:"1"`
+	UserName   string       `json:"user_name" example:"John Doe"`
+	WalletName string       `json:"wallet_name" example:"John's Wallet"`
+	WalletType string       `json:"wallet_type" example:"Credit Card"`
+	Balance    *money.Money `json:"balance,omitempty" swaggertype:"number" example:"100.00"`
+	Currency   string       `json:"currency,omitempty" example:"THB"`
+}
+
+// WalletQuery selects a page of wallets. Sort is created_at, balance or
+// wallet_name, prefixed with "-" for descending order. Without a Status,
+// closed wallets are left out.
+type WalletQuery struct {
+	WalletType  string
+	Status      string
+	UserID      int
+	MinBalance  *money.Money
+	MaxBalance  *money.Money
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Limit       int
+	Cursor      string
+}
+
+type WalletPage struct {
+	Data       []Wallet `json:"data"`
+	NextCursor string   `json:"next_cursor,omitempty" example:"eyJzIjoiY3JlYXRlZF9hdCIsImlkIjo..."`
+}
+
+type TransferRequest struct {
+	FromWalletID int         `json:"from_wallet_id" example:"1"`
+	ToWalletID   int         `json:"to_wallet_id" example:"2"`
+	Amount       money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+	QuoteID      string      `json:"quote_id,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+}
+
+type Transfer struct {
+	FromWallet     Wallet      `json:"from_wallet"`
+	ToWallet       Wallet      `json:"to_wallet"`
+	Amount         money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+	CreditedAmount money.Money `json:"credited_amount" swaggertype:"number" example:"50.00"`
+	FXQuoteID      string      `json:"fx_quote_id,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	FXRate         string      `json:"fx_rate,omitempty" example:"36.5"`
+	FXSpread       string      `json:"fx_spread,omitempty" example:"0.005"`
+}
+
+type FXQuoteRequest struct {
+	FromCurrency string      `json:"from_currency" example:"USD"`
+	ToCurrency   string      `json:"to_currency" example:"THB"`
+	Amount       money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+}
+
+type FXQuote struct {
+	ID              string      `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	FromCurrency    string      `json:"from_currency" example:"USD"`
+	ToCurrency      string      `json:"to_currency" example:"THB"`
+	Amount          money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+	ConvertedAmount money.Money `json:"converted_amount" swaggertype:"number" example:"1815.87"`
+	Rate            string      `json:"rate" example:"36.5"`
+	Spread          string      `json:"spread" example:"0.005"`
+	ExpiresAt       time.Time   `json:"expires_at" example:"2024-03-25T14:20:00Z"`
+}
+
+type BalanceChangeRequest struct {
+	Amount   money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+	Currency string      `json:"currency,omitempty" example:"THB"`
+}
+
+// AdjustmentRequest corrects a balance by a signed amount; the reason is
+// kept on the ledger entry.
+type AdjustmentRequest struct {
+	Amount   money.Money `json:"amount" swaggertype:"number" example:"-12.50"`
+	Currency string      `json:"currency,omitempty" example:"THB"`
+	Reason   string      `json:"reason" example:"refund of duplicated card fee"`
 }
 
+type BalanceChange struct {
+	Wallet      Wallet      `json:"wallet"`
+	Transaction Transaction `json:"transaction"`
+}
+
+type Transaction struct {
+	ID                   int64       `json:"id" example:"1"`
+	WalletID             int         `json:"wallet_id" example:"1"`
+	Type                 string      `json:"type" example:"deposit"`
+	Amount               money.Money `json:"amount" swaggertype:"number" example:"50.00"`
+	BalanceAfter         money.Money `json:"balance_after" swaggertype:"number" example:"150.00"`
+	Currency             string      `json:"currency" example:"THB"`
+	CounterpartyWalletID int         `json:"counterparty_wallet_id,omitempty" example:"2"`
+	FXRate               string      `json:"fx_rate,omitempty" example:"36.5"`
+	FXSpread             string      `json:"fx_spread,omitempty" example:"0.005"`
+	FXQuoteID            string      `json:"fx_quote_id,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Reason               string      `json:"reason,omitempty" example:"refund of duplicated card fee"`
+	CreatedAt            time.Time   `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	Limit        int           `json:"limit" example:"20"`
+	Offset       int           `json:"offset" example:"0"`
+}
+
+type Reconciliation struct {
+	WalletID      int         `json:"wallet_id" example:"1"`
+	Balance       money.Money `json:"balance" swaggertype:"number" example:"100.00"`
+	LedgerBalance money.Money `json:"ledger_balance" swaggertype:"number" example:"100.00"`
+	Balanced      bool        `json:"balanced" example:"true"`
+}
+
+// AnyVersion asks UpdateWalletByWalletId to update whatever version the
+// wallet is at, for If-Match: *. Versions start at 1.
+const AnyVersion = 0
 
 type Service interface {
-	GetAllWallets() ([]Wallet, error)
+	GetAllWallets(ctx context.Context) ([]Wallet, error)
+	
+	ListWallets(ctx context.Context, query *WalletQuery) (*WalletPage, error)
+	
+	GetWalletById(ctx context.Context, walletId int) (*Wallet, error)
+	
+	GetWalletsByWalletType(ctx context.Context, walletType string) ([]Wallet, error)
+	
+	GetWalletsByUserId(ctx context.Context, userId int) ([]Wallet, error)
+	
+	CreateWallet(ctx context.Context, wallet *WalletRequest, meta postgres.AuditMeta)(*Wallet,error)
+	
+	DeleteWalletByUserId(ctx context.Context, userId string, meta postgres.AuditMeta)(int64,error)
+	
+	DeleteWalletById(ctx context.Context, walletId int, meta postgres.AuditMeta) (*Wallet, error)
+	
+	UpdateWalletByWalletId(ctx context.Context, walletId int, version int, request *WalletUpdateRequest, meta postgres.AuditMeta) (*Wallet, error)
+	
+	Transfer(ctx context.Context, request *TransferRequest, meta postgres.AuditMeta) (*Transfer, error)
+	
+	GetTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (*TransactionPage, error)
+	
+	ReconcileWallet(ctx context.Context, walletId int) (*Reconciliation, error)
 	
-	GetWalletsByWalletType(walletType string) ([]Wallet, error)
+	Deposit(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error)
 	
-	GetWalletsByUserId(userId int) ([]Wallet, error)
+	Withdraw(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error)
 	
-	CreateWallet(wallet *WalletRequest)(*Wallet,error)
+	Adjust(ctx context.Context, walletId int, request *AdjustmentRequest, meta postgres.AuditMeta) (*BalanceChange, error)
 	
-	DeleteWalletByUserId(userId string)(int64,error)
+	ChangeWalletStatus(ctx context.Context, walletId int, status string, meta postgres.AuditMeta) (*Wallet, error)
 	
-	UpdateWalletByWalletId(walletId int,request *WalletRequest) (*Wallet,error)
+	CreateFXQuote(ctx context.Context, request *FXQuoteRequest) (*FXQuote, error)
 }
 
@@ -1,33 +1,46 @@
 package wallet
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/metrics"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 )
 
 type WalletService struct {
 	WalletStore postgres.Storer
+	Rates       FXRateProvider
+	Logger      *slog.Logger
 }
 
-func NewService(db postgres.Storer) WalletService {
-	return WalletService{WalletStore: db}
+func NewService(db postgres.Storer, rates FXRateProvider, logger *slog.Logger) WalletService {
+	return WalletService{WalletStore: db, Rates: rates, Logger: logger}
 }
 
-func (s WalletService) GetAllWallets() ([]Wallet, error) {
+// logger falls back to the default logger for a service built without one.
+func (s WalletService) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
+}
+
+func (s WalletService) GetAllWallets(ctx context.Context) ([]Wallet, error) {
 
-	wallets, err := s.WalletStore.FindAll()
+	wallets, err := s.WalletStore.FindAll(ctx)
 
 	if err != nil {
 		return nil, apperrs.NewInternalServerError(err.Error())
 	}
 
-	if len(wallets) == 0 {
-		return nil, apperrs.NewNotFoundError("wallet not found")
-	}
-
-	var walletResponses []Wallet
+	walletResponses := []Wallet{}
 	for _, w := range wallets {
 		walletResponses = append(walletResponses, Wallet{
 			ID:         w.ID,
@@ -36,6 +49,9 @@ func (s WalletService) GetAllWallets() ([]Wallet, error) {
 			WalletName: w.WalletName,
 			WalletType: w.WalletType,
 			Balance:    w.Balance,
+			Currency:   w.Currency,
+			Status:     w.Status,
+			Version:    w.Version,
 			CreatedAt:  w.CreatedAt,
 		})
 	}
@@ -44,19 +60,82 @@ func (s WalletService) GetAllWallets() ([]Wallet, error) {
 
 }
 
-func (s WalletService) GetWalletsByWalletType(walletType string) ([]Wallet, error) {
+// ListWallets returns one page of wallets and, when more follow, the cursor
+// to pass back for the next page.
+func (s WalletService) ListWallets(ctx context.Context, query *WalletQuery) (*WalletPage, error) {
 
-	wallets, err := s.WalletStore.FindByWalletType(walletType)
+	err := ValidateWalletQuery(query)
 
 	if err != nil {
-		return nil, apperrs.NewInternalServerError(err.Error())
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	filter := postgres.WalletFilter{
+		WalletType:  query.WalletType,
+		Status:      query.Status,
+		UserID:      query.UserID,
+		MinBalance:  query.MinBalance,
+		MaxBalance:  query.MaxBalance,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		Sort:        sortKey(query.Sort),
+		Descending:  sortKey(query.Sort) != query.Sort,
+		Limit:       query.Limit + 1,
 	}
 
-	if len(wallets) == 0 {
-		return nil, apperrs.NewNotFoundError("wallet not found")
+	if query.Cursor != "" {
+		filter.After, err = decodeCursor(query.Sort, query.Cursor)
+
+		if err != nil {
+			return nil, apperrs.NewBadRequestError(err.Error())
+		}
+	}
+
+	wallets, err := s.WalletStore.ListWallets(ctx, filter)
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "List wallets failed", "err", err)
+		return nil, apperrs.NewInternalServerError("List wallets failed")
+	}
+
+	page := &WalletPage{Data: []Wallet{}}
+
+	// One row more than the limit was asked for to learn whether a next page exists
+	if len(wallets) > query.Limit {
+		wallets = wallets[:query.Limit]
+		page.NextCursor = encodeCursor(query.Sort, postgres.CursorFor(wallets[len(wallets)-1], filter.Sort))
 	}
 
-	var walletResponses []Wallet
+	for _, w := range wallets {
+		page.Data = append(page.Data, toWalletResponse(w))
+	}
+
+	return page, nil
+}
+
+func (s WalletService) GetWalletById(ctx context.Context, walletId int) (*Wallet, error) {
+
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Get wallet failed")
+	}
+
+	walletResponse := toWalletResponse(*w)
+
+	return &walletResponse, nil
+}
+
+func (s WalletService) GetWalletsByWalletType(ctx context.Context, walletType string) ([]Wallet, error) {
+
+	wallets, err := s.WalletStore.FindByWalletType(ctx, walletType)
+
+	if err != nil {
+		return nil, apperrs.NewInternalServerError(err.Error())
+	}
+
+	walletResponses := []Wallet{}
 	for _, w := range wallets {
 		walletResponses = append(walletResponses, Wallet{
 			ID:         w.ID,
@@ -65,6 +144,9 @@ func (s WalletService) GetWalletsByWalletType(walletType string) ([]Wallet, erro
 			WalletName: w.WalletName,
 			WalletType: w.WalletType,
 			Balance:    w.Balance,
+			Currency:   w.Currency,
+			Status:     w.Status,
+			Version:    w.Version,
 			CreatedAt:  w.CreatedAt,
 		})
 	}
@@ -73,19 +155,15 @@ func (s WalletService) GetWalletsByWalletType(walletType string) ([]Wallet, erro
 
 }
 
-func (s WalletService) GetWalletsByUserId(userId int) ([]Wallet, error) {
+func (s WalletService) GetWalletsByUserId(ctx context.Context, userId int) ([]Wallet, error) {
 
-	wallets, err := s.WalletStore.FindByUserId(userId)
+	wallets, err := s.WalletStore.FindByUserId(ctx, userId)
 
 	if err != nil {
 		return nil, apperrs.NewInternalServerError(err.Error())
 	}
 
-	if len(wallets) == 0 {
-		return nil, apperrs.NewNotFoundError("wallet not found")
-	}
-
-	var walletResponses []Wallet
+	walletResponses := []Wallet{}
 	for _, w := range wallets {
 		walletResponses = append(walletResponses, Wallet{
 			ID:         w.ID,
@@ -94,6 +172,9 @@ func (s WalletService) GetWalletsByUserId(userId int) ([]Wallet, error) {
 			WalletName: w.WalletName,
 			WalletType: w.WalletType,
 			Balance:    w.Balance,
+			Currency:   w.Currency,
+			Status:     w.Status,
+			Version:    w.Version,
 			CreatedAt:  w.CreatedAt,
 		})
 	}
@@ -102,12 +183,12 @@ func (s WalletService) GetWalletsByUserId(userId int) ([]Wallet, error) {
 
 }
 
-func (s WalletService) CreateWallet(request *WalletRequest) (*Wallet, error) {
+func (s WalletService) CreateWallet(ctx context.Context, request *WalletRequest, meta postgres.AuditMeta) (*Wallet, error) {
 
 	err := ValidateWalletRequestCreate(request)
 
 	if err != nil {
-		log.Println(err)
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
 		return nil, apperrs.NewBadRequestError(err.Error())
 	}
 
@@ -116,27 +197,29 @@ func (s WalletService) CreateWallet(request *WalletRequest) (*Wallet, error) {
 		UserName:   request.UserName,
 		WalletName: request.WalletName,
 		WalletType: request.WalletType,
-		Balance:    request.Balance,
+		Balance:    request.Balance.WithCurrency(request.Currency),
+		Currency:   request.Currency,
 	}
 
-	isDuplicated, err := s.CheckDuplicated(wallet)
+	isDuplicated, err := s.CheckDuplicated(ctx, wallet)
 
 	if err != nil {
-		log.Println(err)
+		s.logger().ErrorContext(ctx, "Check duplicated wallet failed", "err", err)
 		return nil, apperrs.NewInternalServerError(err.Error())
 	}
 
 	if isDuplicated {
-		log.Printf("Duplicated wallet userid=%d userName=%s walletname=%s walletType=%s", wallet.UserID, wallet.UserName, wallet.WalletName, wallet.WalletType)
+		s.logger().InfoContext(ctx, "Duplicated wallet", "user_id", wallet.UserID, "user_name", wallet.UserName, "wallet_name", wallet.WalletName, "wallet_type", wallet.WalletType)
 		return nil, apperrs.NewInternalServerError("Duplicated wallet")
 	}
 
-	w, err := s.WalletStore.Create(&wallet)
+	w, err := s.WalletStore.Create(ctx, &wallet, meta)
 
 	if err != nil {
-		log.Println(err)
+		s.logger().ErrorContext(ctx, "Create wallet failed", "err", err)
 		return nil, apperrs.NewInternalServerError("Create wallet failed")
 	}
+	metrics.WalletsCreated.Inc()
 
 	walletResponses := Wallet{
 		ID:         w.ID,
@@ -145,13 +228,16 @@ func (s WalletService) CreateWallet(request *WalletRequest) (*Wallet, error) {
 		WalletName: w.WalletName,
 		WalletType: w.WalletType,
 		Balance:    w.Balance,
+		Currency:   w.Currency,
+		Status:     w.Status,
+		Version:    w.Version,
 		CreatedAt:  w.CreatedAt,
 	}
 
 	return &walletResponses, nil
 }
 
-func (s WalletService) CheckDuplicated(wallet postgres.Wallet) (bool, error) {
+func (s WalletService) CheckDuplicated(ctx context.Context, wallet postgres.Wallet) (bool, error) {
 
 	//user_id , user_name , wallet_name , wallet_type,
 
@@ -162,7 +248,7 @@ func (s WalletService) CheckDuplicated(wallet postgres.Wallet) (bool, error) {
 		WalletType: wallet.WalletType,
 	}
 
-	rowCount, err := s.WalletStore.CountByCriteria(criteria)
+	rowCount, err := s.WalletStore.CountByCriteria(ctx, criteria)
 
 	if err != nil {
 		return false, err
@@ -173,58 +259,142 @@ func (s WalletService) CheckDuplicated(wallet postgres.Wallet) (bool, error) {
 	return isDup, nil
 }
 
-func (s WalletService) DeleteWalletByUserId(userId string) (int64, error) {
+func (s WalletService) DeleteWalletByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
 
-	deleteRow, err := s.WalletStore.DeleteByUserId(userId)
+	deleteRow, err := s.WalletStore.DeleteByUserId(ctx, userId, meta)
 
 	if err != nil {
-		log.Println(err)
-		return 0, apperrs.NewInternalServerError("Delete wallet failed")
+		return 0, s.storeError(ctx, err, "Delete wallet failed")
 	}
 
 	if deleteRow == 0 {
-		log.Println("delete affected ", deleteRow)
+		s.logger().InfoContext(ctx, "Delete wallet affected no wallets", "user_id", userId)
 		return 0, apperrs.NewUnprocessableEntity("Delete wallet failed")
 	}
 
 	return deleteRow, nil
 }
 
-func (s WalletService) UpdateWalletByWalletId(walletId int, request *WalletRequest) (*Wallet, error) {
+// DeleteWalletById closes a single wallet. The store refuses wallets that
+// are not active or still hold money.
+func (s WalletService) DeleteWalletById(ctx context.Context, walletId int, meta postgres.AuditMeta) (*Wallet, error) {
+
+	w, err := s.WalletStore.DeleteByWalletId(ctx, walletId, meta)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Delete wallet failed")
+	}
+
+	walletResponse := toWalletResponse(*w)
+
+	return &walletResponse, nil
+}
+
+// walletTransitions lists the statuses a wallet may move to from each status.
+// Closed is final.
+var walletTransitions = map[string][]string{
+	postgres.WalletActive: {postgres.WalletFrozen, postgres.WalletClosed},
+	postgres.WalletFrozen: {postgres.WalletActive},
+}
+
+// ChangeWalletStatus freezes, unfreezes or closes a wallet. Only a wallet
+// with a zero balance can be closed.
+func (s WalletService) ChangeWalletStatus(ctx context.Context, walletId int, status string, meta postgres.AuditMeta) (*Wallet, error) {
+
+	existing, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Change wallet status failed")
+	}
+
+	allowed := false
+	for _, next := range walletTransitions[existing.Status] {
+		allowed = allowed || next == status
+	}
+
+	if !allowed {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet %d cannot go from %s to %s", walletId, existing.Status, status))
+	}
+
+	if status == postgres.WalletClosed && !existing.Balance.IsZero() {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet %d: %s", walletId, postgres.ErrBalanceNotZero))
+	}
+
+	w, err := s.WalletStore.SetStatus(ctx, walletId, existing.Version, status, meta)
+
+	// The wallet changed after it was checked; nothing was asked of the
+	// caller, so this is a conflict rather than a failed precondition
+	if errors.Is(err, postgres.ErrVersionConflict) {
+		return nil, apperrs.NewConflictError(err.Error())
+	}
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Change wallet status failed")
+	}
+
+	walletResponse := toWalletResponse(*w)
+
+	return &walletResponse, nil
+}
+
+func (s WalletService) UpdateWalletByWalletId(ctx context.Context, walletId int, version int, request *WalletUpdateRequest, meta postgres.AuditMeta) (*Wallet, error) {
 
 	err := ValidateWalletRequestUpdate(request)
 
 	if err != nil {
-		log.Println(err)
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
 		return nil, apperrs.NewBadRequestError(err.Error())
 	}
 
+	existing, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Update wallet failed")
+	}
+
+	if version == AnyVersion {
+		version = existing.Version
+	}
+
+	if existing.Version != version {
+		return nil, apperrs.NewPreconditionFailedError(postgres.ErrVersionConflict.Error())
+	}
+
+	if existing.Status != postgres.WalletActive {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet %d is %s", walletId, existing.Status))
+	}
+
+	if request.Currency != "" && request.Currency != existing.Currency {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet currency %s cannot be changed", existing.Currency))
+	}
+
+	if request.Balance != nil && request.Balance.Cmp(existing.Balance) != 0 {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet balance cannot be set; book the change with POST /api/v1/wallets/%d/adjustments", walletId))
+	}
+
 	wallet := postgres.Wallet{
 		UserID:     request.UserID,
 		UserName:   request.UserName,
 		WalletName: request.WalletName,
 		WalletType: request.WalletType,
-		Balance:    request.Balance,
 	}
 
 
-	updateRow, err := s.WalletStore.UpdateByWalletId(walletId, wallet)
+	updateRow, err := s.WalletStore.UpdateByWalletId(ctx, walletId, version, wallet, meta)
 
 	if err != nil {
-		log.Println(err)
-		return nil, apperrs.NewInternalServerError("Update wallet failed")
+		return nil, s.storeError(ctx, err, "Update wallet failed")
 	}
 
 	if updateRow == 0 {
-		log.Println("update affected ", updateRow)
-		return nil, apperrs.NewUnprocessableEntity("Update wallet failed")
+		s.logger().InfoContext(ctx, "Update wallet affected no wallets", "wallet_id", walletId)
+		return nil, apperrs.NewNotFoundError(fmt.Sprintf("wallet %d: %s", walletId, postgres.ErrNotFound))
 	}
 
-	w, err := s.WalletStore.FindByWalletId(walletId)
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
 
 	if err != nil {
-		log.Println(err)
-		return nil, apperrs.NewInternalServerError("Update wallet failed")
+		return nil, s.storeError(ctx, err, "Update wallet failed")
 	}
 
 	walletResponses := Wallet{
@@ -234,8 +404,368 @@ func (s WalletService) UpdateWalletByWalletId(walletId int, request *WalletReque
 		WalletName: w.WalletName,
 		WalletType: w.WalletType,
 		Balance:    w.Balance,
+		Currency:   w.Currency,
+		Status:     w.Status,
+		Version:    w.Version,
 		CreatedAt:  w.CreatedAt,
 	}
 
 	return &walletResponses, nil
 }
+
+func (s WalletService) Transfer(ctx context.Context, request *TransferRequest, meta postgres.AuditMeta) (*Transfer, error) {
+
+	err := ValidateTransferRequest(request)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	from, err := s.WalletStore.FindByWalletId(ctx, request.FromWalletID)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Transfer failed")
+	}
+
+	to, err := s.WalletStore.FindByWalletId(ctx, request.ToWalletID)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Transfer failed")
+	}
+
+	var quote *postgres.FXQuote
+
+	if request.QuoteID != "" {
+		quote, err = s.WalletStore.FindFXQuote(ctx, request.QuoteID)
+
+		if err != nil {
+			return nil, s.storeError(ctx, err, "Transfer failed")
+		}
+
+		err = checkFXQuote(quote, from, to, request.Amount)
+
+		if err != nil {
+			return nil, err
+		}
+	} else if from.Currency != to.Currency {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("transfers from %s to %s need a quote_id", from.Currency, to.Currency))
+	}
+
+	err = ValidateAmountPrecision(from.Currency, request.Amount)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	minBalance := balanceRuleFor(from.WalletType).minBalance(from.Currency)
+	amount := request.Amount.WithCurrency(from.Currency)
+
+	t, err := s.WalletStore.Transfer(ctx, request.FromWalletID, request.ToWalletID, amount, minBalance, quote, meta)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Transfer failed")
+	}
+	metrics.TransfersCompleted.Inc()
+
+	transfer := &Transfer{
+		FromWallet:     toWalletResponse(t.FromWallet),
+		ToWallet:       toWalletResponse(t.ToWallet),
+		Amount:         t.Amount,
+		CreditedAmount: t.CreditedAmount,
+	}
+
+	if t.Quote != nil {
+		transfer.FXQuoteID = t.Quote.ID
+		transfer.FXRate = t.Quote.Rate
+		transfer.FXSpread = t.Quote.Spread
+	}
+
+	return transfer, nil
+}
+
+func (s WalletService) CreateFXQuote(ctx context.Context, request *FXQuoteRequest) (*FXQuote, error) {
+
+	err := ValidateFXQuoteRequest(request)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	rate, err := s.Rates.Rate(ctx, request.FromCurrency, request.ToCurrency)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Create fx quote failed")
+	}
+
+	effective, err := effectiveRate(rate)
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "Create fx quote failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create fx quote failed")
+	}
+
+	to, _ := money.LookupCurrency(request.ToCurrency)
+	amount := request.Amount.WithCurrency(request.FromCurrency)
+
+	converted, err := amount.Convert(effective, to)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	if converted.IsZero() {
+		return nil, apperrs.NewBadRequestError(fmt.Sprintf("Amount is too small to convert to %s", request.ToCurrency))
+	}
+
+	id, err := newQuoteID()
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "Create fx quote failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create fx quote failed")
+	}
+
+	quote, err := s.WalletStore.CreateFXQuote(ctx, &postgres.FXQuote{
+		ID:              id,
+		FromCurrency:    request.FromCurrency,
+		ToCurrency:      request.ToCurrency,
+		Amount:          amount,
+		ConvertedAmount: converted,
+		Rate:            rate.Rate,
+		Spread:          rate.Spread,
+		ExpiresAt:       time.Now().Add(fxQuoteTTL),
+	})
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "Create fx quote failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create fx quote failed")
+	}
+
+	return toFXQuoteResponse(quote), nil
+}
+
+func (s WalletService) Deposit(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Deposit failed")
+	}
+
+	if request.Currency != "" && request.Currency != w.Currency {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet currency is %s, not %s", w.Currency, request.Currency))
+	}
+
+	err = ValidateBalanceChangeRequest(w.Currency, request)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	amount := request.Amount.WithCurrency(w.Currency)
+
+	change, err := s.WalletStore.Deposit(ctx, walletId, amount, meta)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Deposit failed")
+	}
+
+	return toBalanceChangeResponse(change), nil
+}
+
+// Withdraw debits the wallet down to the floor allowed by its type:
+// Savings and Crypto Wallet stop at zero, Credit Card at the credit limit of
+// its currency.
+func (s WalletService) Withdraw(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Withdraw failed")
+	}
+
+	if request.Currency != "" && request.Currency != w.Currency {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet currency is %s, not %s", w.Currency, request.Currency))
+	}
+
+	err = ValidateBalanceChangeRequest(w.Currency, request)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	amount := request.Amount.WithCurrency(w.Currency)
+
+	minBalance := balanceRuleFor(w.WalletType).minBalance(w.Currency)
+
+	change, err := s.WalletStore.Withdraw(ctx, walletId, amount, minBalance, meta)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Withdraw failed")
+	}
+
+	return toBalanceChangeResponse(change), nil
+}
+
+// Adjust corrects a balance in either direction, for operators fixing
+// mistakes. It bypasses the floor of the wallet type.
+func (s WalletService) Adjust(ctx context.Context, walletId int, request *AdjustmentRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Adjust failed")
+	}
+
+	if request.Currency != "" && request.Currency != w.Currency {
+		return nil, apperrs.NewUnprocessableEntity(fmt.Sprintf("wallet currency is %s, not %s", w.Currency, request.Currency))
+	}
+
+	err = ValidateAdjustmentRequest(w.Currency, request)
+
+	if err != nil {
+		s.logger().InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	amount := request.Amount.WithCurrency(w.Currency)
+
+	change, err := s.WalletStore.Adjust(ctx, walletId, amount, strings.TrimSpace(request.Reason), meta)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Adjust failed")
+	}
+
+	return toBalanceChangeResponse(change), nil
+}
+
+func (s WalletService) GetTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (*TransactionPage, error) {
+
+	err := ValidatePagination(limit, offset)
+
+	if err != nil {
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	transactions, err := s.WalletStore.FindTransactionsByWalletId(ctx, walletId, limit, offset)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Get transactions failed")
+	}
+
+	page := TransactionPage{
+		Transactions: make([]Transaction, 0, len(transactions)),
+		Limit:        limit,
+		Offset:       offset,
+	}
+	for _, t := range transactions {
+		page.Transactions = append(page.Transactions, toTransactionResponse(t))
+	}
+
+	return &page, nil
+}
+
+// ReconcileWallet compares the stored balance against the sum of the ledger.
+func (s WalletService) ReconcileWallet(ctx context.Context, walletId int) (*Reconciliation, error) {
+
+	w, err := s.WalletStore.FindByWalletId(ctx, walletId)
+
+	if err != nil {
+		return nil, s.storeError(ctx, err, "Reconcile wallet failed")
+	}
+
+	ledgerBalance, err := s.WalletStore.SumTransactionsByWalletId(ctx, walletId)
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "Reconcile wallet failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Reconcile wallet failed")
+	}
+
+	balanced := w.Balance.Cmp(ledgerBalance) == 0
+
+	if !balanced {
+		s.logger().WarnContext(ctx, "Ledger mismatch", "wallet_id", walletId, "balance", w.Balance.String(), "ledger", ledgerBalance.String())
+	}
+
+	return &Reconciliation{
+		WalletID:      walletId,
+		Balance:       w.Balance,
+		LedgerBalance: ledgerBalance,
+		Balanced:      balanced,
+	}, nil
+}
+
+func toWalletResponse(w postgres.Wallet) Wallet {
+	return Wallet{
+		ID:         w.ID,
+		UserID:     w.UserID,
+		UserName:   w.UserName,
+		WalletName: w.WalletName,
+		WalletType: w.WalletType,
+		Balance:    w.Balance,
+		Currency:   w.Currency,
+		Status:     w.Status,
+		Version:    w.Version,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+func toTransactionResponse(t postgres.Transaction) Transaction {
+	return Transaction{
+		ID:                   t.ID,
+		WalletID:             t.WalletID,
+		Type:                 t.Type,
+		Amount:               t.Amount,
+		BalanceAfter:         t.BalanceAfter,
+		Currency:             t.Currency,
+		CounterpartyWalletID: t.CounterpartyWalletID,
+		FXRate:               t.FXRate,
+		FXSpread:             t.FXSpread,
+		FXQuoteID:            t.FXQuoteID,
+		Reason:               t.Reason,
+		CreatedAt:            t.CreatedAt,
+	}
+}
+
+func toBalanceChangeResponse(change *postgres.BalanceChange) *BalanceChange {
+	return &BalanceChange{
+		Wallet:      toWalletResponse(change.Wallet),
+		Transaction: toTransactionResponse(change.Transaction),
+	}
+}
+
+// storeError maps sentinel errors from the store to an HTTP error,
+// falling back to a 500 with the given message. Mapped errors are the
+// caller's doing and logged at info, the others at error.
+func (s WalletService) storeError(ctx context.Context, err error, message string) error {
+	httpErr := mapStoreError(err)
+	if httpErr == nil {
+		s.logger().ErrorContext(ctx, message, "err", err)
+		return apperrs.NewInternalServerError(message)
+	}
+	s.logger().InfoContext(ctx, message, "err", err)
+	return httpErr
+}
+
+func mapStoreError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		return apperrs.NewNotFoundError(err.Error())
+	case errors.Is(err, postgres.ErrInsufficientFunds):
+		return apperrs.NewUnprocessableEntity(err.Error())
+	case errors.Is(err, postgres.ErrWalletInactive), errors.Is(err, postgres.ErrBalanceNotZero):
+		return apperrs.NewUnprocessableEntity(err.Error())
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		return apperrs.NewUnprocessableEntity(err.Error())
+	case errors.Is(err, postgres.ErrVersionConflict):
+		return apperrs.NewPreconditionFailedError(err.Error())
+	case errors.Is(err, postgres.ErrRateNotFound), errors.Is(err, postgres.ErrQuoteExpired):
+		return apperrs.NewUnprocessableEntity(err.Error())
+	}
+	return nil
+}
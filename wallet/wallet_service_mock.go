@@ -1,8 +1,11 @@
 package wallet
 
 import (
+	"context"
 	"time"
 
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,36 +14,88 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) GetAllWallets() ([]Wallet, error) {
-	args := m.Called()
+func (m *MockService) GetAllWallets(ctx context.Context) ([]Wallet, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]Wallet), args.Error(1)
 }
 
-func (m *MockService) GetWalletsByWalletType(walletType string) ([]Wallet, error) {
-	args := m.Called(walletType)
+func (m *MockService) ListWallets(ctx context.Context, query *WalletQuery) (*WalletPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(*WalletPage), args.Error(1)
+}
+
+func (m *MockService) GetWalletById(ctx context.Context, walletId int) (*Wallet, error) {
+	args := m.Called(ctx, walletId)
+	return args.Get(0).(*Wallet), args.Error(1)
+}
+
+func (m *MockService) GetWalletsByWalletType(ctx context.Context, walletType string) ([]Wallet, error) {
+	args := m.Called(ctx, walletType)
 	return args.Get(0).([]Wallet), args.Error(1)
 }
 
-func (m *MockService) GetWalletsByUserId(userId int) ([]Wallet, error) {
-	args := m.Called(userId)
+func (m *MockService) GetWalletsByUserId(ctx context.Context, userId int) ([]Wallet, error) {
+	args := m.Called(ctx, userId)
 	return args.Get(0).([]Wallet), args.Error(1)
 }
 
-func (m *MockService) CreateWallet(wallet *WalletRequest) (*Wallet, error) {
-	args := m.Called(wallet)
+func (m *MockService) CreateWallet(ctx context.Context, wallet *WalletRequest, meta postgres.AuditMeta) (*Wallet, error) {
+	args := m.Called(ctx, wallet, meta)
 	return args.Get(0).(*Wallet), args.Error(1)
 }
 
-func (m *MockService) DeleteWalletByUserId(userId string) (int64, error) {
-	args := m.Called(userId)
+func (m *MockService) DeleteWalletByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
+	args := m.Called(ctx, userId, meta)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockService) UpdateWalletByWalletId(walletId int, request *WalletRequest) (*Wallet, error) {
-	args := m.Called(walletId, request)
+func (m *MockService) DeleteWalletById(ctx context.Context, walletId int, meta postgres.AuditMeta) (*Wallet, error) {
+	args := m.Called(ctx, walletId, meta)
+	return args.Get(0).(*Wallet), args.Error(1)
+}
+
+func (m *MockService) UpdateWalletByWalletId(ctx context.Context, walletId int, version int, request *WalletUpdateRequest, meta postgres.AuditMeta) (*Wallet, error) {
+	args := m.Called(ctx, walletId, version, request, meta)
+	return args.Get(0).(*Wallet), args.Error(1)
+}
+
+func (m *MockService) Transfer(ctx context.Context, request *TransferRequest, meta postgres.AuditMeta) (*Transfer, error) {
+	args := m.Called(ctx, request, meta)
+	return args.Get(0).(*Transfer), args.Error(1)
+}
+func (m *MockService) GetTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (*TransactionPage, error) {
+	args := m.Called(ctx, walletId, limit, offset)
+	return args.Get(0).(*TransactionPage), args.Error(1)
+}
+
+func (m *MockService) ReconcileWallet(ctx context.Context, walletId int) (*Reconciliation, error) {
+	args := m.Called(ctx, walletId)
+	return args.Get(0).(*Reconciliation), args.Error(1)
+}
+func (m *MockService) Deposit(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	args := m.Called(ctx, walletId, request, meta)
+	return args.Get(0).(*BalanceChange), args.Error(1)
+}
+
+func (m *MockService) Withdraw(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	args := m.Called(ctx, walletId, request, meta)
+	return args.Get(0).(*BalanceChange), args.Error(1)
+}
+
+func (m *MockService) Adjust(ctx context.Context, walletId int, request *AdjustmentRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	args := m.Called(ctx, walletId, request, meta)
+	return args.Get(0).(*BalanceChange), args.Error(1)
+}
+
+func (m *MockService) ChangeWalletStatus(ctx context.Context, walletId int, status string, meta postgres.AuditMeta) (*Wallet, error) {
+	args := m.Called(ctx, walletId, status, meta)
 	return args.Get(0).(*Wallet), args.Error(1)
 }
 
+func (m *MockService) CreateFXQuote(ctx context.Context, request *FXQuoteRequest) (*FXQuote, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*FXQuote), args.Error(1)
+}
 
 
 // Helper function to convert WalletRequest to Wallet
@@ -51,7 +106,7 @@ func toWallet(request *WalletRequest) *Wallet {
 		UserName:   request.UserName,
 		WalletName: request.WalletName,
 		WalletType: request.WalletType,
-		Balance:    0,
+		Balance:    money.Money{},
 		CreatedAt:  time.Time{},
 	}
 }
@@ -1,10 +1,18 @@
 package wallet_test
 
 import (
+    "context"
+    "fmt"
+    "log/slog"
+    "net/http"
     "testing"
+    "time"
+
+    "github.com/labstack/echo/v4"
 
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
+    "github.com/KKGo-Software-engineering/fun-exercise-api/money"
 	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
     "github.com/KKGo-Software-engineering/fun-exercise-api/wallet"
 )
@@ -14,67 +22,131 @@ type MockWalletStore struct {
     mock.Mock
 }
 
-func (m *MockWalletStore) FindAll() ([]postgres.Wallet, error) {
-    args := m.Called()
+func (m *MockWalletStore) FindAll(ctx context.Context) ([]postgres.Wallet, error) {
+    args := m.Called(ctx)
     return args.Get(0).([]postgres.Wallet), args.Error(1)
 }
 
-func (m *MockWalletStore) FindByWalletType(walletType string) ([]postgres.Wallet, error) {
-    args := m.Called(walletType)
+func (m *MockWalletStore) ListWallets(ctx context.Context, filter postgres.WalletFilter) ([]postgres.Wallet, error) {
+    args := m.Called(ctx, filter)
     return args.Get(0).([]postgres.Wallet), args.Error(1)
 }
 
-func (m *MockWalletStore) FindByWalletId(walletId int) (*postgres.Wallet, error) {
-    args := m.Called(walletId)
+func (m *MockWalletStore) FindByWalletType(ctx context.Context, walletType string) ([]postgres.Wallet, error) {
+    args := m.Called(ctx, walletType)
+    return args.Get(0).([]postgres.Wallet), args.Error(1)
+}
+
+func (m *MockWalletStore) FindByWalletId(ctx context.Context, walletId int) (*postgres.Wallet, error) {
+    args := m.Called(ctx, walletId)
     return args.Get(0).(*postgres.Wallet), args.Error(1)
 }
 
-func (m *MockWalletStore) FindByUserId(userId int) ([]postgres.Wallet, error) {
-    args := m.Called(userId)
+func (m *MockWalletStore) FindByUserId(ctx context.Context, userId int) ([]postgres.Wallet, error) {
+    args := m.Called(ctx, userId)
     return args.Get(0).([]postgres.Wallet), args.Error(1)
 }
 
-func (m *MockWalletStore) Create(wallet *postgres.Wallet) (*postgres.Wallet, error) {
-    args := m.Called(wallet)
+func (m *MockWalletStore) Create(ctx context.Context, wallet *postgres.Wallet, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+    args := m.Called(ctx, wallet, meta)
     return args.Get(0).(*postgres.Wallet), args.Error(1)
 }
 
-func (m *MockWalletStore) CountByCriteria(wallet postgres.Wallet) (int, error) {
-    args := m.Called(wallet)
+func (m *MockWalletStore) CountByCriteria(ctx context.Context, wallet postgres.Wallet) (int, error) {
+    args := m.Called(ctx, wallet)
     return args.Int(0), args.Error(1)
 }
 
-func (m *MockWalletStore) DeleteByUserId(userId string) (int64, error) {
-    args := m.Called(userId)
+func (m *MockWalletStore) DeleteByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
+    args := m.Called(ctx, userId, meta)
     return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockWalletStore) UpdateByWalletId(walletId int, wallet postgres.Wallet) (int64, error) {
-    args := m.Called(walletId, wallet)
+func (m *MockWalletStore) DeleteByWalletId(ctx context.Context, walletId int, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+    args := m.Called(ctx, walletId, meta)
+    return args.Get(0).(*postgres.Wallet), args.Error(1)
+}
+
+func (m *MockWalletStore) UpdateByWalletId(ctx context.Context, walletId int, version int, wallet postgres.Wallet, meta postgres.AuditMeta) (int64, error) {
+    args := m.Called(ctx, walletId, version, wallet, meta)
     return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockWalletStore) SetStatus(ctx context.Context, walletId int, version int, status string, meta postgres.AuditMeta) (*postgres.Wallet, error) {
+    args := m.Called(ctx, walletId, version, status, meta)
+    return args.Get(0).(*postgres.Wallet), args.Error(1)
+}
+
+func (m *MockWalletStore) Transfer(ctx context.Context, fromWalletId int, toWalletId int, amount money.Money, minBalance money.Money, quote *postgres.FXQuote, meta postgres.AuditMeta) (*postgres.Transfer, error) {
+    args := m.Called(ctx, fromWalletId, toWalletId, amount, minBalance, quote, meta)
+    return args.Get(0).(*postgres.Transfer), args.Error(1)
+}
+
+func (m *MockWalletStore) Deposit(ctx context.Context, walletId int, amount money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+    args := m.Called(ctx, walletId, amount, meta)
+    return args.Get(0).(*postgres.BalanceChange), args.Error(1)
+}
+
+func (m *MockWalletStore) Withdraw(ctx context.Context, walletId int, amount money.Money, minBalance money.Money, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+    args := m.Called(ctx, walletId, amount, minBalance, meta)
+    return args.Get(0).(*postgres.BalanceChange), args.Error(1)
+}
+
+func (m *MockWalletStore) Adjust(ctx context.Context, walletId int, amount money.Money, reason string, meta postgres.AuditMeta) (*postgres.BalanceChange, error) {
+    args := m.Called(ctx, walletId, amount, reason, meta)
+    return args.Get(0).(*postgres.BalanceChange), args.Error(1)
+}
+
+func (m *MockWalletStore) FindTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) ([]postgres.Transaction, error) {
+    args := m.Called(ctx, walletId, limit, offset)
+    return args.Get(0).([]postgres.Transaction), args.Error(1)
+}
+
+func (m *MockWalletStore) SumTransactionsByWalletId(ctx context.Context, walletId int) (money.Money, error) {
+    args := m.Called(ctx, walletId)
+    return args.Get(0).(money.Money), args.Error(1)
+}
+
+func (m *MockWalletStore) CreateFXQuote(ctx context.Context, quote *postgres.FXQuote) (*postgres.FXQuote, error) {
+    args := m.Called(ctx, quote)
+    return args.Get(0).(*postgres.FXQuote), args.Error(1)
+}
+
+func (m *MockWalletStore) FindFXQuote(ctx context.Context, id string) (*postgres.FXQuote, error) {
+    args := m.Called(ctx, id)
+    return args.Get(0).(*postgres.FXQuote), args.Error(1)
+}
+
+type MockRateProvider struct {
+    mock.Mock
+}
+
+func (m *MockRateProvider) Rate(ctx context.Context, from string, to string) (*postgres.FXRate, error) {
+    args := m.Called(ctx, from, to)
+    return args.Get(0).(*postgres.FXRate), args.Error(1)
+}
+
 func TestGetAllWallets(t *testing.T) {
     // Define test data
     storeWallet := []postgres.Wallet{
-        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: 100.00},
-        {ID: 2, UserID: 456, UserName: "user2", WalletName: "wallet2", WalletType: "type2", Balance: 200.00},
+        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: money.MustParse("100.00")},
+        {ID: 2, UserID: 456, UserName: "user2", WalletName: "wallet2", WalletType: "type2", Balance: money.MustParse("200.00")},
     }
 
     testWallets := []wallet.Wallet{
-        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: 100.00},
-        {ID: 2, UserID: 456, UserName: "user2", WalletName: "wallet2", WalletType: "type2", Balance: 200.00},
+        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: money.MustParse("100.00")},
+        {ID: 2, UserID: 456, UserName: "user2", WalletName: "wallet2", WalletType: "type2", Balance: money.MustParse("200.00")},
     }
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("FindAll").Return(storeWallet, nil)
+    mockStore.On("FindAll", mock.Anything).Return(storeWallet, nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    wallets, err := walletService.GetAllWallets()
+    wallets, err := walletService.GetAllWallets(context.Background())
 
     // Assert the result
     assert.NoError(t, err)
@@ -87,24 +159,24 @@ func TestGetWalletsByWalletType(t *testing.T) {
     walletType := "type1"
     
     storeWallets := []postgres.Wallet{
-        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: walletType, Balance: 100.00},
+        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: walletType, Balance: money.MustParse("100.00")},
     }
 
     testWallets := []wallet.Wallet{
-        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: walletType, Balance: 100.00},
+        {ID: 1, UserID: 123, UserName: "user1", WalletName: "wallet1", WalletType: walletType, Balance: money.MustParse("100.00")},
     }
 
 
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("FindByWalletType", walletType).Return(storeWallets, nil)
+    mockStore.On("FindByWalletType", mock.Anything, walletType).Return(storeWallets, nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    wallets, err := walletService.GetWalletsByWalletType(walletType)
+    wallets, err := walletService.GetWalletsByWalletType(context.Background(), walletType)
 
     // Assert the result
     assert.NoError(t, err)
@@ -117,22 +189,22 @@ func TestGetWalletsByUserId(t *testing.T) {
     userID := 123
 
     storeWallets := []postgres.Wallet{
-        {ID: 1, UserID: userID, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: 100.00},
+        {ID: 1, UserID: userID, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: money.MustParse("100.00")},
     }
 
     testWallets := []wallet.Wallet{
-        {ID: 1, UserID: userID, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: 100.00},
+        {ID: 1, UserID: userID, UserName: "user1", WalletName: "wallet1", WalletType: "type1", Balance: money.MustParse("100.00")},
     }
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("FindByUserId", userID).Return(storeWallets, nil)
+    mockStore.On("FindByUserId", mock.Anything, userID).Return(storeWallets, nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    wallets, err := walletService.GetWalletsByUserId(userID)
+    wallets, err := walletService.GetWalletsByUserId(context.Background(), userID)
 
     // Assert the result
     assert.NoError(t, err)
@@ -147,7 +219,8 @@ func TestCreateWallet(t *testing.T) {
         UserName:   "user1",
         WalletName: "wallet1",
         WalletType: "Savings",
-        Balance:    600.00,
+        Balance:    money.MustParse("600.00"),
+        Currency:   "THB",
     }
     
     createWallet := &postgres.Wallet{
@@ -157,6 +230,7 @@ func TestCreateWallet(t *testing.T) {
         WalletName: request.WalletName,
         WalletType: request.WalletType,
         Balance:    request.Balance,
+        Currency:   request.Currency,
     }
 
     testWallet := &wallet.Wallet{
@@ -166,18 +240,19 @@ func TestCreateWallet(t *testing.T) {
         WalletName: request.WalletName,
         WalletType: request.WalletType,
         Balance:    request.Balance,
+        Currency:   request.Currency,
     }
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("Create", mock.AnythingOfType("*postgres.Wallet")).Return(createWallet, nil)
-    mockStore.On("CountByCriteria", mock.AnythingOfType("postgres.Wallet")).Return(0, nil)
+    mockStore.On("Create", mock.Anything, mock.AnythingOfType("*postgres.Wallet"), mock.Anything).Return(createWallet, nil)
+    mockStore.On("CountByCriteria", mock.Anything, mock.AnythingOfType("postgres.Wallet")).Return(0, nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    walletResponse, err := walletService.CreateWallet(request)
+    walletResponse, err := walletService.CreateWallet(context.Background(), request, postgres.AuditMeta{})
 
     // Assert the result
     assert.NoError(t, err)
@@ -192,13 +267,13 @@ func TestDeleteWalletByUserId(t *testing.T) {
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("DeleteByUserId", userID).Return(int64(1), nil)
+    mockStore.On("DeleteByUserId", mock.Anything, userID, mock.Anything).Return(int64(1), nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    deletedRows, err := walletService.DeleteWalletByUserId(userID)
+    deletedRows, err := walletService.DeleteWalletByUserId(context.Background(), userID, postgres.AuditMeta{})
 
     // Assert the result
     assert.NoError(t, err)
@@ -206,15 +281,56 @@ func TestDeleteWalletByUserId(t *testing.T) {
     mockStore.AssertExpectations(t)
 }
 
+func TestDeleteWalletById(t *testing.T) {
+    t.Run("given empty active wallet should close it", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("DeleteByWalletId", mock.Anything, 1, mock.Anything).Return(&postgres.Wallet{ID: 1, Status: postgres.WalletClosed, Version: 4}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        result, err := walletService.DeleteWalletById(context.Background(), 1, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, postgres.WalletClosed, result.Status)
+        mockStore.AssertExpectations(t)
+    })
+
+    testCases := []struct {
+        name     string
+        err      error
+        wantCode int
+    }{
+        {name: "given wallet with money should return 422", err: fmt.Errorf("wallet 1: %w", postgres.ErrBalanceNotZero), wantCode: http.StatusUnprocessableEntity},
+        {name: "given frozen wallet should return 422", err: fmt.Errorf("wallet 1 is frozen: %w", postgres.ErrWalletInactive), wantCode: http.StatusUnprocessableEntity},
+        {name: "given unknown wallet should return 404", err: fmt.Errorf("wallet 1: %w", postgres.ErrNotFound), wantCode: http.StatusNotFound},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            mockStore := new(MockWalletStore)
+            mockStore.On("DeleteByWalletId", mock.Anything, 1, mock.Anything).Return((*postgres.Wallet)(nil), tc.err)
+
+            walletService := wallet.WalletService{WalletStore: mockStore}
+
+            _, err := walletService.DeleteWalletById(context.Background(), 1, postgres.AuditMeta{})
+
+            httpErr, ok := err.(*echo.HTTPError)
+            assert.True(t, ok)
+            assert.Equal(t, tc.wantCode, httpErr.Code)
+        })
+    }
+}
+
 func TestUpdateWalletByWalletId(t *testing.T) {
     // Define test data
     walletID := 1
-    request := &wallet.WalletRequest{
+    balance := money.MustParse("650.00")
+    request := &wallet.WalletUpdateRequest{
         UserID:     123,
         UserName:   "user1",
         WalletName: "updated_wallet1",
         WalletType: "Savings",
-        Balance:    650.00,
+        Balance:    &balance,
     }
 
 
@@ -225,19 +341,21 @@ func TestUpdateWalletByWalletId(t *testing.T) {
         UserName:   "user1",
         WalletName: "updated_wallet1",
         WalletType: "Savings",
-        Balance:    650.00,
+        Balance:    money.MustParse("650.00"),
+        Status:     postgres.WalletActive,
+        Version:    3,
     }
 
     // Create a mock instance
     mockStore := new(MockWalletStore)
-    mockStore.On("UpdateByWalletId", walletID, mock.AnythingOfType("postgres.Wallet")).Return(int64(1), nil)
-    mockStore.On("FindByWalletId", walletID).Return(testWallet, nil)
+    mockStore.On("UpdateByWalletId", mock.Anything, walletID, 3, mock.AnythingOfType("postgres.Wallet"), mock.Anything).Return(int64(1), nil)
+    mockStore.On("FindByWalletId", mock.Anything, walletID).Return(testWallet, nil)
 
     // Create WalletService with mock store
     walletService := wallet.WalletService{WalletStore: mockStore}
 
     // Call the function under test
-    updatedWallet, err := walletService.UpdateWalletByWalletId(walletID, request)
+    updatedWallet, err := walletService.UpdateWalletByWalletId(context.Background(), walletID, 3, request, postgres.AuditMeta{})
 
     // Assert the result
     assert.NoError(t, err)
@@ -246,6 +364,860 @@ func TestUpdateWalletByWalletId(t *testing.T) {
     assert.Equal(t, request.UserName, updatedWallet.UserName)
     assert.Equal(t, request.WalletName, updatedWallet.WalletName)
     assert.Equal(t, request.WalletType, updatedWallet.WalletType)
-    assert.Equal(t, request.Balance, updatedWallet.Balance)
+    assert.Equal(t, *request.Balance, updatedWallet.Balance)
     mockStore.AssertExpectations(t)
 }
+
+func TestTransfer(t *testing.T) {
+    request := &wallet.TransferRequest{
+        FromWalletID: 1,
+        ToWalletID:   2,
+        Amount:       money.MustParse("50.00"),
+    }
+
+    savings := &postgres.Wallet{ID: 1, UserID: 123, WalletType: "Savings", Balance: money.MustParse("1000.00")}
+    creditCard := &postgres.Wallet{ID: 1, UserID: 123, WalletType: "Credit Card", Balance: money.MustParse("0"), Currency: "USD"}
+    destination := &postgres.Wallet{ID: 2, UserID: 456, WalletType: "Savings", Balance: money.MustParse("1000.00")}
+
+    t.Run("given enough balance should debit and credit both wallets", func(t *testing.T) {
+        storeTransfer := &postgres.Transfer{
+            FromWallet: postgres.Wallet{ID: 1, UserID: 123, WalletType: "Savings", Balance: money.MustParse("950.00")},
+            ToWallet:   postgres.Wallet{ID: 2, UserID: 456, WalletType: "Savings", Balance: money.MustParse("1050.00")},
+            Amount:     money.MustParse("50.00"),
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(savings, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(destination, nil)
+        mockStore.On("Transfer", mock.Anything, 1, 2, money.MustParse("50.00"), money.MustParse("0.0"), (*postgres.FXQuote)(nil), mock.Anything).Return(storeTransfer, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        transfer, err := walletService.Transfer(context.Background(), request, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, money.MustParse("950.00"), transfer.FromWallet.Balance)
+        assert.Equal(t, money.MustParse("1050.00"), transfer.ToWallet.Balance)
+        assert.Equal(t, money.MustParse("50.00"), transfer.Amount)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given credit card source should allow balance down to credit limit", func(t *testing.T) {
+        storeTransfer := &postgres.Transfer{
+            FromWallet: postgres.Wallet{ID: 1, UserID: 123, WalletType: "Credit Card", Balance: money.MustParse("-50.00")},
+            ToWallet:   postgres.Wallet{ID: 2, UserID: 456, WalletType: "Savings", Balance: money.MustParse("1050.00")},
+            Amount:     money.MustParse("50.00"),
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(creditCard, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(&postgres.Wallet{ID: 2, UserID: 456, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "USD"}, nil)
+        mockStore.On("Transfer", mock.Anything, 1, 2, money.MustParse("50.00").WithCurrency("USD"), money.MustParse("-300.0"), (*postgres.FXQuote)(nil), mock.Anything).Return(storeTransfer, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        transfer, err := walletService.Transfer(context.Background(), request, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, money.MustParse("-50.00"), transfer.FromWallet.Balance)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given insufficient funds should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(savings, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(destination, nil)
+        mockStore.On("Transfer", mock.Anything, 1, 2, money.MustParse("50.00"), money.MustParse("0.0"), (*postgres.FXQuote)(nil), mock.Anything).Return((*postgres.Transfer)(nil), postgres.ErrInsufficientFunds)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Transfer(context.Background(), request, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given unknown wallet should return 404", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(savings, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return((*postgres.Wallet)(nil), postgres.ErrNotFound)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Transfer(context.Background(), request, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusNotFound, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Transfer")
+    })
+
+    t.Run("given amount finer than savings precision should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(savings, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(destination, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Transfer(context.Background(), &wallet.TransferRequest{FromWalletID: 1, ToWalletID: 2, Amount: money.MustParse("0.001")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Transfer")
+    })
+
+    t.Run("given wallets in different currencies should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "THB"}, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(&postgres.Wallet{ID: 2, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "USD"}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Transfer(context.Background(), request, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Transfer")
+    })
+
+    t.Run("given fx quote should credit the quoted amount", func(t *testing.T) {
+        usd := &postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "USD"}
+        thb := &postgres.Wallet{ID: 2, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "THB"}
+        quote := &postgres.FXQuote{
+            ID:              "q1",
+            FromCurrency:    "USD",
+            ToCurrency:      "THB",
+            Amount:          money.MustParse("50.00").WithCurrency("USD"),
+            ConvertedAmount: money.MustParse("1815.87").WithCurrency("THB"),
+            Rate:            "36.5",
+            Spread:          "0.005",
+            ExpiresAt:       time.Now().Add(time.Minute),
+        }
+        storeTransfer := &postgres.Transfer{
+            FromWallet:     postgres.Wallet{ID: 1, Balance: money.MustParse("950.00"), Currency: "USD"},
+            ToWallet:       postgres.Wallet{ID: 2, Balance: money.MustParse("2815.87"), Currency: "THB"},
+            Amount:         quote.Amount,
+            CreditedAmount: quote.ConvertedAmount,
+            Quote:          quote,
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(usd, nil)
+        mockStore.On("FindByWalletId", mock.Anything, 2).Return(thb, nil)
+        mockStore.On("FindFXQuote", mock.Anything, "q1").Return(quote, nil)
+        mockStore.On("Transfer", mock.Anything, 1, 2, quote.Amount, money.MustParse("0"), quote, mock.Anything).Return(storeTransfer, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        transfer, err := walletService.Transfer(context.Background(), &wallet.TransferRequest{FromWalletID: 1, ToWalletID: 2, Amount: money.MustParse("50.00"), QuoteID: "q1"}, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, quote.ConvertedAmount, transfer.CreditedAmount)
+        assert.Equal(t, "q1", transfer.FXQuoteID)
+        assert.Equal(t, "36.5", transfer.FXRate)
+        assert.Equal(t, "0.005", transfer.FXSpread)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given fx quote that cannot be used should return 422", func(t *testing.T) {
+        usd := &postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "USD"}
+        thb := &postgres.Wallet{ID: 2, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "THB"}
+        usedAt := time.Now()
+
+        testCases := []struct {
+            name  string
+            quote postgres.FXQuote
+        }{
+            {name: "expired", quote: postgres.FXQuote{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("50.00"), ExpiresAt: time.Now().Add(-time.Second)}},
+            {name: "already used", quote: postgres.FXQuote{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("50.00"), ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}},
+            {name: "other amount", quote: postgres.FXQuote{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("40.00"), ExpiresAt: time.Now().Add(time.Minute)}},
+            {name: "other pair", quote: postgres.FXQuote{FromCurrency: "EUR", ToCurrency: "THB", Amount: money.MustParse("50.00"), ExpiresAt: time.Now().Add(time.Minute)}},
+        }
+
+        for _, tc := range testCases {
+            t.Run(tc.name, func(t *testing.T) {
+                quote := tc.quote
+                mockStore := new(MockWalletStore)
+                mockStore.On("FindByWalletId", mock.Anything, 1).Return(usd, nil)
+                mockStore.On("FindByWalletId", mock.Anything, 2).Return(thb, nil)
+                mockStore.On("FindFXQuote", mock.Anything, "q1").Return(&quote, nil)
+
+                walletService := wallet.WalletService{WalletStore: mockStore}
+
+                _, err := walletService.Transfer(context.Background(), &wallet.TransferRequest{FromWalletID: 1, ToWalletID: 2, Amount: money.MustParse("50.00"), QuoteID: "q1"}, postgres.AuditMeta{})
+
+                httpErr, ok := err.(*echo.HTTPError)
+                assert.True(t, ok)
+                assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+                mockStore.AssertNotCalled(t, "Transfer")
+            })
+        }
+    })
+
+    t.Run("given same source and destination should not call store", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Transfer(context.Background(), &wallet.TransferRequest{FromWalletID: 1, ToWalletID: 1, Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Transfer")
+    })
+}
+
+func TestDeposit(t *testing.T) {
+    t.Run("given valid amount should credit wallet", func(t *testing.T) {
+        change := &postgres.BalanceChange{
+            Wallet:      postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1050.00")},
+            Transaction: postgres.Transaction{ID: 9, WalletID: 1, Type: postgres.TransactionDeposit, Amount: money.MustParse("50.00"), BalanceAfter: money.MustParse("1050.00")},
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1000.00")}, nil)
+        mockStore.On("Deposit", mock.Anything, 1, money.MustParse("50.00"), mock.Anything).Return(change, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        result, err := walletService.Deposit(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, money.MustParse("1050.00"), result.Wallet.Balance)
+        assert.Equal(t, "deposit", result.Transaction.Type)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given crypto amount with eight decimals should be accepted", func(t *testing.T) {
+        change := &postgres.BalanceChange{
+            Wallet: postgres.Wallet{ID: 3, WalletType: "Crypto Wallet", Balance: money.MustParse("100.00000001"), Currency: "BTC"},
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 3).Return(&postgres.Wallet{ID: 3, WalletType: "Crypto Wallet", Balance: money.MustParse("100.00"), Currency: "BTC"}, nil)
+        mockStore.On("Deposit", mock.Anything, 3, money.MustParse("0.00000001").WithCurrency("BTC"), mock.Anything).Return(change, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Deposit(context.Background(), 3, &wallet.BalanceChangeRequest{Amount: money.MustParse("0.00000001")}, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given currency other than the wallet's should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("1000.00"), Currency: "THB"}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Deposit(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00"), Currency: "USD"}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Deposit")
+    })
+
+    t.Run("given unknown wallet should return 404", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return((*postgres.Wallet)(nil), postgres.ErrNotFound)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Deposit(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusNotFound, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Deposit")
+    })
+}
+
+func TestChangeWalletStatus(t *testing.T) {
+    testCases := []struct {
+        name     string
+        from     string
+        balance  string
+        to       string
+        wantCode int
+    }{
+        {name: "given active wallet should freeze", from: postgres.WalletActive, balance: "10.00", to: postgres.WalletFrozen},
+        {name: "given frozen wallet should unfreeze", from: postgres.WalletFrozen, balance: "10.00", to: postgres.WalletActive},
+        {name: "given active wallet with zero balance should close", from: postgres.WalletActive, balance: "0", to: postgres.WalletClosed},
+        {name: "given non-zero balance should not close", from: postgres.WalletActive, balance: "10.00", to: postgres.WalletClosed, wantCode: http.StatusUnprocessableEntity},
+        {name: "given frozen wallet should not close", from: postgres.WalletFrozen, balance: "0", to: postgres.WalletClosed, wantCode: http.StatusUnprocessableEntity},
+        {name: "given closed wallet should not reopen", from: postgres.WalletClosed, balance: "0", to: postgres.WalletActive, wantCode: http.StatusUnprocessableEntity},
+        {name: "given active wallet should not unfreeze", from: postgres.WalletActive, balance: "0", to: postgres.WalletActive, wantCode: http.StatusUnprocessableEntity},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            existing := &postgres.Wallet{ID: 1, Balance: money.MustParse(tc.balance).WithCurrency("THB"), Currency: "THB", Status: tc.from, Version: 3}
+
+            mockStore := new(MockWalletStore)
+            mockStore.On("FindByWalletId", mock.Anything, 1).Return(existing, nil)
+            mockStore.On("SetStatus", mock.Anything, 1, 3, tc.to, mock.Anything).Return(&postgres.Wallet{ID: 1, Status: tc.to, Version: 4}, nil)
+
+            walletService := wallet.WalletService{WalletStore: mockStore}
+
+            result, err := walletService.ChangeWalletStatus(context.Background(), 1, tc.to, postgres.AuditMeta{})
+
+            if tc.wantCode == 0 {
+                assert.NoError(t, err)
+                assert.Equal(t, tc.to, result.Status)
+                return
+            }
+            httpErr, ok := err.(*echo.HTTPError)
+            assert.True(t, ok)
+            assert.Equal(t, tc.wantCode, httpErr.Code)
+            mockStore.AssertNotCalled(t, "SetStatus")
+        })
+    }
+
+    t.Run("given wallet changed after the check should return 409", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, Status: postgres.WalletActive, Version: 3}, nil)
+        mockStore.On("SetStatus", mock.Anything, 1, 3, postgres.WalletFrozen, mock.Anything).Return((*postgres.Wallet)(nil), postgres.ErrVersionConflict)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.ChangeWalletStatus(context.Background(), 1, postgres.WalletFrozen, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusConflict, httpErr.Code)
+    })
+}
+
+func TestBalanceChangesOnInactiveWallets(t *testing.T) {
+    t.Run("given frozen wallet deposit should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Currency: "THB", Status: postgres.WalletFrozen}, nil)
+        mockStore.On("Deposit", mock.Anything, 1, money.MustParse("50.00").WithCurrency("THB"), mock.Anything).Return((*postgres.BalanceChange)(nil), fmt.Errorf("wallet 1 is frozen: %w", postgres.ErrWalletInactive))
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Deposit(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        assert.Contains(t, httpErr.Message, "wallet 1 is frozen")
+    })
+
+    t.Run("given closed wallet update should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Currency: "THB", Status: postgres.WalletClosed, Version: 3}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 3, &wallet.WalletUpdateRequest{
+            UserID:     123,
+            UserName:   "user1",
+            WalletName: "renamed",
+            WalletType: "Savings",
+            Currency:   "THB",
+        }, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        mockStore.AssertNotCalled(t, "UpdateByWalletId")
+    })
+
+    t.Run("given frozen wallet update should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, UserID: 123, WalletType: "Savings", Currency: "THB", Status: postgres.WalletFrozen, Version: 3}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 3, &wallet.WalletUpdateRequest{
+            UserID:     456,
+            UserName:   "user2",
+            WalletName: "moved",
+            WalletType: "Savings",
+            Currency:   "THB",
+        }, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        assert.Equal(t, "wallet 1 is frozen", httpErr.Message)
+        mockStore.AssertNotCalled(t, "UpdateByWalletId")
+    })
+
+    t.Run("given wallet with money bulk delete should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("DeleteByUserId", mock.Anything, "1", mock.Anything).Return(int64(0), fmt.Errorf("wallet 2: %w", postgres.ErrBalanceNotZero))
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.DeleteWalletByUserId(context.Background(), "1", postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+    })
+}
+
+func TestAdjust(t *testing.T) {
+    t.Run("given negative amount with reason should book adjustment", func(t *testing.T) {
+        change := &postgres.BalanceChange{
+            Wallet:      postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("-20.00"), Currency: "THB"},
+            Transaction: postgres.Transaction{ID: 9, WalletID: 1, Type: postgres.TransactionAdjustment, Amount: money.MustParse("-30.00"), BalanceAfter: money.MustParse("-20.00"), Currency: "THB", Reason: "duplicate deposit"},
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("10.00"), Currency: "THB"}, nil)
+        mockStore.On("Adjust", mock.Anything, 1, money.MustParse("-30.00").WithCurrency("THB"), "duplicate deposit", mock.Anything).Return(change, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        result, err := walletService.Adjust(context.Background(), 1, &wallet.AdjustmentRequest{Amount: money.MustParse("-30.00"), Reason: " duplicate deposit "}, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        assert.Equal(t, "adjustment", result.Transaction.Type)
+        assert.Equal(t, "duplicate deposit", result.Transaction.Reason)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given no reason should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Currency: "THB"}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Adjust(context.Background(), 1, &wallet.AdjustmentRequest{Amount: money.MustParse("5.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Adjust")
+    })
+}
+
+func TestWithdraw(t *testing.T) {
+    testCases := []struct {
+        name       string
+        walletType string
+        currency   string
+        minBalance string
+    }{
+        {name: "given savings wallet should not go below zero", walletType: "Savings", currency: "THB", minBalance: "0"},
+        {name: "given THB credit card should go down to its credit limit", walletType: "Credit Card", currency: "THB", minBalance: "-10000"},
+        {name: "given USD credit card should go down to its credit limit", walletType: "Credit Card", currency: "USD", minBalance: "-300"},
+        {name: "given crypto wallet should not go below zero", walletType: "Crypto Wallet", currency: "BTC", minBalance: "0"},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            change := &postgres.BalanceChange{
+                Wallet: postgres.Wallet{ID: 1, WalletType: tc.walletType, Balance: money.MustParse("950.00")},
+            }
+
+            mockStore := new(MockWalletStore)
+            mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: tc.walletType, Balance: money.MustParse("1000.00"), Currency: tc.currency}, nil)
+            mockStore.On("Withdraw", mock.Anything, 1, money.MustParse("50.00").WithCurrency(tc.currency), money.MustParse(tc.minBalance), mock.Anything).Return(change, nil)
+
+            walletService := wallet.WalletService{WalletStore: mockStore}
+
+            _, err := walletService.Withdraw(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+            assert.NoError(t, err)
+            mockStore.AssertExpectations(t)
+        })
+    }
+
+    t.Run("given insufficient funds should return 422", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("10.00")}, nil)
+        mockStore.On("Withdraw", mock.Anything, 1, money.MustParse("50.00"), money.MustParse("0.0"), mock.Anything).Return((*postgres.BalanceChange)(nil), postgres.ErrInsufficientFunds)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Withdraw(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("50.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given negative amount should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("10.00")}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.Withdraw(context.Background(), 1, &wallet.BalanceChangeRequest{Amount: money.MustParse("-50.00")}, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "Withdraw")
+    })
+}
+
+func TestGetTransactionsByWalletId(t *testing.T) {
+    t.Run("given ledger entries should return page of transactions", func(t *testing.T) {
+        storeTransactions := []postgres.Transaction{
+            {ID: 2, WalletID: 1, Type: postgres.TransactionTransferOut, Amount: money.MustParse("-50.00"), BalanceAfter: money.MustParse("950.00"), CounterpartyWalletID: 2},
+            {ID: 1, WalletID: 1, Type: postgres.TransactionCreate, Amount: money.MustParse("1000.00"), BalanceAfter: money.MustParse("1000.00")},
+        }
+
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindTransactionsByWalletId", mock.Anything, 1, 20, 0).Return(storeTransactions, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        page, err := walletService.GetTransactionsByWalletId(context.Background(), 1, 20, 0)
+
+        assert.NoError(t, err)
+        assert.Equal(t, 20, page.Limit)
+        assert.Equal(t, 0, page.Offset)
+        assert.Equal(t, []wallet.Transaction{
+            {ID: 2, WalletID: 1, Type: "transfer_out", Amount: money.MustParse("-50.00"), BalanceAfter: money.MustParse("950.00"), CounterpartyWalletID: 2},
+            {ID: 1, WalletID: 1, Type: "create", Amount: money.MustParse("1000.00"), BalanceAfter: money.MustParse("1000.00")},
+        }, page.Transactions)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given no ledger entries should return empty page", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindTransactionsByWalletId", mock.Anything, 1, 20, 40).Return([]postgres.Transaction(nil), nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        page, err := walletService.GetTransactionsByWalletId(context.Background(), 1, 20, 40)
+
+        assert.NoError(t, err)
+        assert.NotNil(t, page.Transactions)
+        assert.Empty(t, page.Transactions)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given limit above maximum should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.GetTransactionsByWalletId(context.Background(), 1, 1000, 0)
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "FindTransactionsByWalletId")
+    })
+
+    t.Run("given store failure should return 500 without its details", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindTransactionsByWalletId", mock.Anything, 1, 20, 0).Return([]postgres.Transaction(nil), fmt.Errorf("pq: relation \"wallet_transaction\" does not exist"))
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.GetTransactionsByWalletId(context.Background(), 1, 20, 0)
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+        assert.Equal(t, "Get transactions failed", httpErr.Message)
+    })
+}
+
+func TestReconcileWallet(t *testing.T) {
+    t.Run("given ledger matching balance should be balanced", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, Balance: money.MustParse("950.10")}, nil)
+        mockStore.On("SumTransactionsByWalletId", mock.Anything, 1).Return(money.MustParse("950.10"), nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        reconciliation, err := walletService.ReconcileWallet(context.Background(), 1)
+
+        assert.NoError(t, err)
+        assert.True(t, reconciliation.Balanced)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given ledger drifting from balance should not be balanced", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, Balance: money.MustParse("1000.00")}, nil)
+        mockStore.On("SumTransactionsByWalletId", mock.Anything, 1).Return(money.MustParse("950.00"), nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        reconciliation, err := walletService.ReconcileWallet(context.Background(), 1)
+
+        assert.NoError(t, err)
+        assert.False(t, reconciliation.Balanced)
+        assert.Equal(t, money.MustParse("1000.00"), reconciliation.Balance)
+        assert.Equal(t, money.MustParse("950.00"), reconciliation.LedgerBalance)
+        mockStore.AssertExpectations(t)
+    })
+}
+
+func TestUpdateWalletByWalletIdRejectsCurrencyChange(t *testing.T) {
+    mockStore := new(MockWalletStore)
+    mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("650.00"), Currency: "THB"}, nil)
+
+    walletService := wallet.WalletService{WalletStore: mockStore}
+
+    _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 0, &wallet.WalletUpdateRequest{
+        UserID:     123,
+        UserName:   "user1",
+        WalletName: "wallet1",
+        WalletType: "Savings",
+        Currency:   "USD",
+    }, postgres.AuditMeta{})
+
+    httpErr, ok := err.(*echo.HTTPError)
+    assert.True(t, ok)
+    assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+    mockStore.AssertNotCalled(t, "UpdateByWalletId")
+}
+
+func TestUpdateWalletByWalletIdRejectsBalanceChange(t *testing.T) {
+    mockStore := new(MockWalletStore)
+    mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("650.00"), Currency: "THB", Status: postgres.WalletActive, Version: 3}, nil)
+
+    walletService := wallet.WalletService{WalletStore: mockStore}
+
+    balance := money.MustParse("1000.00")
+    _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 3, &wallet.WalletUpdateRequest{
+        UserID:     123,
+        UserName:   "user1",
+        WalletName: "wallet1",
+        WalletType: "Savings",
+        Balance:    &balance,
+    }, postgres.AuditMeta{})
+
+    httpErr, ok := err.(*echo.HTTPError)
+    assert.True(t, ok)
+    assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+    assert.Contains(t, httpErr.Message, "/api/v1/wallets/1/adjustments")
+    mockStore.AssertNotCalled(t, "UpdateByWalletId")
+}
+
+func TestCreateFXQuote(t *testing.T) {
+    t.Run("given known pair should quote converted amount after spread", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("CreateFXQuote", mock.Anything, mock.AnythingOfType("*postgres.FXQuote")).Return(&postgres.FXQuote{ID: "q1", FromCurrency: "USD", ToCurrency: "THB"}, nil)
+        mockRates := new(MockRateProvider)
+        mockRates.On("Rate", mock.Anything, "USD", "THB").Return(&postgres.FXRate{From: "USD", To: "THB", Rate: "36.5", Spread: "0.005"}, nil)
+
+        walletService := wallet.NewService(mockStore, mockRates, slog.Default())
+
+        quote, err := walletService.CreateFXQuote(context.Background(), &wallet.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("50.00")})
+
+        assert.NoError(t, err)
+        assert.Equal(t, "q1", quote.ID)
+
+        created := mockStore.Calls[0].Arguments.Get(1).(*postgres.FXQuote)
+        assert.Len(t, created.ID, 32)
+        assert.Equal(t, money.MustParse("50.00").WithCurrency("USD"), created.Amount)
+        assert.Equal(t, money.MustParse("1815.87").WithCurrency("THB"), created.ConvertedAmount)
+        assert.Equal(t, "36.5", created.Rate)
+        assert.Equal(t, "0.005", created.Spread)
+        assert.True(t, created.ExpiresAt.After(time.Now()))
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given pair without rate should return 422", func(t *testing.T) {
+        mockRates := new(MockRateProvider)
+        mockRates.On("Rate", mock.Anything, "USD", "JPY").Return((*postgres.FXRate)(nil), postgres.ErrRateNotFound)
+
+        walletService := wallet.NewService(new(MockWalletStore), mockRates, slog.Default())
+
+        _, err := walletService.CreateFXQuote(context.Background(), &wallet.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "JPY", Amount: money.MustParse("50.00")})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+    })
+
+    t.Run("given same currency should return 400", func(t *testing.T) {
+        mockRates := new(MockRateProvider)
+        walletService := wallet.NewService(new(MockWalletStore), mockRates, slog.Default())
+
+        _, err := walletService.CreateFXQuote(context.Background(), &wallet.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "USD", Amount: money.MustParse("50.00")})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockRates.AssertNotCalled(t, "Rate")
+    })
+}
+
+func TestUpdateWalletByWalletIdVersionConflict(t *testing.T) {
+    request := &wallet.WalletUpdateRequest{
+        UserID:     123,
+        UserName:   "user1",
+        WalletName: "wallet1",
+        WalletType: "Savings",
+    }
+
+    t.Run("given stale version should return 412 without updating", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("650.00"), Status: postgres.WalletActive, Version: 4}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 3, request, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusPreconditionFailed, httpErr.Code)
+        mockStore.AssertNotCalled(t, "UpdateByWalletId")
+    })
+
+    t.Run("given any version should update the current one", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("650.00"), Status: postgres.WalletActive, Version: 4}, nil)
+        mockStore.On("UpdateByWalletId", mock.Anything, 1, 4, mock.AnythingOfType("postgres.Wallet"), mock.Anything).Return(int64(1), nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, wallet.AnyVersion, request, postgres.AuditMeta{})
+
+        assert.NoError(t, err)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given concurrent update in the store should return 412", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, WalletType: "Savings", Balance: money.MustParse("650.00"), Status: postgres.WalletActive, Version: 3}, nil)
+        mockStore.On("UpdateByWalletId", mock.Anything, 1, 3, mock.AnythingOfType("postgres.Wallet"), mock.Anything).Return(int64(0), postgres.ErrVersionConflict)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.UpdateWalletByWalletId(context.Background(), 1, 3, request, postgres.AuditMeta{})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusPreconditionFailed, httpErr.Code)
+        mockStore.AssertExpectations(t)
+    })
+}
+
+func TestListWallets(t *testing.T) {
+    page := []postgres.Wallet{
+        {ID: 1, WalletName: "a", Balance: money.MustParse("100.00"), CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+        {ID: 2, WalletName: "b", Balance: money.MustParse("200.00"), CreatedAt: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+        {ID: 3, WalletName: "c", Balance: money.MustParse("300.00"), CreatedAt: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+    }
+
+    t.Run("given more rows than the limit should return next cursor that resumes after the last row", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("ListWallets", mock.Anything, postgres.WalletFilter{Sort: "balance", Descending: true, Limit: 3}).Return(page, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        first, err := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "-balance", Limit: 2})
+
+        assert.NoError(t, err)
+        assert.Len(t, first.Data, 2)
+        assert.NotEmpty(t, first.NextCursor)
+
+        after := &postgres.WalletCursor{ID: 2, Balance: money.MustParse("200.00")}
+        mockStore.On("ListWallets", mock.Anything, postgres.WalletFilter{Sort: "balance", Descending: true, Limit: 3, After: after}).Return(page[2:], nil)
+
+        second, err := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "-balance", Limit: 2, Cursor: first.NextCursor})
+
+        assert.NoError(t, err)
+        assert.Len(t, second.Data, 1)
+        assert.Empty(t, second.NextCursor)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given no rows should return empty page", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("ListWallets", mock.Anything, mock.AnythingOfType("postgres.WalletFilter")).Return([]postgres.Wallet(nil), nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        result, err := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "created_at", Limit: 20})
+
+        assert.NoError(t, err)
+        assert.Equal(t, []wallet.Wallet{}, result.Data)
+    })
+
+    t.Run("given cursor from another sort should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("ListWallets", mock.Anything, mock.AnythingOfType("postgres.WalletFilter")).Return(page, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        first, _ := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "created_at", Limit: 1})
+        _, err := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "wallet_name", Limit: 1, Cursor: first.NextCursor})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+    })
+
+    t.Run("given unknown sort should return 400", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.ListWallets(context.Background(), &wallet.WalletQuery{Sort: "user_name", Limit: 20})
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+        mockStore.AssertNotCalled(t, "ListWallets")
+    })
+}
+
+func TestGetWalletById(t *testing.T) {
+    t.Run("given existing wallet should return it", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return(&postgres.Wallet{ID: 1, UserID: 123, WalletType: "Savings", Balance: money.MustParse("100.00"), Currency: "THB", Status: postgres.WalletActive, Version: 2}, nil)
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        w, err := walletService.GetWalletById(context.Background(), 1)
+
+        assert.NoError(t, err)
+        assert.Equal(t, 1, w.ID)
+        assert.Equal(t, 2, w.Version)
+        mockStore.AssertExpectations(t)
+    })
+
+    t.Run("given unknown wallet should return 404", func(t *testing.T) {
+        mockStore := new(MockWalletStore)
+        mockStore.On("FindByWalletId", mock.Anything, 1).Return((*postgres.Wallet)(nil), fmt.Errorf("wallet 1: %w", postgres.ErrNotFound))
+
+        walletService := wallet.WalletService{WalletStore: mockStore}
+
+        _, err := walletService.GetWalletById(context.Background(), 1)
+
+        httpErr, ok := err.(*echo.HTTPError)
+        assert.True(t, ok)
+        assert.Equal(t, http.StatusNotFound, httpErr.Code)
+    })
+}
+
+func TestListsReturnEmptyInsteadOfNotFound(t *testing.T) {
+    mockStore := new(MockWalletStore)
+    mockStore.On("FindAll", mock.Anything).Return([]postgres.Wallet(nil), nil)
+    mockStore.On("FindByWalletType", mock.Anything, "Savings").Return([]postgres.Wallet(nil), nil)
+    mockStore.On("FindByUserId", mock.Anything, 1).Return([]postgres.Wallet(nil), nil)
+
+    walletService := wallet.WalletService{WalletStore: mockStore}
+
+    wallets, err := walletService.GetAllWallets(context.Background())
+    assert.NoError(t, err)
+    assert.Equal(t, []wallet.Wallet{}, wallets)
+
+    wallets, err = walletService.GetWalletsByWalletType(context.Background(), "Savings")
+    assert.NoError(t, err)
+    assert.Equal(t, []wallet.Wallet{}, wallets)
+
+    wallets, err = walletService.GetWalletsByUserId(context.Background(), 1)
+    assert.NoError(t, err)
+    assert.Equal(t, []wallet.Wallet{}, wallets)
+}
@@ -1,16 +1,24 @@
 package wallet
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
 
+// testLogger keeps handler logs out of the test output.
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
 type StubService struct {
 	Wallets        []Wallet
 	Wallet        	Wallet
@@ -20,40 +28,95 @@ type StubService struct {
 }
 
 // GetAllWallets mocks the GetAllWallets method.
-func (s StubService) GetAllWallets() ([]Wallet, error) {
+func (s StubService) GetAllWallets(ctx context.Context) ([]Wallet, error) {
 	return s.Wallets, s.Err
 }
 
+// ListWallets mocks the ListWallets method.
+func (s StubService) ListWallets(ctx context.Context, query *WalletQuery) (*WalletPage, error) {
+	return &WalletPage{Data: s.Wallets}, s.Err
+}
+
+// GetWalletById mocks the GetWalletById method.
+func (s StubService) GetWalletById(ctx context.Context, walletId int) (*Wallet, error) {
+	return &s.Wallet, s.Err
+}
+
 // GetWalletsByWalletType mocks the GetWalletsByWalletType method.
-func (s StubService) GetWalletsByWalletType(walletType string) ([]Wallet, error) {
+func (s StubService) GetWalletsByWalletType(ctx context.Context, walletType string) ([]Wallet, error) {
 	return s.Wallets, s.Err
 }
 
 // GetWalletsByUserId mocks the GetWalletsByUserId method.
-func (s StubService) GetWalletsByUserId(userId int) ([]Wallet, error) {
+func (s StubService) GetWalletsByUserId(ctx context.Context, userId int) ([]Wallet, error) {
 	return s.Wallets, s.Err
 }
 
 // CreateWallet mocks the CreateWallet method.
-func (s StubService) CreateWallet(wallet *WalletRequest) (*Wallet, error) {
+func (s StubService) CreateWallet(ctx context.Context, wallet *WalletRequest, meta postgres.AuditMeta) (*Wallet, error) {
+	return &s.Wallet, s.Err
+}
+
+// DeleteWalletById mocks the DeleteWalletById method.
+func (s StubService) DeleteWalletById(ctx context.Context, walletId int, meta postgres.AuditMeta) (*Wallet, error) {
 	return &s.Wallet, s.Err
 }
 
 // DeleteWalletByUserId mocks the DeleteWalletByUserId method.
-func (s StubService) DeleteWalletByUserId(userId string) (int64, error) {
+func (s StubService) DeleteWalletByUserId(ctx context.Context, userId string, meta postgres.AuditMeta) (int64, error) {
 	return s.DeletedRow, s.Err
 }
 
 // GetCountWalletsByCriteria mocks the GetCountWalletsByCriteria method.
-func (s StubService) GetCountWalletsByCriteria(criteria *Wallet) (int, error) {
+func (s StubService) GetCountWalletsByCriteria(ctx context.Context, criteria *Wallet) (int, error) {
 	return s.DuplicateCount, s.Err
 }
 
 // UpdateWalletByWalletId mocks the UpdateWalletByWalletId method.
-func (s StubService) UpdateWalletByWalletId(walletId int, request *WalletRequest) (*Wallet, error) {
+func (s StubService) UpdateWalletByWalletId(ctx context.Context, walletId int, version int, request *WalletUpdateRequest, meta postgres.AuditMeta) (*Wallet, error) {
+	return &s.Wallet, s.Err
+}
+
+// Transfer mocks the Transfer method.
+func (s StubService) Transfer(ctx context.Context, request *TransferRequest, meta postgres.AuditMeta) (*Transfer, error) {
+	return &Transfer{}, s.Err
+}
+
+// GetTransactionsByWalletId mocks the GetTransactionsByWalletId method.
+func (s StubService) GetTransactionsByWalletId(ctx context.Context, walletId int, limit int, offset int) (*TransactionPage, error) {
+	return &TransactionPage{}, s.Err
+}
+
+// ReconcileWallet mocks the ReconcileWallet method.
+func (s StubService) ReconcileWallet(ctx context.Context, walletId int) (*Reconciliation, error) {
+	return &Reconciliation{}, s.Err
+}
+
+// Deposit mocks the Deposit method.
+func (s StubService) Deposit(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	return &BalanceChange{Wallet: s.Wallet}, s.Err
+}
+
+// Withdraw mocks the Withdraw method.
+func (s StubService) Withdraw(ctx context.Context, walletId int, request *BalanceChangeRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	return &BalanceChange{Wallet: s.Wallet}, s.Err
+}
+
+// Adjust mocks the Adjust method.
+func (s StubService) Adjust(ctx context.Context, walletId int, request *AdjustmentRequest, meta postgres.AuditMeta) (*BalanceChange, error) {
+	return &BalanceChange{Wallet: s.Wallet}, s.Err
+}
+
+// ChangeWalletStatus mocks the ChangeWalletStatus method.
+func (s StubService) ChangeWalletStatus(ctx context.Context, walletId int, status string, meta postgres.AuditMeta) (*Wallet, error) {
 	return &s.Wallet, s.Err
 }
 
+// CreateFXQuote mocks the CreateFXQuote method.
+func (s StubService) CreateFXQuote(ctx context.Context, request *FXQuoteRequest) (*FXQuote, error) {
+	return &FXQuote{}, s.Err
+}
+
 func TestWallet(t *testing.T) {
     t.Run("given unable to get wallets should return 500 and error message", func(t *testing.T) {
         // Setup
@@ -63,6 +126,8 @@ func TestWallet(t *testing.T) {
         rec := httptest.NewRecorder()
 
         c := e.NewContext(req, rec)
+
+        asAdmin(c)
         c.SetPath("/api/v1/wallets")
 
         // Register the CustomErrorMiddleware with the Echo instance
@@ -70,7 +135,7 @@ func TestWallet(t *testing.T) {
 
         // Create the handler with a stub that always returns an internal server error
         stubService := StubService{Err: apperrs.NewInternalServerError("Internal Server Error")}
-        h := NewHandler(&stubService)
+        h := NewHandler(&stubService, testLogger)
 
         // Wrap the handler with the middleware
         handlerWithMiddleware := apperrs.CustomErrorMiddleware(h.WalletHandler)
@@ -91,19 +156,21 @@ func TestWallet(t *testing.T) {
         rec := httptest.NewRecorder()
 
         c := e.NewContext(req, rec)
+
+        asAdmin(c)
         c.SetPath("/api/v1/wallets")
 
         // Define the expected wallets
         expectedWallets := []Wallet{
-            {ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John's Wallet", WalletType: "Credit Card", Balance: 100.00},
-            {ID: 2, UserID: 2, UserName: "Jane Doe", WalletName: "Jane's Wallet", WalletType: "Debit Card", Balance: 150.00},
+            {ID: 1, UserID: 1, UserName: "John Doe", WalletName: "John's Wallet", WalletType: "Credit Card", Balance: money.MustParse("100.00")},
+            {ID: 2, UserID: 2, UserName: "Jane Doe", WalletName: "Jane's Wallet", WalletType: "Debit Card", Balance: money.MustParse("150.00")},
         }
 
         // Create a stub service with the expected wallets
         stubService := StubService{Wallets: expectedWallets}
 
         // Create the handler with the stub service
-        h := NewHandler(&stubService)
+        h := NewHandler(&stubService, testLogger)
 
         // Act: Execute the handler
         err := h.WalletHandler(c)
@@ -113,10 +180,11 @@ func TestWallet(t *testing.T) {
         assert.Equal(t, http.StatusOK, rec.Code, "status code should be 200")
 
         // Unmarshal the response body to check the returned wallets
-        var got []Wallet
-        if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+        var page WalletPage
+        if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
             t.Errorf("unable to unmarshal response JSON: %v", err)
         }
+        got := page.Data
 
         // Compare each field of expected and received wallets
         for i, expected := range expectedWallets {
@@ -125,7 +193,7 @@ func TestWallet(t *testing.T) {
             assert.Equal(t, expected.UserName, got[i].UserName, "UserName should match")
             assert.Equal(t, expected.WalletName, got[i].WalletName, "WalletName should match")
             assert.Equal(t, expected.WalletType, got[i].WalletType, "WalletType should match")
-            assert.InDelta(t, expected.Balance, got[i].Balance, 0.01, "Balance should match")
+            assert.Equal(t, expected.Balance, got[i].Balance, "Balance should match")
         }
     })
 
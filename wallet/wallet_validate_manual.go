@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/metrics"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
 )
 
 // Constants for validation
@@ -14,11 +18,55 @@ const (
 	maxWalletNameLength = 255
 	minBalance          = 500
 	maxBalance          = 10000
+	defaultPageLimit    = 20
+	maxPageLimit        = 100
+	maxReasonLength     = 500
 )
 
 // Valid wallet types
 var validWalletTypes = []string{"Savings", "Credit Card", "Crypto Wallet"}
 
+// balanceRule describes how far a debit may take a wallet's balance. The
+// precision of amounts follows the wallet's currency instead.
+type balanceRule struct {
+	// creditLimits is how far below zero the balance may go in each
+	// currency; a currency without a limit stops at zero.
+	creditLimits map[string]money.Money
+}
+
+// creditCardLimits are the credit lines of Credit Card wallets, each worth
+// about 10,000 THB.
+var creditCardLimits = map[string]money.Money{
+	"THB": money.FromInt(10000),
+	"USD": money.FromInt(300),
+	"EUR": money.FromInt(275),
+	"GBP": money.FromInt(235),
+	"SGD": money.FromInt(400),
+	"JPY": money.FromInt(45000),
+	"KRW": money.FromInt(400000),
+	"BHD": money.FromInt(110),
+	"KWD": money.FromInt(90),
+}
+
+var balanceRules = map[string]balanceRule{
+	"Savings":       {},
+	"Credit Card":   {creditLimits: creditCardLimits},
+	"Crypto Wallet": {},
+}
+
+// balanceRuleFor falls back to the strictest rule for unknown wallet types.
+func balanceRuleFor(walletType string) balanceRule {
+	return balanceRules[walletType]
+}
+
+// minBalance is the lowest balance a debit may leave in currency.
+func (r balanceRule) minBalance(currency string) money.Money {
+	if limit, ok := r.creditLimits[currency]; ok {
+		return limit.Neg()
+	}
+	return money.FromInt(0)
+}
+
 
 // ValidateWalletRequest validates a wallet request
 func ValidateWalletRequestCreate(wallet *WalletRequest) error {
@@ -28,8 +76,11 @@ func ValidateWalletRequestCreate(wallet *WalletRequest) error {
 	validateUserName(wallet.UserName, &errMsgs)
 	validateWalletName(wallet.WalletName, &errMsgs)
 	validateWalletType(wallet.WalletType, &errMsgs)
+	validateCurrency(wallet.Currency, &errMsgs)
+	validateCurrencyForWalletType(wallet.WalletType, wallet.Currency, &errMsgs)
 	validateBalanceGreaterThanZero(wallet.Balance, &errMsgs)
 	validateBalanceRangeMinMax(wallet.Balance, &errMsgs)
+	validateAmountPrecision("Balance", wallet.Currency, wallet.Balance, &errMsgs)
 	
 
 	if len(errMsgs) > 0 {
@@ -40,14 +91,13 @@ func ValidateWalletRequestCreate(wallet *WalletRequest) error {
 }
 
 
-func ValidateWalletRequestUpdate(wallet *WalletRequest) error {
+func ValidateWalletRequestUpdate(wallet *WalletUpdateRequest) error {
 	var errMsgs []string
 
 	validateUserID(wallet.UserID, &errMsgs)
 	validateUserName(wallet.UserName, &errMsgs)
 	validateWalletName(wallet.WalletName, &errMsgs)
 	validateWalletType(wallet.WalletType, &errMsgs)
-	validateBalanceGreaterEqualZero(wallet.Balance, &errMsgs)
 
 	if len(errMsgs) > 0 {
 		return errors.New(strings.Join(errMsgs, "; "))
@@ -57,54 +107,229 @@ func ValidateWalletRequestUpdate(wallet *WalletRequest) error {
 }
 
 
+// ValidateTransferRequest validates a transfer between two wallets
+func ValidateTransferRequest(transfer *TransferRequest) error {
+	var errMsgs []string
+
+	validateWalletID("FromWalletID", transfer.FromWalletID, &errMsgs)
+	validateWalletID("ToWalletID", transfer.ToWalletID, &errMsgs)
+	validateDifferentWallets(transfer.FromWalletID, transfer.ToWalletID, &errMsgs)
+	validateAmountGreaterThanZero(transfer.Amount, &errMsgs)
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateAmountPrecision validates that an amount fits the minor unit of a currency
+func ValidateAmountPrecision(currency string, amount money.Money) error {
+	var errMsgs []string
+
+	validateAmountPrecision("Amount", currency, amount, &errMsgs)
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateBalanceChangeRequest validates a deposit or withdrawal for a wallet currency
+func ValidateBalanceChangeRequest(currency string, request *BalanceChangeRequest) error {
+	var errMsgs []string
+
+	validateAmountGreaterThanZero(request.Amount, &errMsgs)
+	validateAmountPrecision("Amount", currency, request.Amount, &errMsgs)
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateAdjustmentRequest validates a signed, non-zero adjustment and
+// requires a reason for it
+func ValidateAdjustmentRequest(currency string, request *AdjustmentRequest) error {
+	var errMsgs []string
+
+	if request.Amount.IsZero() {
+		fail("amount_non_zero", "Amount must not be zero", &errMsgs)
+	}
+	validateAmountPrecision("Amount", currency, request.Amount, &errMsgs)
+	if strings.TrimSpace(request.Reason) == "" {
+		fail("reason_required", "Reason is required", &errMsgs)
+	} else if len(request.Reason) > maxReasonLength {
+		fail("reason_length", fmt.Sprintf("Reason must be at most %d characters", maxReasonLength), &errMsgs)
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateFXQuoteRequest validates the currency pair and amount of a quote
+func ValidateFXQuoteRequest(request *FXQuoteRequest) error {
+	var errMsgs []string
+
+	validateCurrency(request.FromCurrency, &errMsgs)
+	validateCurrency(request.ToCurrency, &errMsgs)
+	if request.FromCurrency == request.ToCurrency {
+		fail("different_currencies", "FromCurrency and ToCurrency must be different", &errMsgs)
+	}
+	validateAmountGreaterThanZero(request.Amount, &errMsgs)
+	validateAmountPrecision("Amount", request.FromCurrency, request.Amount, &errMsgs)
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateWalletQuery validates the sort, page size and ranges of a wallet listing
+func ValidateWalletQuery(query *WalletQuery) error {
+	var errMsgs []string
+
+	if !postgres.IsWalletSort(sortKey(query.Sort)) {
+		fail("sort", "Sort must be one of created_at, balance or wallet_name, optionally prefixed with -", &errMsgs)
+	}
+	if query.Limit <= 0 || query.Limit > maxPageLimit {
+		fail("limit", fmt.Sprintf("Limit must be between 1 and %d", maxPageLimit), &errMsgs)
+	}
+	if query.Status != "" && !postgres.IsWalletStatus(query.Status) {
+		fail("status", "Status must be one of active, frozen or closed", &errMsgs)
+	}
+	if query.MinBalance != nil && query.MaxBalance != nil && query.MinBalance.Cmp(*query.MaxBalance) > 0 {
+		fail("balance_filter_range", "MinBalance must not be greater than MaxBalance", &errMsgs)
+	}
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedFrom.Before(*query.CreatedTo) {
+		fail("created_range", "CreatedFrom must be before CreatedTo", &errMsgs)
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidatePagination validates limit and offset of a paged listing
+func ValidatePagination(limit int, offset int) error {
+	var errMsgs []string
+
+	if limit <= 0 || limit > maxPageLimit {
+		fail("limit", fmt.Sprintf("Limit must be between 1 and %d", maxPageLimit), &errMsgs)
+	}
+	if offset < 0 {
+		fail("offset", "Offset must be equal or greater than 0", &errMsgs)
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
 
 // Helper functions for individual validations
 
+// fail adds the message of a broken rule and counts the rule in the
+// validation failure metric
+func fail(rule string, message string, errMsgs *[]string) {
+	metrics.ValidationFailures.WithLabelValues(rule).Inc()
+	*errMsgs = append(*errMsgs, message)
+}
+
 func validateUserID(userID int, errMsgs *[]string) {
 	if userID <= 0 {
-		*errMsgs = append(*errMsgs, "UserID must be greater than 0")
+		fail("user_id", "UserID must be greater than 0", errMsgs)
 	}
 }
 
 func validateUserName(userName string, errMsgs *[]string) {
 	if len(userName) < minUserNameLength || len(userName) > maxUserNameLength {
-		*errMsgs = append(*errMsgs, fmt.Sprintf("UserName must be between %d and %d characters", minUserNameLength, maxUserNameLength))
+		fail("user_name_length", fmt.Sprintf("UserName must be between %d and %d characters", minUserNameLength, maxUserNameLength), errMsgs)
 	}
 }
 
 func validateWalletName(walletName string, errMsgs *[]string) {
 	if len(walletName) < minWalletNameLength || len(walletName) > maxWalletNameLength {
-		*errMsgs = append(*errMsgs, fmt.Sprintf("WalletName must be between %d and %d characters", minWalletNameLength, maxWalletNameLength))
+		fail("wallet_name_length", fmt.Sprintf("WalletName must be between %d and %d characters", minWalletNameLength, maxWalletNameLength), errMsgs)
 	}
 }
 
 func validateWalletType(walletType string, errMsgs *[]string) {
 	if !contains(validWalletTypes, walletType) {
-		*errMsgs = append(*errMsgs, fmt.Sprintf("WalletType must be one of: %s", strings.Join(validWalletTypes, ", ")))
+		fail("wallet_type", fmt.Sprintf("WalletType must be one of: %s", strings.Join(validWalletTypes, ", ")), errMsgs)
 	}
 }
 
-func validateBalanceRangeMinMax(balance float64, errMsgs *[]string) {
-	if  balance < minBalance || balance > maxBalance {
-		*errMsgs = append(*errMsgs, fmt.Sprintf("Balance between %d and %d", minBalance, maxBalance))
+func validateBalanceRangeMinMax(balance money.Money, errMsgs *[]string) {
+	if  balance.Cmp(money.FromInt(minBalance)) < 0 || balance.Cmp(money.FromInt(maxBalance)) > 0 {
+		fail("balance_range", fmt.Sprintf("Balance between %d and %d", minBalance, maxBalance), errMsgs)
 	}
 }
 
 
-func validateBalanceGreaterThanZero(balance float64, errMsgs *[]string) {
-	if balance <= 0  {
-		*errMsgs = append(*errMsgs, "Balance must be greater than 0 ")
+func validateBalanceGreaterThanZero(balance money.Money, errMsgs *[]string) {
+	if balance.Sign() <= 0  {
+		fail("balance_positive", "Balance must be greater than 0 ", errMsgs)
 	}
 }
 
 
-func validateBalanceGreaterEqualZero(balance float64, errMsgs *[]string) {
-	if balance < 0  {
-		*errMsgs = append(*errMsgs, fmt.Sprintf("Balance must be equal or greater than 0 "))
+func validateWalletID(field string, walletID int, errMsgs *[]string) {
+	if walletID <= 0 {
+		fail("wallet_id", fmt.Sprintf("%s must be greater than 0", field), errMsgs)
 	}
 }
 
+func validateDifferentWallets(fromWalletID int, toWalletID int, errMsgs *[]string) {
+	if fromWalletID == toWalletID {
+		fail("different_wallets", "FromWalletID and ToWalletID must be different", errMsgs)
+	}
+}
 
+func validateAmountGreaterThanZero(amount money.Money, errMsgs *[]string) {
+	if amount.Sign() <= 0 {
+		fail("amount_positive", "Amount must be greater than 0", errMsgs)
+	}
+}
+
+func validateCurrency(currency string, errMsgs *[]string) {
+	if _, ok := money.LookupCurrency(currency); !ok {
+		fail("currency", "Currency must be a supported ISO 4217 or crypto currency code", errMsgs)
+	}
+}
+
+func validateCurrencyForWalletType(walletType string, currency string, errMsgs *[]string) {
+	c, ok := money.LookupCurrency(currency)
+	if !ok || !contains(validWalletTypes, walletType) {
+		return
+	}
+	if isCrypto := walletType == "Crypto Wallet"; isCrypto != c.Crypto {
+		fail("currency_for_wallet_type", fmt.Sprintf("Currency %s cannot be used for a %s", currency, walletType), errMsgs)
+	}
+}
+
+// validateAmountPrecision checks the amount against the currency's minor
+// unit, e.g. no decimals for JPY and three for BHD. Unknown currencies are
+// held to two places.
+func validateAmountPrecision(field string, currency string, amount money.Money, errMsgs *[]string) {
+	places := 2
+	if c, ok := money.LookupCurrency(currency); ok {
+		places = c.Exponent
+	}
+	if amount.DecimalPlaces() > places {
+		fail("amount_precision", fmt.Sprintf("%s must have at most %d decimal places for %s", field, places, currency), errMsgs)
+	}
+}
 
 func contains(arr []string, str string) bool {
 	for _, a := range arr {
@@ -3,6 +3,11 @@ package wallet
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/metrics"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/money"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestValidateWalletRequest(t *testing.T) {
@@ -18,7 +23,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: false,
         },
@@ -29,7 +35,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -40,7 +47,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JD",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -51,7 +59,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   strings.Repeat("a", maxUserNameLength+1),
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -62,7 +71,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "W1",
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -73,7 +83,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: strings.Repeat("a", maxUserNameLength+1),
                 WalletType: "Savings",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -84,7 +95,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "InvalidType",
-                Balance:    1000,
+                Balance:    money.MustParse("1000"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -95,7 +107,8 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    -100,
+                Balance:    money.MustParse("-100"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -106,7 +119,32 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    200,
+                Balance:    money.MustParse("200"),
+                Currency:   "THB",
+            },
+            wantError: true,
+        },
+        {
+            name: "Valid Balance (exactly minimum)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Savings",
+                WalletType: "Savings",
+                Balance:    money.MustParse("500.00"),
+                Currency:   "THB",
+            },
+            wantError: false,
+        },
+        {
+            name: "Invalid Balance (just below minimum)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Savings",
+                WalletType: "Savings",
+                Balance:    money.MustParse("499.99"),
+                Currency:   "THB",
             },
             wantError: true,
         },
@@ -117,7 +155,56 @@ func TestValidateWalletRequest(t *testing.T) {
                 UserName:   "JohnDoe",
                 WalletName: "Savings",
                 WalletType: "Savings",
-                Balance:    11000,
+                Balance:    money.MustParse("11000"),
+                Currency:   "THB",
+            },
+            wantError: true,
+        },
+        {
+            name: "Invalid Currency (unsupported)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Savings",
+                WalletType: "Savings",
+                Balance:    money.MustParse("1000"),
+                Currency:   "XYZ",
+            },
+            wantError: true,
+        },
+        {
+            name: "Invalid Currency (crypto for savings)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Savings",
+                WalletType: "Savings",
+                Balance:    money.MustParse("1000"),
+                Currency:   "BTC",
+            },
+            wantError: true,
+        },
+        {
+            name: "Invalid Currency (fiat for crypto wallet)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Coins",
+                WalletType: "Crypto Wallet",
+                Balance:    money.MustParse("1000"),
+                Currency:   "USD",
+            },
+            wantError: true,
+        },
+        {
+            name: "Invalid Balance (decimals for JPY)",
+            wallet: &WalletRequest{
+                UserID:     1,
+                UserName:   "JohnDoe",
+                WalletName: "Savings",
+                WalletType: "Savings",
+                Balance:    money.MustParse("1000.5"),
+                Currency:   "JPY",
             },
             wantError: true,
         },
@@ -136,3 +223,166 @@ func validateWalletRequestTest(t *testing.T, wallet *WalletRequest, wantError bo
         t.Errorf("ValidateWalletRequest(%v) returned error: %v, wantError: %t", wallet, err, wantError)
     }
 }
+
+func TestValidateTransferRequest(t *testing.T) {
+    testCases := []struct {
+        name      string
+        transfer  *TransferRequest
+        wantError bool
+    }{
+        {
+            name:      "Valid transfer request",
+            transfer:  &TransferRequest{FromWalletID: 1, ToWalletID: 2, Amount: money.MustParse("50")},
+            wantError: false,
+        },
+        {
+            name:      "Invalid FromWalletID (zero)",
+            transfer:  &TransferRequest{FromWalletID: 0, ToWalletID: 2, Amount: money.MustParse("50")},
+            wantError: true,
+        },
+        {
+            name:      "Invalid ToWalletID (negative)",
+            transfer:  &TransferRequest{FromWalletID: 1, ToWalletID: -2, Amount: money.MustParse("50")},
+            wantError: true,
+        },
+        {
+            name:      "Invalid same wallet",
+            transfer:  &TransferRequest{FromWalletID: 1, ToWalletID: 1, Amount: money.MustParse("50")},
+            wantError: true,
+        },
+        {
+            name:      "Invalid Amount (zero)",
+            transfer:  &TransferRequest{FromWalletID: 1, ToWalletID: 2, Amount: money.MustParse("0")},
+            wantError: true,
+        },
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateTransferRequest(tc.transfer)
+            if (err != nil) != tc.wantError {
+                t.Errorf("ValidateTransferRequest(%v) returned error: %v, wantError: %t", tc.transfer, err, tc.wantError)
+            }
+        })
+    }
+}
+
+func TestValidateBalanceChangeRequest(t *testing.T) {
+    testCases := []struct {
+        name      string
+        currency  string
+        amount    string
+        wantError bool
+    }{
+        {name: "Valid THB amount", currency: "THB", amount: "100.25", wantError: false},
+        {name: "Invalid THB amount (three decimals)", currency: "THB", amount: "100.255", wantError: true},
+        {name: "Valid JPY amount", currency: "JPY", amount: "100", wantError: false},
+        {name: "Invalid JPY amount (decimals)", currency: "JPY", amount: "100.5", wantError: true},
+        {name: "Valid BHD amount (three decimals)", currency: "BHD", amount: "1.255", wantError: false},
+        {name: "Valid BTC amount (eight decimals)", currency: "BTC", amount: "0.12345678", wantError: false},
+        {name: "Invalid amount (zero)", currency: "THB", amount: "0", wantError: true},
+        {name: "Invalid amount (negative)", currency: "THB", amount: "-1", wantError: true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateBalanceChangeRequest(tc.currency, &BalanceChangeRequest{Amount: money.MustParse(tc.amount)})
+            if (err != nil) != tc.wantError {
+                t.Errorf("ValidateBalanceChangeRequest(%s, %s) returned error: %v, wantError: %t", tc.currency, tc.amount, err, tc.wantError)
+            }
+        })
+    }
+}
+
+func TestValidateAdjustmentRequest(t *testing.T) {
+    testCases := []struct {
+        name      string
+        request   *AdjustmentRequest
+        wantError bool
+    }{
+        {name: "Valid credit", request: &AdjustmentRequest{Amount: money.MustParse("12.50"), Reason: "refund"}, wantError: false},
+        {name: "Valid debit", request: &AdjustmentRequest{Amount: money.MustParse("-12.50"), Reason: "duplicate deposit"}, wantError: false},
+        {name: "Invalid Amount (zero)", request: &AdjustmentRequest{Amount: money.MustParse("0"), Reason: "refund"}, wantError: true},
+        {name: "Invalid Amount (three decimals)", request: &AdjustmentRequest{Amount: money.MustParse("1.255"), Reason: "refund"}, wantError: true},
+        {name: "Invalid Reason (blank)", request: &AdjustmentRequest{Amount: money.MustParse("1"), Reason: "  "}, wantError: true},
+        {name: "Invalid Reason (too long)", request: &AdjustmentRequest{Amount: money.MustParse("1"), Reason: strings.Repeat("a", 501)}, wantError: true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateAdjustmentRequest("THB", tc.request)
+            if (err != nil) != tc.wantError {
+                t.Errorf("ValidateAdjustmentRequest(%+v) returned error: %v, wantError: %t", tc.request, err, tc.wantError)
+            }
+        })
+    }
+}
+
+func TestValidateFXQuoteRequest(t *testing.T) {
+    testCases := []struct {
+        name      string
+        request   *FXQuoteRequest
+        wantError bool
+    }{
+        {name: "Valid quote request", request: &FXQuoteRequest{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("50.00")}, wantError: false},
+        {name: "Invalid same currency", request: &FXQuoteRequest{FromCurrency: "USD", ToCurrency: "USD", Amount: money.MustParse("50.00")}, wantError: true},
+        {name: "Invalid unsupported currency", request: &FXQuoteRequest{FromCurrency: "USD", ToCurrency: "XYZ", Amount: money.MustParse("50.00")}, wantError: true},
+        {name: "Invalid Amount (zero)", request: &FXQuoteRequest{FromCurrency: "USD", ToCurrency: "THB", Amount: money.MustParse("0")}, wantError: true},
+        {name: "Invalid Amount (decimals for JPY)", request: &FXQuoteRequest{FromCurrency: "JPY", ToCurrency: "THB", Amount: money.MustParse("10.5")}, wantError: true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateFXQuoteRequest(tc.request)
+            if (err != nil) != tc.wantError {
+                t.Errorf("ValidateFXQuoteRequest(%v) returned error: %v, wantError: %t", tc.request, err, tc.wantError)
+            }
+        })
+    }
+}
+
+func TestValidateWalletQuery(t *testing.T) {
+    low, high := money.MustParse("10"), money.MustParse("100")
+    from, to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+    testCases := []struct {
+        name      string
+        query     *WalletQuery
+        wantError bool
+    }{
+        {name: "Valid query", query: &WalletQuery{Sort: "created_at", Limit: 20}, wantError: false},
+        {name: "Valid descending sort with ranges", query: &WalletQuery{Sort: "-wallet_name", Limit: 100, MinBalance: &low, MaxBalance: &high, CreatedFrom: &from, CreatedTo: &to}, wantError: false},
+        {name: "Valid status", query: &WalletQuery{Sort: "created_at", Limit: 20, Status: "closed"}, wantError: false},
+        {name: "Invalid sort", query: &WalletQuery{Sort: "id", Limit: 20}, wantError: true},
+        {name: "Invalid status", query: &WalletQuery{Sort: "created_at", Limit: 20, Status: "deleted"}, wantError: true},
+        {name: "Invalid limit", query: &WalletQuery{Sort: "balance", Limit: 101}, wantError: true},
+        {name: "Invalid balance range", query: &WalletQuery{Sort: "balance", Limit: 20, MinBalance: &high, MaxBalance: &low}, wantError: true},
+        {name: "Invalid created range", query: &WalletQuery{Sort: "created_at", Limit: 20, CreatedFrom: &to, CreatedTo: &from}, wantError: true},
+    }
+
+    for _, tc := range testCases {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateWalletQuery(tc.query)
+            if (err != nil) != tc.wantError {
+                t.Errorf("ValidateWalletQuery(%v) returned error: %v, wantError: %t", tc.query, err, tc.wantError)
+            }
+        })
+    }
+}
+
+func TestValidationFailuresAreCountedByRule(t *testing.T) {
+    sameWallet := testutil.ToFloat64(metrics.ValidationFailures.WithLabelValues("different_wallets"))
+    amount := testutil.ToFloat64(metrics.ValidationFailures.WithLabelValues("amount_positive"))
+
+    err := ValidateTransferRequest(&TransferRequest{FromWalletID: 1, ToWalletID: 1, Amount: money.MustParse("0")})
+
+    if err == nil {
+        t.Fatal("ValidateTransferRequest returned no error")
+    }
+    if got := testutil.ToFloat64(metrics.ValidationFailures.WithLabelValues("different_wallets")); got != sameWallet+1 {
+        t.Errorf("different_wallets failures = %v, want %v", got, sameWallet+1)
+    }
+    if got := testutil.ToFloat64(metrics.ValidationFailures.WithLabelValues("amount_positive")); got != amount+1 {
+        t.Errorf("amount_positive failures = %v, want %v", got, amount+1)
+    }
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+// Dispatcher sends pending deliveries to their webhooks. A failed delivery is
+// retried with exponential backoff, BaseDelay doubling up to MaxDelay, and
+// marked dead after MaxAttempts. Several dispatchers can share a database:
+// each delivery is claimed by one of them at a time.
+type Dispatcher struct {
+	Store       postgres.WebhookStorer
+	Client      *http.Client
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	now func() time.Time
+}
+
+func NewDispatcher(store postgres.WebhookStorer) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Interval:    time.Second,
+		BatchSize:   50,
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+		now:         time.Now,
+	}
+}
+
+// envelope is the body of a delivery.
+type envelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Run dispatches until ctx is done, checking for due deliveries every
+// Interval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while full batches come back, there is a backlog
+		for {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Claim webhook deliveries failed", "err", err)
+			}
+			if err != nil || n < d.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce claims one batch of due deliveries and attempts each of
+// them, returning how many it claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// The lease must outlast the slowest possible batch, or a delivery
+	// could be claimed again while it is still being sent
+	lease := time.Duration(d.BatchSize)*d.Client.Timeout + time.Minute
+
+	deliveries, err := d.Store.ClaimDeliveries(ctx, d.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		sendErr := d.send(ctx, delivery)
+
+		if sendErr == nil {
+			err = d.Store.CompleteDelivery(ctx, delivery.ID)
+		} else {
+			err = d.Store.FailDelivery(ctx, delivery.ID, sendErr.Error(), d.retryAt(delivery.Attempts+1))
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Record webhook delivery failed", "delivery_id", delivery.ID, "err", err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *postgres.WebhookDelivery) error {
+	body, err := json.Marshal(envelope{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.EventType,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// retryAt returns when to try again after the given number of failed
+// attempts, or nil when the delivery should be given up.
+func (d *Dispatcher) retryAt(attempts int) *time.Time {
+	if attempts >= d.MaxAttempts {
+		return nil
+	}
+	delay := d.BaseDelay
+	for i := 1; i < attempts && delay < d.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxDelay {
+		delay = d.MaxDelay
+	}
+	t := d.now().Add(delay)
+	return &t
+}
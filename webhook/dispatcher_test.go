@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingStore hands out Deliveries once and records what happened to them.
+type recordingStore struct {
+	postgres.WebhookStorer
+
+	Deliveries []postgres.WebhookDelivery
+	Completed  []int64
+	Failed     map[int64]*time.Time
+	Errors     map[int64]string
+}
+
+func (s *recordingStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]postgres.WebhookDelivery, error) {
+	d := s.Deliveries
+	s.Deliveries = nil
+	return d, nil
+}
+
+func (s *recordingStore) CompleteDelivery(ctx context.Context, id int64) error {
+	s.Completed = append(s.Completed, id)
+	return nil
+}
+
+func (s *recordingStore) FailDelivery(ctx context.Context, id int64, lastError string, retryAt *time.Time) error {
+	if s.Failed == nil {
+		s.Failed, s.Errors = map[int64]*time.Time{}, map[int64]string{}
+	}
+	s.Failed[id] = retryAt
+	s.Errors[id] = lastError
+	return nil
+}
+
+func TestDispatchOnce(t *testing.T) {
+	now := time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	newDispatcher := func(store postgres.WebhookStorer) *Dispatcher {
+		d := NewDispatcher(store)
+		d.now = func() time.Time { return now }
+		return d
+	}
+
+	delivery := func(id int64, url string, attempts int) postgres.WebhookDelivery {
+		return postgres.WebhookDelivery{
+			ID: id, EventID: 7, Attempts: attempts, URL: url, Secret: "whsec_test",
+			Event: postgres.OutboxEvent{ID: 7, EventType: postgres.EventWalletCreated, Payload: json.RawMessage(`{"id":1}`), CreatedAt: now},
+		}
+	}
+
+	t.Run("given receiver accepts should send signed event and complete delivery", func(t *testing.T) {
+		var got *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		store := &recordingStore{Deliveries: []postgres.WebhookDelivery{delivery(1, server.URL, 0)}}
+
+		n, err := newDispatcher(store).DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []int64{1}, store.Completed)
+		assert.Equal(t, "wallet.created", got.Header.Get(HeaderEvent))
+		assert.Equal(t, "1", got.Header.Get(HeaderDelivery))
+		assert.Equal(t, strconv.FormatInt(now.Unix(), 10), got.Header.Get(HeaderTimestamp))
+		assert.Equal(t, Sign("whsec_test", now.Unix(), body), got.Header.Get(HeaderSignature))
+		assert.JSONEq(t, `{"id":7,"type":"wallet.created","created_at":"2024-03-25T12:00:00Z","data":{"id":1}}`, string(body))
+	})
+
+	t.Run("given receiver fails should retry with exponential backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		store := &recordingStore{Deliveries: []postgres.WebhookDelivery{delivery(1, server.URL, 0), delivery(2, server.URL, 3)}}
+
+		_, err := newDispatcher(store).DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Empty(t, store.Completed)
+		assert.Equal(t, now.Add(30*time.Second), *store.Failed[1])
+		assert.Equal(t, now.Add(8*30*time.Second), *store.Failed[2])
+		assert.Equal(t, "unexpected status 503", store.Errors[1])
+	})
+
+	t.Run("given last attempt fails should mark delivery dead", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := &recordingStore{Deliveries: []postgres.WebhookDelivery{delivery(1, server.URL, 7)}}
+
+		_, err := newDispatcher(store).DispatchOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Contains(t, store.Failed, int64(1))
+		assert.Nil(t, store.Failed[1])
+	})
+}
+
+func TestRetryAt(t *testing.T) {
+	now := time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)
+	d := NewDispatcher(nil)
+	d.now = func() time.Time { return now }
+	d.MaxAttempts = 20
+
+	assert.Equal(t, now.Add(30*time.Second), *d.retryAt(1))
+	assert.Equal(t, now.Add(time.Minute), *d.retryAt(2))
+	assert.Equal(t, now.Add(time.Hour), *d.retryAt(12))
+	assert.Nil(t, d.retryAt(20))
+}
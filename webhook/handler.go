@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// CreateWebhook
+// @Summary Create webhook
+// @Description Subscribe a URL to wallet events; the signing secret is only shown in this response
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Router /api/v1/webhooks [post]
+// @Security BearerAuth
+// @Param WebhookRequest body WebhookRequest true "WebhookRequest"
+// @Success 201 {object} CreatedWebhook
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) CreateWebhookHandler(c echo.Context) error {
+
+	req := new(WebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request().Context(), req)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks
+// @Summary List webhooks
+// @Description List active webhook subscriptions without their secrets
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Router /api/v1/webhooks [get]
+// @Security BearerAuth
+// @Success 200 {array} Webhook
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+func (h *Handler) ListWebhooksHandler(c echo.Context) error {
+
+	webhooks, err := h.service.ListWebhooks(c.Request().Context())
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook
+// @Summary Delete webhook
+// @Description Stop sending events to a webhook; its deliveries stay listable
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Router /api/v1/webhooks/{id} [delete]
+// @Security BearerAuth
+// @Param	id	path	string	true	"webhook id"
+// @Success 204
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) DeleteWebhookHandler(c echo.Context) error {
+
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid webhook ID")
+	}
+
+	if err := h.service.DeleteWebhook(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeliveries
+// @Summary List webhook deliveries
+// @Description List the latest deliveries of a webhook, newest first; status=dead shows the ones that ran out of attempts
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+// @Security BearerAuth
+// @Param	id	path	string	true	"webhook id"
+// @Param	status	query	string	false	"pending, delivered or dead"
+// @Param	limit	query	int	false	"page size (default 20, max 100)"
+// @Success 200 {array} Delivery
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) ListDeliveriesHandler(c echo.Context) error {
+
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid webhook ID")
+	}
+
+	limit := defaultPageLimit
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return apperrs.NewBadRequestError("invalid limit")
+		}
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request().Context(), id, c.QueryParam("status"), limit)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// RetryDelivery
+// @Summary Retry webhook delivery
+// @Description Queue a delivery again with a fresh set of attempts
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/retry [post]
+// @Security BearerAuth
+// @Param	id	path	string	true	"webhook id"
+// @Param	deliveryId	path	string	true	"delivery id"
+// @Success 200 {object} Delivery
+// @Failure 500 {object} apperrs.CustomError
+// @Failure 404 {object} apperrs.CustomError
+// @Failure 403 {object} apperrs.CustomError
+// @Failure 401 {object} apperrs.CustomError
+// @Failure 400 {object} apperrs.CustomError
+func (h *Handler) RetryDeliveryHandler(c echo.Context) error {
+
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid webhook ID")
+	}
+
+	deliveryId, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+
+	if err != nil {
+		return apperrs.NewBadRequestError("invalid delivery ID")
+	}
+
+	delivery, err := h.service.RetryDelivery(c.Request().Context(), id, deliveryId)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhookHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	reqBody := WebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"wallet.created"}}
+	created := CreatedWebhook{Webhook: Webhook{ID: 1, URL: reqBody.URL, EventTypes: reqBody.EventTypes}, Secret: "whsec_secret"}
+
+	mockService.On("CreateWebhook", mock.Anything, &reqBody).Return(&created, nil)
+
+	e := echo.New()
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(reqBodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.CreateWebhookHandler(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"secret":"whsec_secret"`)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("DeleteWebhook", mock.Anything, 3).Return(nil)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/3", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	if assert.NoError(t, handler.DeleteWebhookHandler(c)) {
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListDeliveriesHandler(t *testing.T) {
+	t.Run("given status and limit should pass them to the service", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		mockService.On("ListDeliveries", mock.Anything, 3, "dead", 5).Return([]Delivery{{ID: 2, Status: "dead"}}, nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/3/deliveries?status=dead&limit=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+
+		if assert.NoError(t, handler.ListDeliveriesHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("given invalid webhook id should return 400", func(t *testing.T) {
+		mockService := new(MockService)
+		handler := NewHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/abc/deliveries", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		err := handler.ListDeliveriesHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		mockService.AssertNotCalled(t, "ListDeliveries")
+	})
+}
+
+func TestRetryDeliveryHandler(t *testing.T) {
+	mockService := new(MockService)
+	handler := NewHandler(mockService)
+
+	mockService.On("RetryDelivery", mock.Anything, 3, int64(42)).Return(&Delivery{ID: 42, Status: "pending"}, nil)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/3/deliveries/42/retry", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "deliveryId")
+	c.SetParamValues("3", "42")
+
+	if assert.NoError(t, handler.RetryDeliveryHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	}
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/apperrs"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+type WebhookService struct {
+	WebhookStore postgres.WebhookStorer
+}
+
+func NewService(db postgres.WebhookStorer) WebhookService {
+	return WebhookService{WebhookStore: db}
+}
+
+// CreateWebhook subscribes a URL. The signing secret is returned here and
+// nowhere else.
+func (s WebhookService) CreateWebhook(ctx context.Context, request *WebhookRequest) (*CreatedWebhook, error) {
+
+	err := ValidateWebhookRequest(request)
+
+	if err != nil {
+		slog.InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	secret, err := GenerateSecret()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Create webhook failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create webhook failed")
+	}
+
+	w, err := s.WebhookStore.CreateWebhook(ctx, &postgres.Webhook{
+		URL:        request.URL,
+		Secret:     secret,
+		EventTypes: request.EventTypes,
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Create webhook failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Create webhook failed")
+	}
+
+	return &CreatedWebhook{Webhook: toWebhookResponse(w), Secret: secret}, nil
+}
+
+func (s WebhookService) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+
+	webhooks, err := s.WebhookStore.ListWebhooks(ctx)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "List webhooks failed", "err", err)
+		return nil, apperrs.NewInternalServerError("List webhooks failed")
+	}
+
+	responses := []Webhook{}
+	for i := range webhooks {
+		responses = append(responses, toWebhookResponse(&webhooks[i]))
+	}
+
+	return responses, nil
+}
+
+func (s WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+
+	err := s.WebhookStore.DeleteWebhook(ctx, id)
+
+	if errors.Is(err, postgres.ErrNotFound) {
+		return apperrs.NewNotFoundError(err.Error())
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Delete webhook failed", "err", err)
+		return apperrs.NewInternalServerError("Delete webhook failed")
+	}
+
+	return nil
+}
+
+func (s WebhookService) ListDeliveries(ctx context.Context, webhookId int, status string, limit int) ([]Delivery, error) {
+
+	err := ValidateDeliveryQuery(status, limit)
+
+	if err != nil {
+		slog.InfoContext(ctx, "invalid request", "err", err)
+		return nil, apperrs.NewBadRequestError(err.Error())
+	}
+
+	deliveries, err := s.WebhookStore.ListDeliveries(ctx, webhookId, status, limit)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "List webhook deliveries failed", "err", err)
+		return nil, apperrs.NewInternalServerError("List webhook deliveries failed")
+	}
+
+	responses := []Delivery{}
+	for i := range deliveries {
+		responses = append(responses, toDeliveryResponse(&deliveries[i]))
+	}
+
+	return responses, nil
+}
+
+// RetryDelivery queues a delivery again, typically a dead one once the
+// receiver is fixed.
+func (s WebhookService) RetryDelivery(ctx context.Context, webhookId int, deliveryId int64) (*Delivery, error) {
+
+	d, err := s.WebhookStore.RetryDelivery(ctx, webhookId, deliveryId)
+
+	if errors.Is(err, postgres.ErrNotFound) {
+		return nil, apperrs.NewNotFoundError(err.Error())
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Retry webhook delivery failed", "err", err)
+		return nil, apperrs.NewInternalServerError("Retry webhook delivery failed")
+	}
+
+	response := toDeliveryResponse(d)
+	return &response, nil
+}
+
+func toWebhookResponse(w *postgres.Webhook) Webhook {
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return Webhook{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+func toDeliveryResponse(d *postgres.WebhookDelivery) Delivery {
+	return Delivery{
+		ID:            d.ID,
+		EventID:       d.EventID,
+		EventType:     d.Event.EventType,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+		DeliveredAt:   d.DeliveredAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockService is a mock implementation of the Service interface
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) CreateWebhook(ctx context.Context, request *WebhookRequest) (*CreatedWebhook, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*CreatedWebhook), args.Error(1)
+}
+
+func (m *MockService) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]Webhook), args.Error(1)
+}
+
+func (m *MockService) DeleteWebhook(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockService) ListDeliveries(ctx context.Context, webhookId int, status string, limit int) ([]Delivery, error) {
+	args := m.Called(ctx, webhookId, status, limit)
+	return args.Get(0).([]Delivery), args.Error(1)
+}
+
+func (m *MockService) RetryDelivery(ctx context.Context, webhookId int, deliveryId int64) (*Delivery, error) {
+	args := m.Called(ctx, webhookId, deliveryId)
+	return args.Get(0).(*Delivery), args.Error(1)
+}
@@ -0,0 +1,128 @@
+package webhook_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+	"github.com/KKGo-Software-engineering/fun-exercise-api/webhook"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubWebhookStore struct {
+	Created    *postgres.Webhook
+	Webhooks   []postgres.Webhook
+	Deliveries []postgres.WebhookDelivery
+	Err        error
+}
+
+func (s *StubWebhookStore) CreateWebhook(ctx context.Context, w *postgres.Webhook) (*postgres.Webhook, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	s.Created = w
+	created := *w
+	created.ID = 1
+	created.Active = true
+	created.CreatedAt = time.Now()
+	return &created, nil
+}
+
+func (s *StubWebhookStore) ListWebhooks(ctx context.Context) ([]postgres.Webhook, error) {
+	return s.Webhooks, s.Err
+}
+
+func (s *StubWebhookStore) DeleteWebhook(ctx context.Context, id int) error {
+	return s.Err
+}
+
+func (s *StubWebhookStore) ListDeliveries(ctx context.Context, subscriptionId int, status string, limit int) ([]postgres.WebhookDelivery, error) {
+	return s.Deliveries, s.Err
+}
+
+func (s *StubWebhookStore) RetryDelivery(ctx context.Context, subscriptionId int, id int64) (*postgres.WebhookDelivery, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return &postgres.WebhookDelivery{ID: id, SubscriptionID: subscriptionId, Status: postgres.DeliveryPending}, nil
+}
+
+func (s *StubWebhookStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]postgres.WebhookDelivery, error) {
+	return s.Deliveries, s.Err
+}
+
+func (s *StubWebhookStore) CompleteDelivery(ctx context.Context, id int64) error {
+	return s.Err
+}
+
+func (s *StubWebhookStore) FailDelivery(ctx context.Context, id int64, lastError string, retryAt *time.Time) error {
+	return s.Err
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("given valid request should store a generated secret and return it", func(t *testing.T) {
+		store := &StubWebhookStore{}
+		service := webhook.NewService(store)
+
+		created, err := service.CreateWebhook(context.Background(), &webhook.WebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"wallet.created"}})
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+		assert.Equal(t, created.Secret, store.Created.Secret)
+		assert.Equal(t, []string{"wallet.created"}, created.EventTypes)
+	})
+
+	t.Run("given invalid URL should return 400", func(t *testing.T) {
+		store := &StubWebhookStore{}
+		service := webhook.NewService(store)
+
+		_, err := service.CreateWebhook(context.Background(), &webhook.WebhookRequest{URL: "example.com"})
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Nil(t, store.Created)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	t.Run("given unknown webhook should return 404", func(t *testing.T) {
+		service := webhook.NewService(&StubWebhookStore{Err: fmt.Errorf("webhook 9: %w", postgres.ErrNotFound)})
+
+		err := service.DeleteWebhook(context.Background(), 9)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
+func TestListDeliveries(t *testing.T) {
+	t.Run("given deliveries should return them with their event type", func(t *testing.T) {
+		store := &StubWebhookStore{Deliveries: []postgres.WebhookDelivery{
+			{ID: 2, EventID: 7, Status: postgres.DeliveryDead, Attempts: 8, LastError: "unexpected status 503", Event: postgres.OutboxEvent{ID: 7, EventType: postgres.EventWalletUpdated}},
+		}}
+		service := webhook.NewService(store)
+
+		deliveries, err := service.ListDeliveries(context.Background(), 1, postgres.DeliveryDead, 20)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "wallet.updated", deliveries[0].EventType)
+		assert.Equal(t, "dead", deliveries[0].Status)
+	})
+
+	t.Run("given unknown status should return 400", func(t *testing.T) {
+		service := webhook.NewService(&StubWebhookStore{})
+
+		_, err := service.ListDeliveries(context.Background(), 1, "failed", 20)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/KKGo-Software-engineering/fun-exercise-api/postgres"
+)
+
+const (
+	maxURLLength     = 2048
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// ValidateWebhookRequest validates the URL and event types of a subscription
+func ValidateWebhookRequest(request *WebhookRequest) error {
+	var errMsgs []string
+
+	u, err := url.Parse(request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errMsgs = append(errMsgs, "URL must be an absolute http or https URL")
+	} else if len(request.URL) > maxURLLength {
+		errMsgs = append(errMsgs, fmt.Sprintf("URL must be at most %d characters", maxURLLength))
+	}
+
+	seen := map[string]bool{}
+	for _, eventType := range request.EventTypes {
+		if !isEventType(eventType) {
+			errMsgs = append(errMsgs, fmt.Sprintf("EventTypes must be among: %s", strings.Join(postgres.EventTypes, ", ")))
+			break
+		}
+		if seen[eventType] {
+			errMsgs = append(errMsgs, "EventTypes must not repeat")
+			break
+		}
+		seen[eventType] = true
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// ValidateDeliveryQuery validates the status filter and page size of a
+// delivery listing
+func ValidateDeliveryQuery(status string, limit int) error {
+	var errMsgs []string
+
+	switch status {
+	case "", postgres.DeliveryPending, postgres.DeliveryDelivered, postgres.DeliveryDead:
+	default:
+		errMsgs = append(errMsgs, "Status must be one of pending, delivered or dead")
+	}
+	if limit <= 0 || limit > maxPageLimit {
+		errMsgs = append(errMsgs, fmt.Sprintf("Limit must be between 1 and %d", maxPageLimit))
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+func isEventType(eventType string) bool {
+	for _, t := range postgres.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"testing"
+)
+
+func TestValidateWebhookRequest(t *testing.T) {
+	testCases := []struct {
+		name      string
+		request   *WebhookRequest
+		wantError bool
+	}{
+		{name: "Valid request", request: &WebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"wallet.created", "transfer.completed"}}, wantError: false},
+		{name: "Valid request for every event", request: &WebhookRequest{URL: "http://localhost:8080/hooks"}, wantError: false},
+		{name: "Invalid URL (relative)", request: &WebhookRequest{URL: "/hooks"}, wantError: true},
+		{name: "Invalid URL (scheme)", request: &WebhookRequest{URL: "ftp://example.com/hooks"}, wantError: true},
+		{name: "Invalid EventTypes (unknown)", request: &WebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"wallet.viewed"}}, wantError: true},
+		{name: "Invalid EventTypes (repeated)", request: &WebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"wallet.created", "wallet.created"}}, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateWebhookRequest(tc.request)
+			if (err != nil) != tc.wantError {
+				t.Errorf("ValidateWebhookRequest(%+v) returned error: %v, wantError: %t", tc.request, err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestValidateDeliveryQuery(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    string
+		limit     int
+		wantError bool
+	}{
+		{name: "Valid query", status: "", limit: 20, wantError: false},
+		{name: "Valid dead filter", status: "dead", limit: 100, wantError: false},
+		{name: "Invalid status", status: "failed", limit: 20, wantError: true},
+		{name: "Invalid limit", status: "", limit: 101, wantError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDeliveryQuery(tc.status, tc.limit)
+			if (err != nil) != tc.wantError {
+				t.Errorf("ValidateDeliveryQuery(%q, %d) returned error: %v, wantError: %t", tc.status, tc.limit, err, tc.wantError)
+			}
+		})
+	}
+}
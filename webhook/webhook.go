@@ -0,0 +1,52 @@
+// Package webhook lets admins subscribe URLs to wallet events and delivers
+// the events written to the outbox to them.
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+type Webhook struct {
+	ID         int       `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://ledger.example.com/hooks/wallet"`
+	EventTypes []string  `json:"event_types" example:"wallet.created"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+// CreatedWebhook is the only response that contains the signing secret.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret" example:"whsec_q8Xh..."`
+}
+
+// WebhookRequest subscribes URL to EventTypes, or to every event when
+// EventTypes is empty.
+type WebhookRequest struct {
+	URL        string   `json:"url" example:"https://ledger.example.com/hooks/wallet"`
+	EventTypes []string `json:"event_types" example:"wallet.created"`
+}
+
+type Delivery struct {
+	ID            int64      `json:"id" example:"42"`
+	EventID       int64      `json:"event_id" example:"7"`
+	EventType     string     `json:"event_type" example:"wallet.updated"`
+	Status        string     `json:"status" example:"dead"`
+	Attempts      int        `json:"attempts" example:"8"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" example:"2024-03-25T15:19:00Z"`
+	LastError     string     `json:"last_error,omitempty" example:"unexpected status 503"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" example:"2024-03-25T14:19:01Z"`
+	CreatedAt     time.Time  `json:"created_at" example:"2024-03-25T14:19:00.729237Z"`
+}
+
+type Service interface {
+	CreateWebhook(ctx context.Context, request *WebhookRequest) (*CreatedWebhook, error)
+
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+
+	DeleteWebhook(ctx context.Context, id int) error
+
+	ListDeliveries(ctx context.Context, webhookId int, status string, limit int) ([]Delivery, error)
+
+	RetryDelivery(ctx context.Context, webhookId int, deliveryId int64) (*Delivery, error)
+}